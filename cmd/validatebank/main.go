@@ -0,0 +1,126 @@
+// Command validatebank checks the technical question bank by running each
+// question's reference solutions against all of its test cases, using the same
+// code runner, harness and output comparison as candidate submissions.
+//
+//	go run ./cmd/validatebank [-question <id>] [-difficulty Easy|Medium|Hard]
+//
+// It exits with status 1 if any reference solution fails a test case or
+// cannot be run, so it can gate changes to the technical_bank collection.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"stormhacks-be/database/mongodb"
+	"stormhacks-be/models"
+	"stormhacks-be/repositories"
+	"stormhacks-be/services"
+)
+
+func main() {
+	questionID := flag.String("question", "", "only validate the question with this ID")
+	difficulty := flag.String("difficulty", "", "only validate questions of this difficulty")
+	flag.Parse()
+
+	mongoClient, err := mongodb.NewMongoClient(mongodb.DefaultConfig())
+	if err != nil {
+		log.Fatal("Failed to connect to MongoDB: ", err)
+	}
+
+	codeRunner, err := services.NewCodeRunner(services.DefaultCodeRunnerConfig())
+	if err != nil {
+		log.Fatal("Failed to create code runner: ", err)
+	}
+
+	interviewRepo := repositories.NewInterviewRepository(mongoClient.Database)
+	questions, err := loadQuestions(interviewRepo, *questionID)
+	if err != nil {
+		log.Fatal("Failed to load technical questions: ", err)
+	}
+
+	var validated, skipped, failed int
+	for _, question := range questions {
+		if *difficulty != "" && string(question.Difficulty) != *difficulty {
+			continue
+		}
+
+		name := fmt.Sprintf("%s %q", question.ID.Hex(), question.Question.Question)
+		checks, err := services.ValidateTechnicalQuestion(question.Question, codeRunner)
+		if errors.Is(err, services.ErrNoReferenceSolutions) {
+			fmt.Printf("SKIP  %s: no reference solutions\n", name)
+			skipped++
+			continue
+		}
+
+		questionFailed := err != nil
+		for _, check := range checks {
+			passed := check.Total - len(check.Mismatches)
+			if len(check.Mismatches) == 0 {
+				fmt.Printf("OK    %s (%s): %d/%d test cases\n", name, check.Language, passed, check.Total)
+				continue
+			}
+
+			questionFailed = true
+			fmt.Printf("FAIL  %s (%s): %d/%d test cases\n", name, check.Language, passed, check.Total)
+			for _, mismatch := range check.Mismatches {
+				fmt.Printf("      %s\n", describeMismatch(mismatch))
+			}
+		}
+		if err != nil {
+			fmt.Printf("ERROR %s: %v\n", name, err)
+		}
+
+		validated++
+		if questionFailed {
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d questions validated, %d failed, %d skipped\n", validated, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadQuestions fetches a single question by ID, or the whole bank
+func loadQuestions(interviewRepo *repositories.InterviewRepository, questionID string) ([]models.TechnicalBank, error) {
+	if questionID == "" {
+		return interviewRepo.GetAllTechnicalQuestions()
+	}
+
+	question, err := interviewRepo.GetTechnicalQuestionByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	return []models.TechnicalBank{*question}, nil
+}
+
+// describeMismatch explains why a reference solution did not pass a test case
+func describeMismatch(mismatch services.ReferenceMismatch) string {
+	label := fmt.Sprintf("test case %d", mismatch.Index+1)
+	if mismatch.Hidden {
+		label += " (hidden)"
+	}
+
+	result := mismatch.Result
+	if result.Stderr != "" {
+		return fmt.Sprintf("%s %s - input %s:\n%s", label, result.Verdict, result.Input, indent(strings.TrimSpace(result.Stderr), "        "))
+	}
+	if len(result.MismatchedCalls) > 0 {
+		label += fmt.Sprintf(", call %d", result.MismatchedCalls[0])
+	}
+	if result.MismatchedLine > 0 {
+		label += fmt.Sprintf(", line %d", result.MismatchedLine)
+	}
+	return fmt.Sprintf("%s %s - input %s: expected %s, got %s", label, result.Verdict, result.Input, result.Expected, result.Actual)
+}
+
+// indent prefixes every line of text
+func indent(text string, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
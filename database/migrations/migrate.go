@@ -67,6 +67,47 @@ func createIndexes(db *mongo.Database) error {
 		return fmt.Errorf("failed to create question_bank indexes: %v", err)
 	}
 
+	// Indexes for submissions
+	submissionsCollection := db.Collection("submissions")
+	submissionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "session_id", Value: 1}, {Key: "question_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	}
+	_, err = submissionsCollection.Indexes().CreateMany(ctx, submissionIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create submissions indexes: %v", err)
+	}
+
+	// Indexes for editor_snapshots
+	snapshotsCollection := db.Collection("editor_snapshots")
+	snapshotIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}, {Key: "question_id", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err = snapshotsCollection.Indexes().CreateMany(ctx, snapshotIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create editor_snapshots indexes: %v", err)
+	}
+
+	// Indexes for execution_cache; cached executions expire after a week
+	executionCacheCollection := db.Collection("execution_cache")
+	executionCacheIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "question_id", Value: 1}, {Key: "revision", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(7 * 24 * 60 * 60),
+		},
+	}
+	_, err = executionCacheCollection.Indexes().CreateMany(ctx, executionCacheIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create execution_cache indexes: %v", err)
+	}
+
 
 
 	log.Println("All indexes created successfully!")
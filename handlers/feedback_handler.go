@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"stormhacks-be/services"
 	"stormhacks-be/types/requests"
 )
 
@@ -53,7 +54,19 @@ func (h *FeedbackHandler) GenerateFeedback(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Generate feedback
-	response, err := h.interviewService.GenerateInterviewFeedback(input)
+	response, err := h.interviewService.GenerateInterviewFeedback(r.Context(), input)
+	if errors.Is(err, services.ErrAIUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrMalformedAIResponse) {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
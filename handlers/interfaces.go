@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
 	"stormhacks-be/models"
 	"stormhacks-be/types/requests"
 	"stormhacks-be/types/responses"
@@ -9,10 +12,18 @@ import (
 // InterviewServiceInterface defines the interface for interview service
 type InterviewServiceInterface interface {
 	CreateInterviewSession(input requests.InterviewSessionInput) (*responses.InterviewSessionResponse, error)
-	GenerateInterviewQuestions(sessionID string) (*responses.InterviewSessionQuestionsResponse, error)
-	GenerateInterviewFeedback(input requests.InterviewFeedbackInput) (*responses.InterviewFeedbackResponse, error)
+	GenerateInterviewQuestions(ctx context.Context, sessionID string) (*responses.InterviewSessionQuestionsResponse, error)
+	GenerateInterviewFeedback(ctx context.Context, input requests.InterviewFeedbackInput) (*responses.InterviewFeedbackResponse, error)
 	GetTechnicalQuestion(difficulty string) (*models.TechnicalBank, error)
 	ExecuteCode(input requests.ExecuteTechnicalInput) (*responses.ExecuteTechnicalResponse, error)
-	GenerateHint(input requests.HintRequest) (*responses.HintResponse, error)
-	GenerateTechnicalFeedback(input requests.TechnicalFeedbackInput) (*responses.TechnicalFeedbackResponse, error)
+	ExecuteCustom(input requests.ExecuteCustomInput) (*responses.ExecuteCustomResponse, error)
+	QueueSubmission(input requests.ExecuteTechnicalInput) (*responses.SubmissionResponse, error)
+	GetSubmission(submissionID string) (*responses.SubmissionResponse, error)
+	WatchSubmission(submissionID string) (<-chan responses.SubmissionResponse, func(), error)
+	GetSubmissionHistory(sessionID string, questionID string) (*responses.SubmissionHistoryResponse, error)
+	RecordEditorSnapshot(input requests.EditorSnapshotInput) (*responses.EditorSnapshotResponse, error)
+	GetReplay(sessionID string, questionID string, at *time.Time) (*responses.ReplayResponse, error)
+	GenerateHint(ctx context.Context, input requests.HintRequest) (*responses.HintResponse, error)
+	StreamHint(ctx context.Context, input requests.HintRequest, onHint func(string)) (*responses.HintResponse, error)
+	GenerateTechnicalFeedback(ctx context.Context, input requests.TechnicalFeedbackInput) (*responses.TechnicalFeedbackResponse, error)
 }
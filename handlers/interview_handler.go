@@ -3,8 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"stormhacks-be/services"
+	"stormhacks-be/types/enums"
 	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+	"time"
 )
 
 // InterviewHandler handles interview-related HTTP requests
@@ -95,7 +100,11 @@ func (h *InterviewHandler) GetInterviewQuestions(w http.ResponseWriter, r *http.
 	sessionId := sessionIdStr
 
 	// Get interview questions
-	response, err := h.interviewService.GenerateInterviewQuestions(sessionId)
+	response, err := h.interviewService.GenerateInterviewQuestions(r.Context(), sessionId)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -194,6 +203,15 @@ func (h *InterviewHandler) ExecuteCode(w http.ResponseWriter, r *http.Request) {
 
 	// Execute code
 	response, err := h.interviewService.ExecuteCode(input)
+	if errors.Is(err, services.ErrSubmissionQueueFull) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -204,6 +222,376 @@ func (h *InterviewHandler) ExecuteCode(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// ExecuteCustom handles POST /api/execute-custom
+func (h *InterviewHandler) ExecuteCustom(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST requests
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var input requests.ExecuteCustomInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Validate input
+	if err := h.validateExecuteCustomInput(input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Execute code against the custom input
+	response, err := h.interviewService.ExecuteCustom(input)
+	if errors.Is(err, services.ErrSubmissionQueueFull) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateSubmission handles POST /api/submissions
+func (h *InterviewHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST requests
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var input requests.ExecuteTechnicalInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Validate input
+	if err := h.validateExecuteTechnicalInput(input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Queue the code for execution
+	response, err := h.interviewService.QueueSubmission(input)
+	if errors.Is(err, services.ErrSubmissionQueueFull) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return accepted response
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetSubmission handles GET /api/submissions/status
+func (h *InterviewHandler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET requests
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get submissionId from query parameters
+	submissionID := r.URL.Query().Get("submissionId")
+	if submissionID == "" {
+		http.Error(w, "submissionId query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get submission status
+	response, err := h.interviewService.GetSubmission(submissionID)
+	if errors.Is(err, services.ErrSubmissionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetSubmissionHistory handles GET /api/submissions/history
+func (h *InterviewHandler) GetSubmissionHistory(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET requests
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get sessionId, and optionally questionId, from query parameters
+	sessionID := r.URL.Query().Get("sessionId")
+	if sessionID == "" {
+		http.Error(w, "sessionId query parameter is required", http.StatusBadRequest)
+		return
+	}
+	questionID := r.URL.Query().Get("questionId")
+
+	// Get the session's submissions
+	response, err := h.interviewService.GetSubmissionHistory(sessionID, questionID)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RecordEditorSnapshot handles POST /api/snapshots
+func (h *InterviewHandler) RecordEditorSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST requests
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input requests.EditorSnapshotInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Validate input
+	if err := h.validateEditorSnapshotInput(input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Record the snapshot
+	response, err := h.interviewService.RecordEditorSnapshot(input)
+	if errors.Is(err, services.ErrSnapshotOutOfSync) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidSnapshot) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetReplay handles GET /api/replay
+func (h *InterviewHandler) GetReplay(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Content-Type", "application/json")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET requests
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get sessionId, questionId and optionally at from query parameters
+	sessionID := r.URL.Query().Get("sessionId")
+	if sessionID == "" {
+		http.Error(w, "sessionId query parameter is required", http.StatusBadRequest)
+		return
+	}
+	questionID := r.URL.Query().Get("questionId")
+	if questionID == "" {
+		http.Error(w, "questionId query parameter is required", http.StatusBadRequest)
+		return
+	}
+	var at *time.Time
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "at query parameter must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		at = &parsed
+	}
+
+	// Rebuild the coding session
+	response, err := h.interviewService.GetReplay(sessionID, questionID, at)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// StreamSubmission handles GET /api/submissions/events, sending the submission's
+// status as server-sent events until it finishes
+func (h *InterviewHandler) StreamSubmission(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET requests
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get submissionId from query parameters
+	submissionID := r.URL.Query().Get("submissionId")
+	if submissionID == "" {
+		http.Error(w, "submissionId query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe to submission updates
+	updates, unsubscribe, err := h.interviewService.WatchSubmission(submissionID)
+	if errors.Is(err, services.ErrSubmissionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Send each status change until the submission finishes or the client goes away
+	for {
+		select {
+		case update, open := <-updates:
+			if !open {
+				return
+			}
+			data, err := json.Marshal(update)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", update.Status, data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // GenerateHint handles POST /api/hint
 func (h *InterviewHandler) GenerateHint(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
@@ -238,7 +626,19 @@ func (h *InterviewHandler) GenerateHint(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Generate hints
-	response, err := h.interviewService.GenerateHint(input)
+	response, err := h.interviewService.GenerateHint(r.Context(), input)
+	if errors.Is(err, services.ErrAIUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrMalformedAIResponse) {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -249,6 +649,90 @@ func (h *InterviewHandler) GenerateHint(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// StreamHint handles POST /api/hint/stream
+func (h *InterviewHandler) StreamHint(w http.ResponseWriter, r *http.Request) {
+	// Set CORS headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// Handle preflight requests
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow POST requests
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var input requests.HintRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Validate input
+	if err := h.validateHintRequest(input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream starts with the first piece of the hint, so earlier failures still get a status code
+	started := false
+	sendEvent := func(event string, payload interface{}) {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	// Send the conversational hint as it is generated, then the whole hint with its summary
+	response, err := h.interviewService.StreamHint(r.Context(), input, func(text string) {
+		sendEvent("hint", responses.HintChunk{Text: text})
+	})
+	if err != nil && started {
+		sendEvent("error", responses.HintStreamError{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrAIUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrMalformedAIResponse) {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendEvent("summary", response)
+}
+
 // validateExecuteTechnicalInput validates the input data
 func (h *InterviewHandler) validateExecuteTechnicalInput(input requests.ExecuteTechnicalInput) error {
 	if input.QuestionID == "" {
@@ -257,6 +741,40 @@ func (h *InterviewHandler) validateExecuteTechnicalInput(input requests.ExecuteT
 	if input.Code == "" {
 		return errors.New("code is required")
 	}
+	if input.Mode != "" && !enums.IsValidExecutionMode(string(input.Mode)) {
+		return errors.New("mode must be one of: run, submit, stress")
+	}
+	if input.Mode == enums.ExecutionModeSubmit && input.SessionID == "" {
+		return errors.New("sessionId is required to submit")
+	}
+	return nil
+}
+
+// validateEditorSnapshotInput validates the input data
+func (h *InterviewHandler) validateEditorSnapshotInput(input requests.EditorSnapshotInput) error {
+	if input.SessionID == "" {
+		return errors.New("sessionId is required")
+	}
+	if input.QuestionID == "" {
+		return errors.New("questionId is required")
+	}
+	if (input.Code == nil) == (input.Delta == nil) {
+		return errors.New("exactly one of code or delta is required")
+	}
+	return nil
+}
+
+// validateExecuteCustomInput validates the input data
+func (h *InterviewHandler) validateExecuteCustomInput(input requests.ExecuteCustomInput) error {
+	if input.QuestionID == "" {
+		return errors.New("questionId is required")
+	}
+	if input.Code == "" {
+		return errors.New("code is required")
+	}
+	if input.Input == "" {
+		return errors.New("input is required")
+	}
 	return nil
 }
 
@@ -311,7 +829,19 @@ func (h *InterviewHandler) GenerateTechnicalFeedback(w http.ResponseWriter, r *h
 	}
 
 	// Generate technical feedback
-	response, err := h.interviewService.GenerateTechnicalFeedback(input)
+	response, err := h.interviewService.GenerateTechnicalFeedback(r.Context(), input)
+	if errors.Is(err, services.ErrAIUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, services.ErrMalformedAIResponse) {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -8,6 +8,7 @@ import (
 	"stormhacks-be/database/migrations"
 	"stormhacks-be/database/mongodb"
 	"stormhacks-be/handlers"
+	"stormhacks-be/prompts"
 	"stormhacks-be/repositories"
 	"stormhacks-be/services"
 )
@@ -16,6 +17,7 @@ import (
 type ServiceContainer struct {
 	InterviewHandler *handlers.InterviewHandler
 	FeedbackHandler  *handlers.FeedbackHandler
+	AIAvailable      bool // a language model and prompts were set up for the AI features
 }
 
 // initializeServices sets up all the service dependencies
@@ -32,9 +34,35 @@ func initializeServices() (*ServiceContainer, error) {
 		log.Println("Continuing without migrations...")
 	}
 
+	// Code runner used for technical question execution
+	codeRunner, err := services.NewCodeRunner(services.DefaultCodeRunnerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create code runner: %w", err)
+	}
+
+	// Policy submitted code must pass before it is run
+	codePolicy := services.NewCodePolicy(services.DefaultCodePolicyConfig())
+
+	// Language model and prompts shared by the AI features; without either the API still serves everything else
+	llmConfig := services.DefaultLLMConfig()
+	llmClient, llmErr := services.NewLLMClient(llmConfig)
+	if llmErr != nil {
+		log.Printf("Warning: AI features are unavailable: %v", llmErr)
+	}
+	promptLibrary, promptErr := prompts.Load(prompts.DefaultConfig())
+	if promptErr != nil {
+		log.Printf("Warning: AI features are unavailable: %v", promptErr)
+	}
+	var aiService *services.LLMService
+	if llmErr == nil && promptErr == nil {
+		aiService = services.NewLLMService(llmClient, promptLibrary, llmConfig.RepairAttempts)
+	}
+
 	// Create layers
 	interviewRepo := repositories.NewInterviewRepository(mongoClient.Database)
-	interviewService := services.NewInterviewService(interviewRepo)
+	executionCache := services.NewExecutionCache(services.DefaultExecutionCacheConfig(), interviewRepo)
+	submissionQueue := services.NewSubmissionQueue(services.DefaultSubmissionQueueConfig(), interviewRepo, codeRunner, codePolicy, executionCache)
+	interviewService := services.NewInterviewService(interviewRepo, submissionQueue, aiService)
 
 	// Create handlers
 	interviewHandler := handlers.NewInterviewHandler(interviewService)
@@ -43,6 +71,7 @@ func initializeServices() (*ServiceContainer, error) {
 	return &ServiceContainer{
 		InterviewHandler: interviewHandler,
 		FeedbackHandler:  feedbackHandler,
+		AIAvailable:      interviewService.AIAvailable(),
 	}, nil
 }
 
@@ -55,10 +84,15 @@ func main() {
 
 	// Simple health check handler
 	healthHandler := func(w http.ResponseWriter, r *http.Request) {
+		ai := "available"
+		if !services.AIAvailable {
+			ai = "unavailable"
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
 			"status":  "ok",
 			"message": "Interview API is running",
+			"ai":      ai,
 		})
 	}
 
@@ -69,11 +103,19 @@ func main() {
 	http.HandleFunc("/api/interview/feedback", services.FeedbackHandler.GenerateFeedback)
 	http.HandleFunc("/api/technical-question", services.InterviewHandler.GetTechnicalQuestion)
 	http.HandleFunc("/api/hint", services.InterviewHandler.GenerateHint)
+	http.HandleFunc("/api/hint/stream", services.InterviewHandler.StreamHint)
 	http.HandleFunc("/api/execute-code", services.InterviewHandler.ExecuteCode)
+	http.HandleFunc("/api/execute-custom", services.InterviewHandler.ExecuteCustom)
+	http.HandleFunc("/api/submissions", services.InterviewHandler.CreateSubmission)
+	http.HandleFunc("/api/submissions/status", services.InterviewHandler.GetSubmission)
+	http.HandleFunc("/api/submissions/events", services.InterviewHandler.StreamSubmission)
+	http.HandleFunc("/api/submissions/history", services.InterviewHandler.GetSubmissionHistory)
+	http.HandleFunc("/api/snapshots", services.InterviewHandler.RecordEditorSnapshot)
+	http.HandleFunc("/api/replay", services.InterviewHandler.GetReplay)
 	http.HandleFunc("/api/technical-feedback", services.InterviewHandler.GenerateTechnicalFeedback)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprintf(w, `
+		fmt.Fprint(w, `
 <!DOCTYPE html>
 <html>
 <head>
@@ -101,7 +143,7 @@ func main() {
 
         <div class="endpoint">
             <h2><span class="method get">GET</span><span class="url">/health</span></h2>
-            <p><strong>Description:</strong> Health check endpoint to verify API status</p>
+            <p><strong>Description:</strong> Health check endpoint to verify API status. <code>ai</code> is <code>unavailable</code> when the language model or prompts could not be set up at startup; the AI endpoints then return 503 and everything else keeps working. They return 502 when the model's replies still break their schema after <code>LLM_REPAIR_ATTEMPTS</code> repairs.</p>
             <p><strong>Request:</strong></p>
             <pre>curl -X GET http://localhost:8080/health</pre>
             <p><strong>Response:</strong></p>
@@ -185,7 +227,8 @@ func main() {
         "What was the outcome of your communication approach?"
       ]
     }
-  ]
+  ],
+  "promptVersion": "v1"
 }</pre>
             </div>
         </div>
@@ -251,7 +294,8 @@ func main() {
     "Strong technical leadership with clear communication and measurable results",
     "Excellent problem-solving approach using appropriate tools and methodologies",
     "Consider expanding on broader impact and preventive measures for even stronger responses"
-  ]
+  ],
+  "promptVersion": "v1"
 }</pre>
             </div>
         </div>
@@ -315,11 +359,32 @@ func main() {
                 <pre>{
   "sessionId": "550e8400-e29b-41d4-a716-446655440000",
   "conversationalHint": "Great start! I can see you're thinking about this problem. Let me guide you - consider what happens when you compare characters at each position. What would you do if the characters match versus when they don't match?",
-  "hintSummary": "Consider character comparison logic for matching vs non-matching cases"
+  "hintSummary": "Consider character comparison logic for matching vs non-matching cases",
+  "promptVersion": "v1"
 }</pre>
             </div>
         </div>
 
+        <div class="endpoint">
+            <h2><span class="method post">POST</span><span class="url">/api/hint/stream</span></h2>
+            <p><strong>Description:</strong> Server-sent events for a hint, so text-to-speech can start before the hint is finished. Takes the same payload as <code>/api/hint</code>. <code>hint</code> events carry the conversational hint piece by piece as the model writes it, and the stream closes with a <code>summary</code> event holding the same JSON as <code>/api/hint</code>. A failure after the first piece is sent as an <code>error</code> event; earlier failures get the same status codes as <code>/api/hint</code>.</p>
+            <p><strong>Request:</strong></p>
+            <pre>curl -N -X POST http://localhost:8080/api/hint/stream \\
+  -H "Content-Type: application/json" \\
+  -d '{...}'</pre>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>event: hint
+data: {"text":"Great start! Consider what happens"}
+
+event: hint
+data: {"text":" when you compare characters at each position."}
+
+event: summary
+data: {"sessionId":"550e8400-e29b-41d4-a716-446655440000","conversationalHint":"Great start! Consider what happens when you compare characters at each position.","hintSummary":"Compare characters at each position","promptVersion":"v1"}</pre>
+            </div>
+        </div>
+
         <div class="endpoint">
             <h2><span class="method post">POST</span><span class="url">/api/execute-code</span></h2>
             <p><strong>Description:</strong> Execute user-submitted code against test cases for technical questions</p>
@@ -332,19 +397,48 @@ func main() {
                 <pre>{
   "questionId": "68e205dadb8a0fc4ec6924e9",
   "code": "def rottenOranges(grid):\n    if not grid or not grid[0]:\n        return -1\n    \n    m, n = len(grid), len(grid[0])\n    queue = []\n    fresh = 0\n    \n    # Find all rotten oranges and count fresh ones\n    for i in range(m):\n        for j in range(n):\n            if grid[i][j] == 2:\n                queue.append((i, j, 0))\n            elif grid[i][j] == 1:\n                fresh += 1\n    \n    if fresh == 0:\n        return 0\n    \n    directions = [(0,1), (1,0), (0,-1), (-1,0)]\n    \n    while queue:\n        i, j, time = queue.pop(0)\n        \n        for di, dj in directions:\n            ni, nj = i + di, j + dj\n            if 0 <= ni < m and 0 <= nj < n and grid[ni][nj] == 1:\n                grid[ni][nj] = 2\n                fresh -= 1\n                if fresh == 0:\n                    return time + 1\n                queue.append((ni, nj, time + 1))\n    \n    return -1",
-  "language": "python"
+  "language": "python",
+  "mode": "submit",
+  "sessionId": "550e8400-e29b-41d4-a716-446655440000"
 }</pre>
             </div>
+            <p><strong>Modes:</strong> <code>run</code> (default) executes the sample test cases only. <code>submit</code> executes every test case, including hidden ones, and records the final verdict on the session given by <code>sessionId</code>. Hidden test cases only report their <code>verdict</code> and <code>runtime</code>. <code>stress</code> runs the code and the question's trusted solution on random inputs and returns the smallest input the code fails on as <code>counterexample</code>, with the <code>seed</code> that reproduces the run (pass it back as <code>seed</code>). Accepted submissions to questions with an input generator also return <code>"complexityPending": true</code>: their complexity is measured after the verdict and stored on the submission in <code>/api/submissions/history</code> as <code>complexity</code>, with the estimated big-O (<code>bigO</code>), the timings it was fitted to (<code>samples</code>, each <code>n</code> and <code>timeMs</code>) and a <code>note</code> when sizes stopped early. Running the same code again against an unchanged question in <code>run</code> or <code>submit</code> mode returns the earlier result with <code>"cached": true</code>.</p>
+            <p><strong>Design questions:</strong> for questions of kind <code>design</code> the code defines the question's class, each test case's <code>expected</code> and <code>actual</code> are the lists of every call's result, and wrong answers list the positions (from 1) of the wrong calls in <code>mismatchedCalls</code>.</p>
+            <p><strong>Stdin/stdout questions:</strong> for questions of kind <code>stdio</code> the code is a whole program that reads each test case's input from stdin and prints its answer, which is returned as <code>actual</code> (stderr is returned as <code>logs</code>). Output is compared line by line ignoring extra whitespace and trailing blank lines, and wrong answers give the first wrong line in <code>mismatchedLine</code>.</p>
             <p><strong>Response (Success):</strong></p>
             <div class="response">
                 <pre>{
   "questionId": "68e205dadb8a0fc4ec6924e9",
   "code": "def rottenOranges(grid):...",
   "language": "python",
+  "mode": "submit",
   "output": "4\n-1\n0",
   "error": "",
   "executionTime": 200,
-  "success": true
+  "success": true,
+  "verdict": "Accepted",
+  "passed": 4,
+  "total": 4,
+  "results": [
+    {
+      "input": "[[2,1,1],[1,1,0],[0,1,1]]",
+      "expected": "4",
+      "actual": "4",
+      "logs": "",
+      "runtime": 70,
+      "verdict": "Accepted"
+    },
+    ...
+    {
+      "input": "",
+      "expected": "",
+      "actual": "",
+      "logs": "",
+      "runtime": 85,
+      "verdict": "Accepted",
+      "hidden": true
+    }
+  ]
 }</pre>
             </div>
             <p><strong>Response (Compilation Error):</strong></p>
@@ -356,7 +450,9 @@ func main() {
   "output": "",
   "error": "Compilation Error: SyntaxError: expected ':'",
   "executionTime": 50,
-  "success": false
+  "success": false,
+  "verdict": "CompileError",
+  "results": [...]
 }</pre>
             </div>
             <p><strong>Response (Wrong Answer):</strong></p>
@@ -368,13 +464,47 @@ func main() {
   "output": "1\n1\n0",
   "error": "Test case 1: Expected '4', got '1'\nTest case 2: Expected '-1', got '1'",
   "executionTime": 150,
-  "success": false
+  "success": false,
+  "verdict": "WrongAnswer",
+  "results": [...]
+}</pre>
+            </div>
+            <p><strong>Response (Stress, counterexample found):</strong></p>
+            <div class="response">
+                <pre>{
+  "questionId": "68e205dadb8a0fc4ec6924e9",
+  "language": "python",
+  "mode": "stress",
+  "error": "Input [[2,1,1]]: Expected '2', got '1'",
+  "success": false,
+  "verdict": "WrongAnswer",
+  "passed": 22,
+  "total": 25,
+  "counterexample": {
+    "input": "[[2,1,1]]",
+    "expected": "2",
+    "actual": "1",
+    "logs": "",
+    "runtime": 0,
+    "verdict": "WrongAnswer"
+  },
+  "seed": 1792219957461050723,
+  ...
 }</pre>
             </div>
             <p><strong>Supported Languages:</strong></p>
             <ul>
                 <li><code>python</code> - Python 3</li>
-                <li><code>javascript</code> - JavaScript (Node.js)</li>
+                <li><code>js</code> - JavaScript (Node.js)</li>
+                <li><code>typescript</code> - TypeScript</li>
+                <li><code>go</code> - Go</li>
+                <li><code>java</code> - Java (<code>class Solution</code>)</li>
+                <li><code>cpp</code> - C++17 (<code>class Solution</code>)</li>
+            </ul>
+            <p><strong>Verdicts</strong> (per test case in <code>results</code>, and overall in <code>verdict</code>):</p>
+            <ul>
+                <li><code>Accepted</code>, <code>WrongAnswer</code>, <code>RuntimeError</code>, <code>CompileError</code>, <code>TimeLimitExceeded</code>, <code>MemoryLimitExceeded</code></li>
+                <li><code>PolicyViolation</code> - overall only: the code was refused before running (too large, a forbidden module or a banned built-in); <code>results</code> is empty and <code>error</code> gives the line and reason</li>
             </ul>
             <p><strong>Error Types:</strong></p>
             <ul>
@@ -382,12 +512,153 @@ func main() {
                 <li><strong>Runtime Error</strong> - Index out of bounds, null pointer, etc.</li>
                 <li><strong>Wrong Answer</strong> - Code runs but produces incorrect output</li>
                 <li><strong>Execution Error</strong> - Timeout, memory issues, etc.</li>
+                <li><strong>Policy Violation</strong> - Code refused by the code policy before running</li>
             </ul>
         </div>
 
+        <div class="endpoint">
+            <h2><span class="method post">POST</span><span class="url">/api/execute-custom</span></h2>
+            <p><strong>Description:</strong> Run code against a candidate-supplied input, like a scratchpad. If the question has a reference solution, its return value for the same input is included.</p>
+            <p><strong>Request:</strong></p>
+            <pre>curl -X POST http://localhost:8080/api/execute-custom \\
+  -H "Content-Type: application/json" \\
+  -d '{...}'</pre>
+            <p><strong>Payload:</strong></p>
+            <div class="payload">
+                <pre>{
+  "questionId": "68e205dadb8a0fc4ec6924e9",
+  "code": "def rottenOranges(grid):...",
+  "language": "python",
+  "input": "[[2,1],[1,1]]"
+}</pre>
+            </div>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>{
+  "questionId": "68e205dadb8a0fc4ec6924e9",
+  "language": "python",
+  "input": "[[2,1],[1,1]]",
+  "result": {
+    "returnValue": "2",
+    "logs": "",
+    "runtime": 1
+  },
+  "reference": {
+    "returnValue": "2",
+    "logs": "",
+    "runtime": 1
+  },
+  "executionTime": 90
+}</pre>
+            </div>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method post">POST</span><span class="url">/api/submissions</span></h2>
+            <p><strong>Description:</strong> Queue code for execution and return straight away. Takes the same payload as <code>/api/execute-code</code>; a fixed pool of workers runs queued submissions in order. Returns 503 with <code>Retry-After</code> when the queue is full.</p>
+            <p><strong>Response (202):</strong></p>
+            <div class="response">
+                <pre>{
+  "submissionId": "5b0e6f0a-3c1f-4f57-9a53-2f1d8c1e7a42",
+  "status": "queued",
+  "position": 3,
+  "createdAt": "2025-10-05T12:00:00Z"
+}</pre>
+            </div>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method get">GET</span><span class="url">/api/submissions/status?submissionId=...</span></h2>
+            <p><strong>Description:</strong> Poll a queued submission. <code>status</code> is one of <code>queued</code>, <code>running</code>, <code>completed</code> or <code>failed</code>; once completed, <code>result</code> holds the same response <code>/api/execute-code</code> returns, and if failed, <code>error</code> says why. Finished submissions are kept for <code>SUBMISSION_RETENTION_MINUTES</code>.</p>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>{
+  "submissionId": "5b0e6f0a-3c1f-4f57-9a53-2f1d8c1e7a42",
+  "status": "completed",
+  "result": { "verdict": "Accepted", "passed": 4, "total": 4, ... },
+  "createdAt": "2025-10-05T12:00:00Z",
+  "startedAt": "2025-10-05T12:00:02Z",
+  "finishedAt": "2025-10-05T12:00:03Z"
+}</pre>
+            </div>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method get">GET</span><span class="url">/api/submissions/events?submissionId=...</span></h2>
+            <p><strong>Description:</strong> Server-sent events for a queued submission. Each event is named after the status and carries the same JSON as the status endpoint; the stream ends once the submission is completed or failed.</p>
+            <p><strong>Request:</strong></p>
+            <pre>curl -N "http://localhost:8080/api/submissions/events?submissionId=..."</pre>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method get">GET</span><span class="url">/api/submissions/history?sessionId=...</span></h2>
+            <p><strong>Description:</strong> Every run and submission of code made with a <code>sessionId</code> (through <code>/api/execute-code</code> or <code>/api/submissions</code>), oldest first, with its code, verdict, per-case results and timing. Pass <code>questionId</code> to only list one question's submissions. Hidden test cases are redacted as in the execution response.</p>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>{
+  "sessionId": "ca781b1f-6d27-4a66-b6c1-ba597b76fee1",
+  "submissions": [
+    {
+      "id": "6710c2f4e13d5a0b9c8e7f61",
+      "questionId": "68e205e6db8a0fc4ec6924ea",
+      "language": "python",
+      "mode": "run",
+      "code": "def rottenOranges(grid): ...",
+      "verdict": "WrongAnswer",
+      "passed": 1,
+      "total": 2,
+      "results": [{"input": "[[2,1,1],[1,1,0],[0,1,1]]", "expected": "4", "actual": "1", "runtime": 0, "verdict": "WrongAnswer"}, ...],
+      "executionTime": 48,
+      "createdAt": "2025-10-05T12:00:00Z"
+    }
+  ]
+}</pre>
+            </div>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method post">POST</span><span class="url">/api/snapshots</span></h2>
+            <p><strong>Description:</strong> Record the candidate's code for a question, sent periodically while they type. Send either the whole <code>code</code>, or a <code>delta</code> replacing <code>deleted</code> characters from <code>start</code> with <code>inserted</code> (positions count Unicode characters) along with the <code>baseSequence</code> it applies to. A delta based on an older snapshot returns 409, and the client should send the whole code. Unchanged code is not stored. An unknown <code>sessionId</code> returns 404.</p>
+            <p><strong>Request:</strong></p>
+            <pre>{
+  "sessionId": "ca781b1f-6d27-4a66-b6c1-ba597b76fee1",
+  "questionId": "68e205e6db8a0fc4ec6924ea",
+  "delta": {"start": 27, "deleted": 0, "inserted": "    return -1\n"},
+  "baseSequence": 4
+}</pre>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>{
+  "sessionId": "ca781b1f-6d27-4a66-b6c1-ba597b76fee1",
+  "questionId": "68e205e6db8a0fc4ec6924ea",
+  "sequence": 5,
+  "recordedAt": "2025-10-05T12:00:05Z"
+}</pre>
+            </div>
+        </div>
+
+        <div class="endpoint">
+            <h2><span class="method get">GET</span><span class="url">/api/replay?sessionId=...&questionId=...</span></h2>
+            <p><strong>Description:</strong> Replay a question's coding session: its edits, hint requests and executions in time order, and the code as it stood at the end. Pass <code>at</code> (an RFC 3339 timestamp) to stop the replay and return the code at that time. Each edit is a delta from the code before it, so applying them in order rebuilds the code. An unknown <code>sessionId</code> returns 404.</p>
+            <p><strong>Response:</strong></p>
+            <div class="response">
+                <pre>{
+  "sessionId": "ca781b1f-6d27-4a66-b6c1-ba597b76fee1",
+  "questionId": "68e205e6db8a0fc4ec6924ea",
+  "code": "def rottenOranges(grid):\n    return -1\n",
+  "events": [
+    {"type": "edit", "time": "2025-10-05T12:00:00Z", "sequence": 1, "delta": {"start": 0, "deleted": 0, "inserted": "def rottenOranges(grid):\n"}},
+    {"type": "hint", "time": "2025-10-05T12:00:03Z"},
+    {"type": "edit", "time": "2025-10-05T12:00:05Z", "sequence": 2, "delta": {"start": 25, "deleted": 0, "inserted": "    return -1\n"}},
+    {"type": "execution", "time": "2025-10-05T12:00:09Z", "execution": {"submissionId": "6710c2f4e13d5a0b9c8e7f61", "mode": "run", "language": "python", "verdict": "WrongAnswer", "passed": 0, "total": 2}}
+  ]
+}</pre>
+            </div>
+        </div>
+
         <div class="endpoint">
             <h2><span class="method post">POST</span><span class="url">/api/technical-feedback</span></h2>
-            <p><strong>Description:</strong> Generate AI-powered technical feedback based on candidate's performance, job context, and code quality</p>
+            <p><strong>Description:</strong> Generate AI-powered technical feedback based on candidate's performance, job context, and code quality. When the session has submissions for the question, the final code, whether it was solved (an accepted <code>submit</code>), the number of hints given through <code>/api/hint</code> and the submission history come from the server, <code>userCode</code>, <code>isCompleted</code> and <code>hintsUsed</code> are ignored, and <code>verified</code> is true. Otherwise the request's values are used and <code>verified</code> is false.</p>
             <p><strong>Request:</strong></p>
             <pre>curl -X POST http://localhost:8080/api/technical-feedback \\
   -H "Content-Type: application/json" \\
@@ -417,7 +688,9 @@ func main() {
     "Good understanding of the problem structure",
     "Clean code organization and variable naming",
     "Proper handling of the base case when no fresh oranges exist"
-  ]
+  ],
+  "promptVersion": "v1",
+  "verified": true
 }</pre>
             </div>
             <p><strong>Features:</strong></p>
@@ -477,7 +750,13 @@ func main() {
 	fmt.Println("AI Feedback: http://localhost:8080/api/interview/feedback")
 	fmt.Println("Technical Questions: http://localhost:8080/api/technical-question")
 	fmt.Println("Hint Generation: http://localhost:8080/api/hint")
+	fmt.Println("Hint Streaming: http://localhost:8080/api/hint/stream")
 	fmt.Println("Code Execution: http://localhost:8080/api/execute-code")
+	fmt.Println("Custom Input: http://localhost:8080/api/execute-custom")
+	fmt.Println("Submission Queue: http://localhost:8080/api/submissions")
+	fmt.Println("Submission History: http://localhost:8080/api/submissions/history")
+	fmt.Println("Editor Snapshots: http://localhost:8080/api/snapshots")
+	fmt.Println("Session Replay: http://localhost:8080/api/replay")
 	fmt.Println("Technical Feedback: http://localhost:8080/api/technical-feedback")
 	fmt.Println("Powered by Google Gemini AI for intelligent question customization, hints, and feedback!")
 
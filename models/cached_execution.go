@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CachedExecution is a judged execution kept so the same code run again
+// against the same revision of a question does not reach the code runner
+type CachedExecution struct {
+	Key        string    `bson:"_id"`
+	QuestionID string    `bson:"question_id"`
+	Revision   string    `bson:"revision"` // hash of the question the execution was judged against
+	Response   []byte    `bson:"response"` // the JSON encoded execution response
+	CreatedAt  time.Time `bson:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EditorSnapshot is the candidate's code for a question at one moment. Most
+// snapshots only store how the code changed since the previous one; every
+// few snapshots a keyframe stores the whole code so replays start nearby.
+type EditorSnapshot struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SessionID  string             `bson:"session_id" json:"sessionId"`
+	QuestionID string             `bson:"question_id" json:"questionId"`
+	Sequence   int                `bson:"sequence" json:"sequence"` // counts up from 1 per session and question
+	Keyframe   bool               `bson:"keyframe,omitempty" json:"keyframe,omitempty"`
+	Code       string             `bson:"code,omitempty" json:"code,omitempty"`   // keyframes only
+	Delta      *CodeDelta         `bson:"delta,omitempty" json:"delta,omitempty"` // every other snapshot
+	RecordedAt time.Time          `bson:"recorded_at" json:"recordedAt"`
+}
+
+// CodeDelta replaces Deleted characters at Start with Inserted. Positions
+// count Unicode characters, not bytes.
+type CodeDelta struct {
+	Start    int    `bson:"start" json:"start"`
+	Deleted  int    `bson:"deleted" json:"deleted"`
+	Inserted string `bson:"inserted" json:"inserted"`
+}
@@ -19,5 +19,37 @@ type InterviewSession struct {
 	InterviewType        *string            `bson:"interview_type,omitempty" json:"interviewType,omitempty"` // "technical", "behavioral", "both"
 	BehaviouralTopics    []enums.BehaviouralTopic `bson:"behavioural_topics" json:"behaviouralTopics"`
 	TechnicalDifficulty  *string            `bson:"technical_difficulty,omitempty" json:"technicalDifficulty,omitempty"`
+	TechnicalVerdicts    []TechnicalVerdict `bson:"technical_verdicts,omitempty" json:"technicalVerdicts,omitempty"`
+	HintUsages           []HintUsage        `bson:"hint_usages,omitempty" json:"hintUsages,omitempty"`
 	CreatedAt            time.Time          `bson:"created_at" json:"createdAt"`
 }
+
+// TechnicalVerdict is the final verdict of a submitted technical solution
+type TechnicalVerdict struct {
+	QuestionID  string        `bson:"question_id" json:"questionId"`
+	Language    string        `bson:"language" json:"language"`
+	Verdict     enums.Verdict `bson:"verdict" json:"verdict"`
+	Passed      int           `bson:"passed" json:"passed"`
+	Total       int           `bson:"total" json:"total"`
+	SubmittedAt time.Time     `bson:"submitted_at" json:"submittedAt"`
+}
+
+// HintUsage records a hint given for a technical question
+type HintUsage struct {
+	QuestionID    string    `bson:"question_id" json:"questionId"`
+	RequestedAt   time.Time `bson:"requested_at" json:"requestedAt"`
+	PromptVersion string    `bson:"prompt_version,omitempty" json:"promptVersion,omitempty"` // version of the prompt the hint came from
+}
+
+// ComplexityEstimate is how a solution's running time was measured to grow with input size
+type ComplexityEstimate struct {
+	BigO    string         `bson:"big_o" json:"bigO"` // e.g. "O(n log n)", empty if there were too few samples to fit
+	Samples []TimingSample `bson:"samples" json:"samples"`
+	Note    string         `bson:"note,omitempty" json:"note,omitempty"` // why the sizes stopped before the largest one
+}
+
+// TimingSample is the running time of a solution on inputs of size N
+type TimingSample struct {
+	N      int     `bson:"n" json:"n"`
+	TimeMs float64 `bson:"time_ms" json:"timeMs"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"stormhacks-be/types/enums"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Submission is one execution of a candidate's code within a session, kept so
+// reviewers can follow how the candidate reached their final answer
+type Submission struct {
+	ID            primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	SessionID     string                 `bson:"session_id" json:"sessionId"`
+	QuestionID    string                 `bson:"question_id" json:"questionId"`
+	Language      string                 `bson:"language" json:"language"`
+	Mode          enums.ExecutionMode    `bson:"mode" json:"mode"`
+	Code          string                 `bson:"code" json:"code"`
+	Verdict       enums.Verdict          `bson:"verdict" json:"verdict"`
+	Passed        int                    `bson:"passed" json:"passed"`
+	Total         int                    `bson:"total" json:"total"`
+	Error         string                 `bson:"error,omitempty" json:"error,omitempty"`
+	Results       []SubmissionCaseResult `bson:"results" json:"results"`
+	ExecutionTime int64                  `bson:"execution_time" json:"executionTime"` // in milliseconds
+	CreatedAt     time.Time              `bson:"created_at" json:"createdAt"`
+
+	// Accepted submissions to questions with an input generator, once measured after the verdict
+	Complexity *ComplexityEstimate `bson:"complexity,omitempty" json:"complexity,omitempty"`
+}
+
+// SubmissionCaseResult is a judged test case of a submission, redacted the
+// same way as in the response for hidden test cases
+type SubmissionCaseResult struct {
+	Input    string        `bson:"input,omitempty" json:"input,omitempty"`
+	Expected string        `bson:"expected,omitempty" json:"expected,omitempty"`
+	Actual   string        `bson:"actual,omitempty" json:"actual,omitempty"`
+	Stderr   string        `bson:"stderr,omitempty" json:"stderr,omitempty"`
+	Runtime  int64         `bson:"runtime" json:"runtime"` // in milliseconds
+	Verdict  enums.Verdict `bson:"verdict" json:"verdict"`
+	Hidden   bool          `bson:"hidden,omitempty" json:"hidden,omitempty"`
+}
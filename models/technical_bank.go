@@ -7,19 +7,176 @@ import (
 )
 
 type TestCase struct {
-	Input          string `bson:"input" json:"input"`
-	ExpectedOutput string `bson:"expectedOutput" json:"expectedOutput"`
+	Input           string   `bson:"input" json:"input"`
+	ExpectedOutput  string   `bson:"expectedOutput" json:"expectedOutput"`
+	AcceptedOutputs []string `bson:"acceptedOutputs,omitempty" json:"acceptedOutputs,omitempty"` // extra valid answers for any_of comparison
+	Hidden          bool     `bson:"hidden,omitempty" json:"hidden,omitempty"`                   // only run on submit, never shown to candidates
 }
 
+// CheckerScript is a program that decides whether an output is correct.
+// It reads {"input", "expected", "actual"} as JSON on stdin and prints true or false.
+type CheckerScript struct {
+	Language enums.CodingLanguage `bson:"language" json:"language"`
+	Code     string               `bson:"code" json:"code"`
+}
+
+// ComparisonSpec configures how outputs are compared for a question
+type ComparisonSpec struct {
+	Mode           enums.ComparisonMode `bson:"mode" json:"mode"`
+	Tolerance      float64              `bson:"tolerance,omitempty" json:"tolerance,omitempty"`           // float mode, defaults to 1e-6
+	UnorderedDepth int                  `bson:"unorderedDepth,omitempty" json:"unorderedDepth,omitempty"` // unordered mode, nesting levels to ignore order in, defaults to 1
+	Checker        *CheckerScript       `bson:"checker,omitempty" json:"-"`
+}
+
+// ReferenceSolution is a trusted solution to a question, never shown to candidates
+type ReferenceSolution struct {
+	Language enums.CodingLanguage `bson:"language" json:"language"`
+	Code     string               `bson:"code" json:"code"`
+}
+
+// ResourceLimits bounds how long and how much memory a solution may use.
+// Zero fields fall back to the next level: language, then question, then the runner's default.
+type ResourceLimits struct {
+	TimeLimitMs   int `bson:"timeLimitMs,omitempty" json:"timeLimitMs,omitempty"`     // per test case
+	MemoryLimitMB int `bson:"memoryLimitMb,omitempty" json:"memoryLimitMb,omitempty"` // per program
+}
+
+// Parameter describes one argument of the function under test.
+// Type is a base type (int, long, double, bool, string, char, ListNode,
+// TreeNode, Node) followed by one "[]" per array dimension, e.g. "int[][]".
+// ListNode and TreeNode values are written as level-order lists, e.g. [1,null,2],
+// and Node graphs as adjacency lists of 1-based values, e.g. [[2],[1]].
+type Parameter struct {
+	Name string `bson:"name" json:"name"`
+	Type string `bson:"type" json:"type"`
+}
+
+// MethodSpec describes one method of the class a design question asks for
+type MethodSpec struct {
+	Name       string      `bson:"name" json:"name"`
+	Parameters []Parameter `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	ReturnType string      `bson:"returnType,omitempty" json:"returnType,omitempty"` // empty or "void" for methods that return nothing
+}
+
+// ClassSpec describes the class candidates implement for a design question.
+// Go solutions provide a Constructor function and exported methods instead of a class.
+type ClassSpec struct {
+	ClassName   string       `bson:"className" json:"className"`
+	Constructor []Parameter  `bson:"constructor,omitempty" json:"constructor,omitempty"` // constructor parameters
+	Methods     []MethodSpec `bson:"methods" json:"methods"`
+}
+
+// ArgumentSpec describes how to generate random values for one argument.
+// Type uses the same syntax as Parameter.Type; length bounds apply to every
+// array dimension, to strings and to the node count of lists, trees and graphs.
+type ArgumentSpec struct {
+	Type        string   `bson:"type" json:"type"`
+	Min         *float64 `bson:"min,omitempty" json:"min,omitempty"`                 // numbers, defaults to -100
+	Max         *float64 `bson:"max,omitempty" json:"max,omitempty"`                 // numbers, defaults to 100
+	MinLength   int      `bson:"minLength,omitempty" json:"minLength,omitempty"`     // arrays and strings
+	MaxLength   int      `bson:"maxLength,omitempty" json:"maxLength,omitempty"`     // arrays and strings, defaults to 10
+	Alphabet    string   `bson:"alphabet,omitempty" json:"alphabet,omitempty"`       // strings and chars, defaults to a-z
+	Rectangular bool     `bson:"rectangular,omitempty" json:"rectangular,omitempty"` // nested arrays all have the same length
+	Unique      bool     `bson:"unique,omitempty" json:"unique,omitempty"`           // innermost arrays have distinct values
+	Sorted      bool     `bson:"sorted,omitempty" json:"sorted,omitempty"`           // innermost arrays are in ascending order
+	Scaled      bool     `bson:"scaled,omitempty" json:"scaled,omitempty"`           // grows with n when estimating complexity
+}
+
+// InputGenerator describes random inputs for stress testing a question, and
+// the trusted solution whose return values they are judged against
+type InputGenerator struct {
+	Arguments       []ArgumentSpec     `bson:"arguments" json:"arguments"`
+	TrustedSolution *ReferenceSolution `bson:"trustedSolution,omitempty" json:"trustedSolution,omitempty"` // defaults to a reference solution
+	Trials          int                `bson:"trials,omitempty" json:"trials,omitempty"`                   // inputs per stress test, defaults to 100
+}
+
+// TechnicalQuestion is a coding problem. Function questions call FunctionName
+// once per test case. Design questions construct Class and call its methods:
+// a test case input is the list of calls and the list of their arguments, e.g.
+// ["LRUCache","put","get"], [[2],[1,1],[1]], and its expected output is the
+// list of results, with null for the constructor and methods returning nothing.
+// Stdio questions run a whole program with a test case's input on stdin and
+// compare what it prints with the expected output line by line.
 type TechnicalQuestion struct {
-	Question     string     `bson:"question" json:"question"`
-	Description  string     `bson:"description" json:"description"`
-	FunctionName string     `bson:"functionName" json:"functionName"`
-	TestCases    []TestCase `bson:"testCases" json:"testCases"`
+	Question     string             `bson:"question" json:"question"`
+	Description  string             `bson:"description" json:"description"`
+	Kind         enums.QuestionKind `bson:"kind,omitempty" json:"kind,omitempty"` // defaults to function
+	FunctionName string             `bson:"functionName" json:"functionName"`
+	Parameters   []Parameter        `bson:"parameters,omitempty" json:"parameters,omitempty"` // required for go, java, cpp
+	ReturnType   string             `bson:"returnType,omitempty" json:"returnType,omitempty"` // needed when the function returns a ListNode, TreeNode or Node
+	Class        *ClassSpec         `bson:"class,omitempty" json:"class,omitempty"`           // design questions only
+	TestCases    []TestCase         `bson:"testCases" json:"testCases"`
+	Comparison   *ComparisonSpec    `bson:"comparison,omitempty" json:"comparison,omitempty"`
+
+	// Names solutions may not use, e.g. sorted, .sort or Math.max, checked in Python, JavaScript and TypeScript
+	BannedBuiltins []string `bson:"bannedBuiltins,omitempty" json:"bannedBuiltins,omitempty"`
+
+	Limits         *ResourceLimits                         `bson:"limits,omitempty" json:"limits,omitempty"`                 // for every language
+	LanguageLimits map[enums.CodingLanguage]ResourceLimits `bson:"languageLimits,omitempty" json:"languageLimits,omitempty"` // overrides per language
+
+	ReferenceSolutions []ReferenceSolution `bson:"referenceSolutions,omitempty" json:"-"`
+	Generator          *InputGenerator     `bson:"generator,omitempty" json:"-"` // random inputs for stress mode
+}
+
+// IsFunction reports whether the question asks for a single function
+func (q TechnicalQuestion) IsFunction() bool {
+	return q.Kind == "" || q.Kind == enums.QuestionKindFunction
+}
+
+// IsDesign reports whether the question asks for a class rather than a function
+func (q TechnicalQuestion) IsDesign() bool {
+	return q.Kind == enums.QuestionKindDesign
+}
+
+// IsStdio reports whether the question asks for a program reading stdin and writing stdout
+func (q TechnicalQuestion) IsStdio() bool {
+	return q.Kind == enums.QuestionKindStdio
+}
+
+// SampleTestCases returns the test cases candidates are allowed to see
+func (q TechnicalQuestion) SampleTestCases() []TestCase {
+	var samples []TestCase
+	for _, testCase := range q.TestCases {
+		if !testCase.Hidden {
+			samples = append(samples, testCase)
+		}
+	}
+	return samples
+}
+
+// LimitsFor returns the resource limits for a language, with per-language overrides applied
+func (q TechnicalQuestion) LimitsFor(language enums.CodingLanguage) ResourceLimits {
+	var limits ResourceLimits
+	if q.Limits != nil {
+		limits = *q.Limits
+	}
+	if override, exists := q.LanguageLimits[language]; exists {
+		if override.TimeLimitMs > 0 {
+			limits.TimeLimitMs = override.TimeLimitMs
+		}
+		if override.MemoryLimitMB > 0 {
+			limits.MemoryLimitMB = override.MemoryLimitMB
+		}
+	}
+	return limits
+}
+
+// ReferenceSolutionFor returns the reference solution in the given language,
+// falling back to any reference solution, or nil if the question has none
+func (q TechnicalQuestion) ReferenceSolutionFor(language enums.CodingLanguage) *ReferenceSolution {
+	for i := range q.ReferenceSolutions {
+		if q.ReferenceSolutions[i].Language == language {
+			return &q.ReferenceSolutions[i]
+		}
+	}
+	if len(q.ReferenceSolutions) > 0 {
+		return &q.ReferenceSolutions[0]
+	}
+	return nil
 }
 
 type TechnicalBank struct {
 	ID         primitive.ObjectID        `bson:"_id,omitempty" json:"id"`
 	Difficulty enums.TechnicalDifficulty `bson:"difficulty" json:"difficulty"`
 	Question   TechnicalQuestion         `bson:"question" json:"question"`
-}
\ No newline at end of file
+}
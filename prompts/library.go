@@ -0,0 +1,328 @@
+package prompts
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// AI tasks with a prompt. Each task's templates are files named <task>.<version>.tmpl.
+const (
+	TaskQuestionCustomization = "question_customization"
+	TaskFeedbackEvaluation    = "feedback_evaluation"
+	TaskHintGeneration        = "hint_generation"
+	TaskTechnicalFeedback     = "technical_feedback"
+)
+
+// weightsFile names the file in the prompt directory that splits traffic between a task's versions
+const weightsFile = "weights.json"
+
+// builtInTemplates are the templates built into the binary, used unless PROMPTS_DIR is set
+//
+//go:embed templates
+var builtInTemplates embed.FS
+
+// JobContext is the job and candidate an interview prompt is about
+type JobContext struct {
+	JobTitle       string
+	JobInfo        string
+	CompanyName    string
+	AdditionalInfo string
+	ResumeText     string
+}
+
+// QuestionCustomizationData is what question customization templates are rendered with
+type QuestionCustomizationData struct {
+	JobContext
+	Questions string // numbered list of the questions to customize
+}
+
+// FeedbackEvaluationData is what interview feedback templates are rendered with
+type FeedbackEvaluationData struct {
+	JobContext
+	QuestionsWithAnswers string // numbered list of the questions and the candidate's answers
+}
+
+// HintGenerationData is what hint templates are rendered with
+type HintGenerationData struct {
+	Question      string
+	UserCode      string
+	UserSpeech    string
+	PreviousHints []string
+}
+
+// TechnicalFeedbackData is what technical feedback templates are rendered with
+type TechnicalFeedbackData struct {
+	JobTitle           string
+	CompanyName        string
+	Question           string
+	Description        string
+	Difficulty         string
+	UserCode           string
+	HintsUsed          int
+	IsCompleted        bool
+	TimeTaken          int // seconds
+	MeasuredComplexity string
+	SubmissionHistory  string
+}
+
+// taskData holds empty data for every task, to check templates when they are loaded
+var taskData = map[string]interface{}{
+	TaskQuestionCustomization: QuestionCustomizationData{},
+	TaskFeedbackEvaluation:    FeedbackEvaluationData{},
+	TaskHintGeneration:        HintGenerationData{},
+	TaskTechnicalFeedback:     TechnicalFeedbackData{},
+}
+
+// templateFuncs are the functions templates can call besides the built-in ones
+var templateFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 }, // numbers a list from 1 inside range
+}
+
+// Config holds prompt configuration
+type Config struct {
+	Dir string // directory holding the templates and weights.json, empty for the built-in templates
+}
+
+// DefaultConfig returns a default prompt configuration from environment variables
+func DefaultConfig() Config {
+	return Config{Dir: os.Getenv("PROMPTS_DIR")}
+}
+
+// files returns the configured prompt directory and a name for it to use in errors
+func (c Config) files() (fs.FS, string, error) {
+	if c.Dir != "" {
+		return os.DirFS(c.Dir), c.Dir, nil
+	}
+	files, err := fs.Sub(builtInTemplates, "templates")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open the built-in prompts: %w", err)
+	}
+	return files, "the built-in prompts", nil
+}
+
+// Library holds every task's prompt templates and how traffic is split between their versions
+type Library struct {
+	tasks map[string]*taskPrompts
+}
+
+// taskPrompts are the versions of one task's prompt
+type taskPrompts struct {
+	templates map[string]*template.Template // by version
+	versions  []string                      // versions served, in name order
+	weights   []int                         // share of traffic for each served version
+	total     int
+}
+
+// Load reads the prompt templates in the configured directory, or the ones
+// built into the binary if none is configured. Every task needs a template. A task with several versions needs their weights in
+// weights.json, a JSON object of task to version to weight:
+//
+//	{"hint_generation": {"v1": 80, "v2": 20}}
+func Load(config Config) (*Library, error) {
+	files, source, err := config.files()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt directory: %w", err)
+	}
+
+	library := &Library{tasks: make(map[string]*taskPrompts)}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+
+		task, version, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".tmpl"), ".")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("prompt template %s is not named <task>.<version>.tmpl", entry.Name())
+		}
+		data, known := taskData[task]
+		if !known {
+			return nil, fmt.Errorf("prompt template %s is for an unknown task: %s", entry.Name(), task)
+		}
+
+		tmpl, err := parseTemplate(files, entry.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+
+		if library.tasks[task] == nil {
+			library.tasks[task] = &taskPrompts{templates: make(map[string]*template.Template)}
+		}
+		library.tasks[task].templates[version] = tmpl
+	}
+
+	weights, err := loadWeights(files)
+	if err != nil {
+		return nil, err
+	}
+	for task := range weights {
+		if _, known := taskData[task]; !known {
+			return nil, fmt.Errorf("%s gives weights for an unknown task: %s", weightsFile, task)
+		}
+	}
+
+	tasks := make([]string, 0, len(taskData))
+	for task := range taskData {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+	for _, task := range tasks {
+		prompts := library.tasks[task]
+		if prompts == nil {
+			return nil, fmt.Errorf("no prompt template for %s in %s", task, source)
+		}
+		if err := prompts.setWeights(task, weights[task]); err != nil {
+			return nil, err
+		}
+	}
+
+	return library, nil
+}
+
+// parseTemplate parses a template file and checks it renders with the task's data
+func parseTemplate(files fs.FS, name string, data interface{}) (*template.Template, error) {
+	text, err := fs.ReadFile(files, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	tmpl, err := template.New(path.Base(name)).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	// Referring to a field the task does not have only fails when rendering
+	if err := tmpl.Execute(io.Discard, data); err != nil {
+		return nil, fmt.Errorf("prompt template %s does not render: %w", path.Base(name), err)
+	}
+
+	return tmpl, nil
+}
+
+// loadWeights reads weights.json, which is optional
+func loadWeights(files fs.FS) (map[string]map[string]int, error) {
+	data, err := fs.ReadFile(files, weightsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt weights: %w", err)
+	}
+
+	var weights map[string]map[string]int
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", weightsFile, err)
+	}
+	return weights, nil
+}
+
+// setWeights decides which versions of the task are served and how often
+func (p *taskPrompts) setWeights(task string, weights map[string]int) error {
+	if weights == nil {
+		if len(p.templates) > 1 {
+			return fmt.Errorf("%s has versions %s; give their weights in %s", task, strings.Join(sortedVersions(p.templates), ", "), weightsFile)
+		}
+		weights = map[string]int{sortedVersions(p.templates)[0]: 1}
+	}
+
+	versions := make([]string, 0, len(weights))
+	for version := range weights {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		weight := weights[version]
+		if p.templates[version] == nil {
+			return fmt.Errorf("%s gives a weight for %s version %s, which has no template", weightsFile, task, version)
+		}
+		if weight < 0 {
+			return fmt.Errorf("%s gives %s version %s a negative weight", weightsFile, task, version)
+		}
+		if weight == 0 {
+			continue
+		}
+		p.versions = append(p.versions, version)
+		p.weights = append(p.weights, weight)
+		p.total += weight
+	}
+	if p.total == 0 {
+		return fmt.Errorf("%s gives no version of %s a weight", weightsFile, task)
+	}
+
+	return nil
+}
+
+// sortedVersions lists a task's versions in name order
+func sortedVersions(templates map[string]*template.Template) []string {
+	versions := make([]string, 0, len(templates))
+	for version := range templates {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Render picks a version of the task's prompt for the key and renders it with
+// data, returning the prompt and its version. The same key always gets the
+// same version, so passing a session ID keeps a session on one version.
+func (l *Library) Render(task string, key string, data interface{}) (string, string, error) {
+	prompts := l.tasks[task]
+	if prompts == nil {
+		return "", "", fmt.Errorf("no prompt for task %s", task)
+	}
+
+	version := prompts.pick(task, key)
+	var prompt strings.Builder
+	if err := prompts.templates[version].Execute(&prompt, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s prompt %s: %w", task, version, err)
+	}
+
+	return prompt.String(), version, nil
+}
+
+// Versions lists the versions of the task's prompt that are served, with their weights
+func (l *Library) Versions(task string) map[string]int {
+	prompts := l.tasks[task]
+	if prompts == nil {
+		return nil
+	}
+
+	versions := make(map[string]int, len(prompts.versions))
+	for i, version := range prompts.versions {
+		versions[version] = prompts.weights[i]
+	}
+	return versions
+}
+
+// pick hashes the key into the weighted versions
+func (p *taskPrompts) pick(task string, key string) string {
+	if len(p.versions) == 1 {
+		return p.versions[0]
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(task + "/" + key))
+	bucket := int(hash.Sum32() % uint32(p.total))
+	for i, weight := range p.weights {
+		if bucket < weight {
+			return p.versions[i]
+		}
+		bucket -= weight
+	}
+	return p.versions[len(p.versions)-1]
+}
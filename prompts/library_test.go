@@ -0,0 +1,170 @@
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePrompts writes a v1 template for every task plus the given files to a
+// new prompt directory
+func writePrompts(t *testing.T, files map[string]string) Config {
+	t.Helper()
+	dir := t.TempDir()
+	for task := range taskData {
+		files[task+".v1.tmpl"] = task + " v1"
+	}
+	for name, text := range files {
+		if text == "" {
+			continue // left out
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Config{Dir: dir}
+}
+
+func TestLoadSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "built-in prompts", config: Config{}},
+		{name: "directory", config: writePrompts(t, map[string]string{})},
+		{name: "missing directory", config: Config{Dir: filepath.Join(t.TempDir(), "missing")}, wantErr: "failed to read prompt directory"},
+		{name: "directory missing a task", config: Config{Dir: t.TempDir()}, wantErr: "no prompt template for"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library, err := Load(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			for task := range taskData {
+				if len(library.Versions(task)) == 0 {
+					t.Errorf("Load() served no version of %s", task)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPicksWeightedVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights string
+		want    map[string]float64 // share of keys served each version
+	}{
+		{name: "single version", want: map[string]float64{"v1": 1}},
+		{name: "even split", weights: `{"hint_generation": {"v1": 1, "v2": 1}}`, want: map[string]float64{"v1": 0.5, "v2": 0.5}},
+		{name: "uneven split", weights: `{"hint_generation": {"v1": 80, "v2": 20}}`, want: map[string]float64{"v1": 0.8, "v2": 0.2}},
+		{name: "retired version", weights: `{"hint_generation": {"v1": 0, "v2": 5}}`, want: map[string]float64{"v2": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{weightsFile: tt.weights}
+			if tt.weights != "" {
+				files[TaskHintGeneration+".v2.tmpl"] = TaskHintGeneration + " v2"
+			}
+			library, err := Load(writePrompts(t, files))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			const keys = 2000
+			served := make(map[string]int)
+			for i := 0; i < keys; i++ {
+				key := fmt.Sprintf("session-%d", i)
+				prompt, version, err := library.Render(TaskHintGeneration, key, HintGenerationData{})
+				if err != nil {
+					t.Fatalf("Render() error = %v", err)
+				}
+				if prompt != TaskHintGeneration+" "+version {
+					t.Fatalf("Render() = %q for version %s", prompt, version)
+				}
+				if _, again, _ := library.Render(TaskHintGeneration, key, HintGenerationData{}); again != version {
+					t.Fatalf("Render() served %s and then %s for key %s", version, again, key)
+				}
+				served[version]++
+			}
+
+			for version := range served {
+				if tt.want[version] == 0 {
+					t.Errorf("Render() served version %s, which has no weight", version)
+				}
+			}
+			for version, share := range tt.want {
+				if got := float64(served[version]) / keys; got < share-0.05 || got > share+0.05 {
+					t.Errorf("Render() served version %s to %.2f of keys, want about %.2f", version, got, share)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejectsBadPrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "versions without weights",
+			files:   map[string]string{"hint_generation.v2.tmpl": "v2"},
+			wantErr: "give their weights in weights.json",
+		},
+		{
+			name:    "weight for a missing version",
+			files:   map[string]string{weightsFile: `{"hint_generation": {"v1": 1, "v3": 1}}`},
+			wantErr: "version v3, which has no template",
+		},
+		{
+			name:    "negative weight",
+			files:   map[string]string{weightsFile: `{"hint_generation": {"v1": -1}}`},
+			wantErr: "negative weight",
+		},
+		{
+			name:    "no weight at all",
+			files:   map[string]string{weightsFile: `{"hint_generation": {"v1": 0}}`},
+			wantErr: "gives no version of hint_generation a weight",
+		},
+		{
+			name:    "weights for an unknown task",
+			files:   map[string]string{weightsFile: `{"jokes": {"v1": 1}}`},
+			wantErr: "unknown task: jokes",
+		},
+		{
+			name:    "template for an unknown task",
+			files:   map[string]string{"jokes.v1.tmpl": "ha"},
+			wantErr: "unknown task: jokes",
+		},
+		{
+			name:    "template without a version",
+			files:   map[string]string{"hint_generation.tmpl": "hint"},
+			wantErr: "is not named <task>.<version>.tmpl",
+		},
+		{
+			name:    "template using a missing field",
+			files:   map[string]string{"hint_generation.v2.tmpl": "{{.Resume}}", weightsFile: `{"hint_generation": {"v1": 1, "v2": 1}}`},
+			wantErr: "does not render",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePrompts(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
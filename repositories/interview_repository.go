@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // InterviewRepository handles MongoDB operations for interview sessions and related data
@@ -18,6 +19,9 @@ type InterviewRepository struct {
 	sessionsCollection     *mongo.Collection
 	questionsCollection    *mongo.Collection
 	technicalBankCollection *mongo.Collection
+	submissionsCollection   *mongo.Collection
+	snapshotsCollection     *mongo.Collection
+	executionCacheCollection *mongo.Collection
 }
 
 // NewInterviewRepository creates a new interview repository with all collections
@@ -26,6 +30,9 @@ func NewInterviewRepository(db *mongo.Database) *InterviewRepository {
 		sessionsCollection:     db.Collection("interview_sessions"),
 		questionsCollection:    db.Collection("question_bank"),
 		technicalBankCollection: db.Collection("technical_bank"),
+		submissionsCollection:   db.Collection("submissions"),
+		snapshotsCollection:     db.Collection("editor_snapshots"),
+		executionCacheCollection: db.Collection("execution_cache"),
 	}
 }
 
@@ -66,7 +73,204 @@ func (r *InterviewRepository) GetBySessionID(sessionID string) (*models.Intervie
 	return &session, nil
 }
 
+// AddTechnicalVerdict records a final technical verdict on an interview session
+func (r *InterviewRepository) AddTechnicalVerdict(sessionID string, verdict models.TechnicalVerdict) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	verdict.SubmittedAt = time.Now()
+
+	result, err := r.sessionsCollection.UpdateOne(ctx,
+		bson.M{"session_id": sessionID},
+		bson.M{"$push": bson.M{"technical_verdicts": verdict}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("not found")
+	}
+
+	return nil
+}
+
+// AddHintUsage records that a hint was given for a technical question on an interview session
+func (r *InterviewRepository) AddHintUsage(sessionID string, questionID string, promptVersion string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usage := models.HintUsage{QuestionID: questionID, RequestedAt: time.Now(), PromptVersion: promptVersion}
+	result, err := r.sessionsCollection.UpdateOne(ctx,
+		bson.M{"session_id": sessionID},
+		bson.M{"$push": bson.M{"hint_usages": usage}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("not found")
+	}
+
+	return nil
+}
+
+// CreateSubmission stores an execution of a candidate's code
+func (r *InterviewRepository) CreateSubmission(submission *models.Submission) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	submission.CreatedAt = time.Now()
+
+	result, err := r.submissionsCollection.InsertOne(ctx, submission)
+	if err != nil {
+		return err
+	}
+
+	submission.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// SetSubmissionComplexity attaches a complexity estimate measured after the submission was judged
+func (r *InterviewRepository) SetSubmissionComplexity(submissionID primitive.ObjectID, estimate *models.ComplexityEstimate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.submissionsCollection.UpdateOne(ctx,
+		bson.M{"_id": submissionID},
+		bson.M{"$set": bson.M{"complexity": estimate}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("not found")
+	}
+
+	return nil
+}
+
+// GetSubmissionsBySession retrieves a session's submissions, oldest first,
+// optionally only those for one question
+func (r *InterviewRepository) GetSubmissionsBySession(sessionID string, questionID string) ([]models.Submission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"session_id": sessionID}
+	if questionID != "" {
+		filter["question_id"] = questionID
+	}
+
+	cursor, err := r.submissionsCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	submissions := []models.Submission{}
+	if err = cursor.All(ctx, &submissions); err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// CreateEditorSnapshot stores an editor snapshot. Sequence numbers are unique
+// per session and question, so a snapshot racing another for the same number fails.
+func (r *InterviewRepository) CreateEditorSnapshot(snapshot *models.EditorSnapshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snapshot.RecordedAt = time.Now()
+
+	result, err := r.snapshotsCollection.InsertOne(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
 
+// GetLatestEditorKeyframe retrieves the most recent keyframe snapshot of a question's code, or nil if there is none
+func (r *InterviewRepository) GetLatestEditorKeyframe(sessionID string, questionID string) (*models.EditorSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var snapshot models.EditorSnapshot
+	err := r.snapshotsCollection.FindOne(ctx,
+		bson.M{"session_id": sessionID, "question_id": questionID, "keyframe": true},
+		options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}}),
+	).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// GetEditorSnapshots retrieves a question's editor snapshots from a sequence
+// number on, in order, stopping after the last one recorded by until if it is set
+func (r *InterviewRepository) GetEditorSnapshots(sessionID string, questionID string, fromSequence int, until *time.Time) ([]models.EditorSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"session_id": sessionID, "question_id": questionID, "sequence": bson.M{"$gte": fromSequence}}
+	if until != nil {
+		filter["recorded_at"] = bson.M{"$lte": *until}
+	}
+	cursor, err := r.snapshotsCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []models.EditorSnapshot{}
+	if err = cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetCachedExecution retrieves a cached execution by key, or nil if there is none
+func (r *InterviewRepository) GetCachedExecution(key string) (*models.CachedExecution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var execution models.CachedExecution
+	err := r.executionCacheCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&execution)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &execution, nil
+}
+
+// SaveCachedExecution stores a cached execution, replacing any with the same key
+func (r *InterviewRepository) SaveCachedExecution(execution *models.CachedExecution) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	execution.CreatedAt = time.Now()
+
+	_, err := r.executionCacheCollection.ReplaceOne(ctx, bson.M{"_id": execution.Key}, execution, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeleteCachedExecutions removes a question's cached executions judged against any revision but keepRevision
+func (r *InterviewRepository) DeleteCachedExecutions(questionID string, keepRevision string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.executionCacheCollection.DeleteMany(ctx, bson.M{"question_id": questionID, "revision": bson.M{"$ne": keepRevision}})
+	return err
+}
 
 // GetQuestionsByBehavioralTopic retrieves questions by behavioral topic
 func (r *InterviewRepository) GetQuestionsByBehavioralTopic(topic string) ([]models.QuestionBank, error) {
@@ -155,6 +359,25 @@ func (r *InterviewRepository) GetTechnicalQuestionByDifficulty(difficulty string
 	return &questions[randomIndex], nil
 }
 
+// GetAllTechnicalQuestions retrieves every question in the technical bank
+func (r *InterviewRepository) GetAllTechnicalQuestions() ([]models.TechnicalBank, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.technicalBankCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var questions []models.TechnicalBank
+	if err = cursor.All(ctx, &questions); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
 // GetTechnicalQuestionByID retrieves a technical question by its ID
 func (r *InterviewRepository) GetTechnicalQuestionByID(questionID string) (*models.TechnicalBank, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"stormhacks-be/models"
+)
+
+// harnessCaseOutput is what the batch harness reported for one test case
+type harnessCaseOutput struct {
+	Index     int
+	Logs      string  // everything the candidate's code printed while the case ran
+	Result    string  // the serialized return value, reported in the end record
+	Status    string  // "ok", "error", or empty if the case never finished
+	Time      float64 // milliseconds spent inside the candidate function
+	Error     string
+	ErrorType string
+}
+
+// harnessCaseRecord is the JSON record the harness prints after each case.
+// The return value travels in the record rather than on stdout so the
+// candidate's own prints can never be mistaken for it.
+type harnessCaseRecord struct {
+	Status    string  `json:"status"`
+	Result    string  `json:"result"`
+	Time      float64 `json:"time"`
+	Error     string  `json:"error"`
+	ErrorType string  `json:"errorType"`
+}
+
+// batchCaseRun pairs a test case's harness output with the run it happened in
+type batchCaseRun struct {
+	Output      harnessCaseOutput
+	Run         *RunResult
+	RunErr      error
+	TimeLimitMs int // per-case time limit the case is judged against, zero if none
+}
+
+// harnessStartupAllowance is added to a harness program's time limit to cover
+// interpreter/JVM startup, which is not charged to any test case
+const harnessStartupAllowance = 2 * time.Second
+
+// runTestCasesBatched runs every test case through a single harness program.
+// If the program dies part-way (timeout, crash) the unfinished case is charged
+// with the failure and the remaining cases are run again in a fresh program.
+// The program's time limit is the question's per-case limit times the number of
+// cases it runs. It also returns the total wall time spent running programs, in milliseconds.
+// Stdin/stdout programs have no harness and run once per case instead.
+func runTestCasesBatched(codeRunner CodeRunner, driver LanguageDriver, code string, question models.TechnicalQuestion, testCases []models.TestCase) ([]batchCaseRun, int64, error) {
+	if question.IsStdio() {
+		return runStdioTestCases(codeRunner, driver, code, question, testCases)
+	}
+
+	runs := make([]batchCaseRun, len(testCases))
+	var totalExecutionTime int64
+	limits := question.LimitsFor(driver.Language())
+
+	for start := 0; start < len(testCases); {
+		nonce, err := newHarnessNonce()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		harness, err := buildBatchHarness(driver, code, question, testCases[start:], nonce)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		runRequest := RunRequest{
+			Language:      string(driver.Language()),
+			Code:          harness,
+			MemoryLimitMB: limits.MemoryLimitMB,
+		}
+		if limits.TimeLimitMs > 0 {
+			remaining := len(testCases) - start
+			runRequest.TimeLimit = time.Duration(limits.TimeLimitMs*remaining)*time.Millisecond + harnessStartupAllowance
+		}
+
+		startTime := time.Now()
+		runResult, runErr := codeRunner.Run(context.Background(), runRequest)
+		totalExecutionTime += time.Since(startTime).Milliseconds()
+
+		// Runner failure or a program that never reached the first case (e.g. a compile error)
+		// applies to every remaining case
+		var outputs []harnessCaseOutput
+		if runErr == nil {
+			outputs = parseHarnessOutput(runResult.Stdout, nonce)
+		}
+		if len(outputs) == 0 {
+			for i := start; i < len(testCases); i++ {
+				runs[i] = batchCaseRun{Output: harnessCaseOutput{Index: i}, Run: runResult, RunErr: runErr, TimeLimitMs: limits.TimeLimitMs}
+			}
+			break
+		}
+
+		// Resume after the last case that finished, skipping one that never did
+		next := start + len(outputs)
+		for _, output := range outputs {
+			index := start + output.Index
+			if index >= len(testCases) {
+				continue
+			}
+			output.Index = index
+			if output.Status == "ok" {
+				output.Result = normalizeNodeResult(question, testCases[index].Input, output.Result)
+			}
+			runs[index] = batchCaseRun{Output: output, Run: runResult, TimeLimitMs: limits.TimeLimitMs}
+			if output.Status == "" {
+				next = index + 1
+				break
+			}
+		}
+		start = next
+	}
+
+	return runs, totalExecutionTime, nil
+}
+
+// runHiddenTestCasesApart runs the sample and hidden test cases in separate harness
+// programs, so hidden inputs are never in a program whose output the candidate sees.
+// Runs are returned in the order of testCases.
+func runHiddenTestCasesApart(codeRunner CodeRunner, driver LanguageDriver, code string, question models.TechnicalQuestion, testCases []models.TestCase) ([]batchCaseRun, int64, error) {
+	var sample, hidden []int
+	for i, testCase := range testCases {
+		if testCase.Hidden {
+			hidden = append(hidden, i)
+		} else {
+			sample = append(sample, i)
+		}
+	}
+
+	runs := make([]batchCaseRun, len(testCases))
+	var totalExecutionTime int64
+	for _, group := range [][]int{sample, hidden} {
+		if len(group) == 0 {
+			continue
+		}
+		groupCases := make([]models.TestCase, len(group))
+		for j, index := range group {
+			groupCases[j] = testCases[index]
+		}
+
+		groupRuns, executionTime, err := runTestCasesBatched(codeRunner, driver, code, question, groupCases)
+		if err != nil {
+			return nil, 0, err
+		}
+		totalExecutionTime += executionTime
+		for j, index := range group {
+			groupRuns[j].Output.Index = index
+			runs[index] = groupRuns[j]
+		}
+	}
+	return runs, totalExecutionTime, nil
+}
+
+// buildBatchHarness formats each test case's arguments for the driver's language
+// and wraps the candidate's code in the driver's harness program
+func buildBatchHarness(driver LanguageDriver, code string, question models.TechnicalQuestion, testCases []models.TestCase, nonce string) (string, error) {
+	spec := HarnessSpec{
+		Code:         code,
+		FunctionName: question.FunctionName,
+		Nonce:        nonce,
+	}
+	spec.ListNode, spec.TreeNode, spec.GraphNode = nodeTypesUsed(question)
+
+	if question.IsDesign() {
+		if question.Class == nil {
+			return "", fmt.Errorf("design question does not describe its class")
+		}
+		spec.ClassName = question.Class.ClassName
+		spec.Calls = make([][]HarnessCall, len(testCases))
+		for i, testCase := range testCases {
+			calls, err := formatDesignCalls(driver, testCase.Input, *question.Class)
+			if err != nil {
+				return "", fmt.Errorf("invalid test case input %q: %w", testCase.Input, err)
+			}
+			spec.Calls[i] = calls
+		}
+		return driver.BuildHarness(spec)
+	}
+
+	spec.Cases = make([][]string, len(testCases))
+	for i, testCase := range testCases {
+		args, err := formatTestCaseArguments(driver, testCase.Input, question.Parameters)
+		if err != nil {
+			return "", fmt.Errorf("invalid test case input %q: %w", testCase.Input, err)
+		}
+		spec.Cases[i] = args
+	}
+	return driver.BuildHarness(spec)
+}
+
+// harnessTemplateFuncs are the helpers available to harness templates
+var harnessTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// renderHarness executes a driver's harness template
+func renderHarness(tmpl *template.Template, spec HarnessSpec) (string, error) {
+	var harness strings.Builder
+	if err := tmpl.Execute(&harness, spec); err != nil {
+		return "", fmt.Errorf("failed to render %s harness: %w", tmpl.Name(), err)
+	}
+	return harness.String(), nil
+}
+
+// parseHarnessOutput splits the harness stdout into per-case outputs.
+// A case that began but has no end marker is returned with an empty status.
+func parseHarnessOutput(stdout, nonce string) []harnessCaseOutput {
+	beginTag := "@@" + nonce + " BEGIN "
+	endTag := "\n@@" + nonce + " END "
+
+	var outputs []harnessCaseOutput
+	rest := stdout
+	for {
+		begin := strings.Index(rest, beginTag)
+		if begin < 0 {
+			break
+		}
+		rest = rest[begin+len(beginTag):]
+
+		closing := strings.Index(rest, "@@\n")
+		if closing < 0 {
+			break
+		}
+		index, err := strconv.Atoi(rest[:closing])
+		if err != nil {
+			break
+		}
+		rest = rest[closing+len("@@\n"):]
+
+		output := harnessCaseOutput{Index: index}
+		end := strings.Index(rest, endTag)
+		if end < 0 {
+			// Program died while this case was running
+			output.Logs = rest
+			outputs = append(outputs, output)
+			break
+		}
+		output.Logs = rest[:end]
+		rest = rest[end+len(endTag):]
+
+		lineEnd := strings.Index(rest, "\n")
+		if lineEnd < 0 {
+			lineEnd = len(rest)
+		}
+		line := strings.TrimSuffix(rest[:lineEnd], "@@")
+		rest = rest[lineEnd:]
+
+		var record harnessCaseRecord
+		if _, recordJSON, found := strings.Cut(line, " "); found && json.Unmarshal([]byte(recordJSON), &record) == nil {
+			output.Status = record.Status
+			output.Result = record.Result
+			output.Time = record.Time
+			output.Error = record.Error
+			output.ErrorType = record.ErrorType
+		}
+		outputs = append(outputs, output)
+	}
+
+	return outputs
+}
+
+// newHarnessNonce returns a random marker token candidates cannot predict
+func newHarnessNonce() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate harness nonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestParseHarnessOutput(t *testing.T) {
+	const nonce = "abc123"
+	begin := func(index string) string { return "@@" + nonce + " BEGIN " + index + "@@\n" }
+	end := func(index, record string) string { return "\n@@" + nonce + " END " + index + " " + record + "@@\n" }
+
+	tests := []struct {
+		name   string
+		stdout string
+		want   []harnessCaseOutput
+	}{
+		{name: "no output", stdout: ""},
+		{
+			name: "finished cases",
+			stdout: begin("0") + end("0", `{"status": "ok", "result": "[1, 2]", "time": 0.5}`) +
+				begin("1") + "debug\n" + end("1", `{"status": "error", "error": "boom", "errorType": "ValueError", "time": 1}`),
+			want: []harnessCaseOutput{
+				{Index: 0, Status: "ok", Result: "[1, 2]", Time: 0.5},
+				{Index: 1, Logs: "debug\n", Status: "error", Error: "boom", ErrorType: "ValueError", Time: 1},
+			},
+		},
+		{
+			name:   "died during a case",
+			stdout: begin("0") + end("0", `{"status": "ok", "result": "1"}`) + begin("1") + "partial",
+			want: []harnessCaseOutput{
+				{Index: 0, Status: "ok", Result: "1"},
+				{Index: 1, Logs: "partial"},
+			},
+		},
+		{
+			name:   "output before the first case",
+			stdout: "top-level print\n" + begin("0") + end("0", `{"status": "ok", "result": "1"}`),
+			want:   []harnessCaseOutput{{Index: 0, Status: "ok", Result: "1"}},
+		},
+		{
+			name:   "forged markers without the nonce",
+			stdout: begin("0") + "@@wrong END 0 {\"status\": \"ok\", \"result\": \"9\"}@@\n" + end("0", `{"status": "ok", "result": "1"}`),
+			want:   []harnessCaseOutput{{Index: 0, Logs: "@@wrong END 0 {\"status\": \"ok\", \"result\": \"9\"}@@\n", Status: "ok", Result: "1"}},
+		},
+		{
+			name:   "malformed record",
+			stdout: begin("0") + end("0", `{"status": `),
+			want:   []harnessCaseOutput{{Index: 0}},
+		},
+		{
+			name:   "malformed index",
+			stdout: begin("x") + end("x", `{"status": "ok"}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseHarnessOutput(tt.stdout, nonce); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHarnessOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHiddenTestCasesApart(t *testing.T) {
+	tests := []struct {
+		name         string
+		hidden       []bool
+		wantPrograms [][]int // the cases each harness program holds
+	}{
+		{name: "sample only", hidden: []bool{false, false}, wantPrograms: [][]int{{0, 1}}},
+		{name: "hidden only", hidden: []bool{true}, wantPrograms: [][]int{{0}}},
+		{name: "mixed", hidden: []bool{true, false, true, false}, wantPrograms: [][]int{{1, 3}, {0, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.TechnicalQuestion{FunctionName: "solve"}
+			for i, hidden := range tt.hidden {
+				question.TestCases = append(question.TestCases, models.TestCase{Input: fmt.Sprintf("'input-%d'", i), Hidden: hidden})
+			}
+			driver, _ := GetLanguageDriver(string(enums.CodingLanguagePython))
+			runner := &stubRunner{result: &RunResult{ExitCode: 1}}
+
+			runs, _, err := runHiddenTestCasesApart(runner, driver, "def solve(s): return s", question, question.TestCases)
+			if err != nil {
+				t.Fatalf("runHiddenTestCasesApart() error = %v", err)
+			}
+			if len(runs) != len(tt.hidden) {
+				t.Fatalf("runHiddenTestCasesApart() returned %d runs, want %d", len(runs), len(tt.hidden))
+			}
+			for i, run := range runs {
+				if run.Output.Index != i {
+					t.Errorf("run %d has index %d", i, run.Output.Index)
+				}
+			}
+
+			if len(runner.requests) != len(tt.wantPrograms) {
+				t.Fatalf("ran %d programs, want %d", len(runner.requests), len(tt.wantPrograms))
+			}
+			for p, cases := range tt.wantPrograms {
+				var got []int
+				for i := range tt.hidden {
+					if strings.Contains(runner.requests[p].Code, fmt.Sprintf("input-%d", i)) {
+						got = append(got, i)
+					}
+				}
+				if !reflect.DeepEqual(got, cases) {
+					t.Errorf("program %d holds cases %v, want %v", p+1, got, cases)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTestCasesBatchedLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		limits         *models.ResourceLimits
+		languageLimits map[enums.CodingLanguage]models.ResourceLimits
+		wantCaseLimit  int // ms each case is judged against
+		wantTimeLimit  time.Duration
+		wantMemoryMB   int
+	}{
+		{name: "runner defaults"},
+		{
+			name:          "question limits",
+			limits:        &models.ResourceLimits{TimeLimitMs: 1000, MemoryLimitMB: 128},
+			wantCaseLimit: 1000,
+			wantTimeLimit: 3*time.Second + harnessStartupAllowance,
+			wantMemoryMB:  128,
+		},
+		{
+			name:           "language override",
+			limits:         &models.ResourceLimits{TimeLimitMs: 1000, MemoryLimitMB: 128},
+			languageLimits: map[enums.CodingLanguage]models.ResourceLimits{enums.CodingLanguagePython: {TimeLimitMs: 3000}},
+			wantCaseLimit:  3000,
+			wantTimeLimit:  9*time.Second + harnessStartupAllowance,
+			wantMemoryMB:   128,
+		},
+		{
+			name:           "other language override",
+			languageLimits: map[enums.CodingLanguage]models.ResourceLimits{enums.CodingLanguageJava: {TimeLimitMs: 3000, MemoryLimitMB: 512}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.TechnicalQuestion{
+				FunctionName:   "solve",
+				Limits:         tt.limits,
+				LanguageLimits: tt.languageLimits,
+				TestCases:      []models.TestCase{{Input: "1"}, {Input: "2"}, {Input: "3"}},
+			}
+			driver, _ := GetLanguageDriver(string(enums.CodingLanguagePython))
+			runner := &stubRunner{result: &RunResult{}}
+
+			runs, _, err := runTestCasesBatched(runner, driver, "def solve(x): return x", question, question.TestCases)
+			if err != nil {
+				t.Fatalf("runTestCasesBatched() error = %v", err)
+			}
+			request := runner.requests[0]
+			if request.TimeLimit != tt.wantTimeLimit || request.MemoryLimitMB != tt.wantMemoryMB {
+				t.Errorf("ran with %v, %d MB, want %v, %d MB", request.TimeLimit, request.MemoryLimitMB, tt.wantTimeLimit, tt.wantMemoryMB)
+			}
+			if runs[0].TimeLimitMs != tt.wantCaseLimit {
+				t.Errorf("cases judged against %d ms, want %d ms", runs[0].TimeLimitMs, tt.wantCaseLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+// CodePolicyConfig configures what submitted code may do. Module and built-in
+// lists are keyed by language; TypeScript shares JavaScript's lists.
+type CodePolicyConfig struct {
+	MaxCodeBytes     int
+	ForbiddenModules map[enums.CodingLanguage][]string
+	BannedBuiltins   map[enums.CodingLanguage][]string // banned in every question
+}
+
+// Default policy lists, used unless the matching environment variable is set
+var (
+	defaultPythonForbiddenModules = []string{
+		"builtins", "ctypes", "http", "importlib", "multiprocessing", "os", "pathlib",
+		"pty", "shutil", "signal", "socket", "subprocess", "urllib",
+	}
+	defaultJavaScriptForbiddenModules = []string{
+		"child_process", "cluster", "dgram", "dns", "http", "http2", "https", "inspector",
+		"module", "net", "tls", "vm", "worker_threads",
+	}
+	defaultPythonBannedBuiltins     = []string{"__import__", "compile", "eval", "exec"}
+	defaultJavaScriptBannedBuiltins = []string{"eval", "Function", "process.binding", "process.dlopen"}
+)
+
+// DefaultCodePolicyConfig reads the code policy from the environment. Lists
+// are comma separated, and an empty variable turns its list off.
+func DefaultCodePolicyConfig() CodePolicyConfig {
+	maxCodeBytes, err := strconv.Atoi(os.Getenv("POLICY_MAX_CODE_BYTES"))
+	if err != nil || maxCodeBytes <= 0 {
+		maxCodeBytes = 64 * 1024
+	}
+
+	pythonModules := envList("POLICY_FORBIDDEN_MODULES_PYTHON", defaultPythonForbiddenModules)
+	javaScriptModules := envList("POLICY_FORBIDDEN_MODULES_JS", defaultJavaScriptForbiddenModules)
+	pythonBuiltins := envList("POLICY_BANNED_BUILTINS_PYTHON", defaultPythonBannedBuiltins)
+	javaScriptBuiltins := envList("POLICY_BANNED_BUILTINS_JS", defaultJavaScriptBannedBuiltins)
+
+	return CodePolicyConfig{
+		MaxCodeBytes: maxCodeBytes,
+		ForbiddenModules: map[enums.CodingLanguage][]string{
+			enums.CodingLanguagePython:     pythonModules,
+			enums.CodingLanguageJavaScript: javaScriptModules,
+			enums.CodingLanguageTypeScript: javaScriptModules,
+		},
+		BannedBuiltins: map[enums.CodingLanguage][]string{
+			enums.CodingLanguagePython:     pythonBuiltins,
+			enums.CodingLanguageJavaScript: javaScriptBuiltins,
+			enums.CodingLanguageTypeScript: javaScriptBuiltins,
+		},
+	}
+}
+
+// envList reads a comma separated list, falling back to defaults when the variable is unset
+func envList(name string, defaults []string) []string {
+	value, set := os.LookupEnv(name)
+	if !set {
+		return defaults
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// PolicyViolation explains why code was refused before running
+type PolicyViolation struct {
+	Line    int // 1-based line of the offending code, zero if it concerns the whole program
+	Message string
+}
+
+func (v *PolicyViolation) Error() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s", v.Line, v.Message)
+	}
+	return v.Message
+}
+
+// CodePolicy inspects submitted code before it reaches the code runner. It
+// keeps candidates to a question's constraints and away from modules that
+// reach the host, but it is a first line of defense, not a sandbox.
+type CodePolicy struct {
+	config CodePolicyConfig
+}
+
+// NewCodePolicy creates a code policy
+func NewCodePolicy(config CodePolicyConfig) *CodePolicy {
+	return &CodePolicy{config: config}
+}
+
+// Check returns the first way code breaks the policy or the question's banned
+// built-ins, or nil if it breaks none. Only the size limit applies to
+// languages whose drivers cannot scan their source.
+func (p *CodePolicy) Check(driver LanguageDriver, code string, question models.TechnicalQuestion) *PolicyViolation {
+	if p.config.MaxCodeBytes > 0 && len(code) > p.config.MaxCodeBytes {
+		return &PolicyViolation{Message: fmt.Sprintf("code is %d bytes, more than the limit of %d", len(code), p.config.MaxCodeBytes)}
+	}
+
+	scanner, ok := driver.(sourceScanner)
+	if !ok {
+		return nil
+	}
+	scan := scanner.scanSource(code)
+
+	forbidden := p.config.ForbiddenModules[driver.Language()]
+	for _, imported := range scan.imports {
+		if imported.module == "" {
+			return &PolicyViolation{Line: imported.line, Message: "modules must be loaded by a literal name"}
+		}
+		for _, module := range forbidden {
+			if imported.module == module || strings.HasPrefix(imported.module, module+".") || strings.HasPrefix(imported.module, module+"/") {
+				return &PolicyViolation{Line: imported.line, Message: fmt.Sprintf("module %s is not allowed", imported.module)}
+			}
+		}
+	}
+
+	for _, name := range p.config.BannedBuiltins[driver.Language()] {
+		if line, found := findName(scan.tokens, name); found {
+			return &PolicyViolation{Line: line, Message: fmt.Sprintf("%s is not allowed", name)}
+		}
+	}
+	for _, name := range question.BannedBuiltins {
+		if line, found := findName(scan.tokens, name); found {
+			return &PolicyViolation{Line: line, Message: fmt.Sprintf("%s is not allowed in this question", name)}
+		}
+	}
+	return nil
+}
+
+// findName looks for a use of a name in code, returning its line. A plain name
+// like sorted matches the identifier but not an attribute of the same name, a
+// name with a leading dot like .sort matches only attributes, and a dotted name
+// like Math.max matches that attribute of that object.
+func findName(tokens []sourceToken, name string) (int, bool) {
+	parts := strings.Split(name, ".")
+	attribute := parts[0] == ""
+	if attribute {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return 0, false
+	}
+
+	for i, token := range tokens {
+		if token.kind != tokenIdentifier || token.text != parts[0] {
+			continue
+		}
+		if afterDot := i > 0 && tokens[i-1].isPunctuation("."); afterDot != attribute {
+			continue
+		}
+
+		matched := true
+		for j, part := range parts[1:] {
+			dot, next := i+2*j+1, i+2*j+2
+			if next >= len(tokens) || !tokens[dot].isPunctuation(".") || tokens[next].kind != tokenIdentifier || tokens[next].text != part {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return token.line, true
+		}
+	}
+	return 0, false
+}
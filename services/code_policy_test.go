@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestCodePolicyCheck(t *testing.T) {
+	config := CodePolicyConfig{
+		MaxCodeBytes: 200,
+		ForbiddenModules: map[enums.CodingLanguage][]string{
+			enums.CodingLanguagePython:     defaultPythonForbiddenModules,
+			enums.CodingLanguageJavaScript: defaultJavaScriptForbiddenModules,
+			enums.CodingLanguageTypeScript: defaultJavaScriptForbiddenModules,
+		},
+		BannedBuiltins: map[enums.CodingLanguage][]string{
+			enums.CodingLanguagePython:     defaultPythonBannedBuiltins,
+			enums.CodingLanguageJavaScript: defaultJavaScriptBannedBuiltins,
+			enums.CodingLanguageTypeScript: defaultJavaScriptBannedBuiltins,
+		},
+	}
+	long := "x = 1\n"
+	for len(long) <= 200 {
+		long += long
+	}
+
+	tests := []struct {
+		name     string
+		language enums.CodingLanguage
+		code     string
+		banned   []string
+		want     string
+	}{
+		{name: "allowed", language: enums.CodingLanguagePython, code: "import collections\ndef solve(x):\n    return sorted(x)"},
+		{name: "too long", language: enums.CodingLanguagePython, code: long, want: "code is 384 bytes, more than the limit of 200"},
+		{name: "too long unscanned", language: enums.CodingLanguageGo, code: long, want: "code is 384 bytes, more than the limit of 200"},
+		{name: "unscanned language", language: enums.CodingLanguageGo, code: `import "os"`},
+		{name: "forbidden module", language: enums.CodingLanguagePython, code: "x = 1\nimport os", want: "line 2: module os is not allowed"},
+		{name: "forbidden submodule", language: enums.CodingLanguagePython, code: "from os.path import join", want: "line 1: module os.path is not allowed"},
+		{name: "module with a forbidden prefix", language: enums.CodingLanguagePython, code: "import osmium"},
+		{name: "module named in a comment", language: enums.CodingLanguagePython, code: "# import os\nx = 'import os'"},
+		{name: "forbidden require", language: enums.CodingLanguageJavaScript, code: `const cp = require("node:child_process");`, want: "line 1: module child_process is not allowed"},
+		{name: "computed require", language: enums.CodingLanguageJavaScript, code: "const m = require(name);", want: "line 1: modules must be loaded by a literal name"},
+		{name: "typescript import", language: enums.CodingLanguageTypeScript, code: `import * as net from "net";`, want: "line 1: module net is not allowed"},
+		{name: "banned built-in", language: enums.CodingLanguagePython, code: "def solve(x):\n    return eval(x)", want: "line 2: eval is not allowed"},
+		{name: "banned dotted built-in", language: enums.CodingLanguageJavaScript, code: "process.binding('fs')", want: "line 1: process.binding is not allowed"},
+		{name: "attribute of a banned name", language: enums.CodingLanguagePython, code: "ast.literal_eval(x)\nself.eval = 1"},
+		{name: "question ban", language: enums.CodingLanguagePython, code: "x.sort()\nreturn sorted(x)", banned: []string{"sorted"}, want: "line 2: sorted is not allowed in this question"},
+		{name: "question ban on an attribute", language: enums.CodingLanguageJavaScript, code: "const sort = 1;\nxs.sort();", banned: []string{".sort"}, want: "line 2: .sort is not allowed in this question"},
+		{name: "question ban in a string", language: enums.CodingLanguagePython, code: "print('sorted')", banned: []string{"sorted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := GetLanguageDriver(string(tt.language))
+			if err != nil {
+				t.Fatal(err)
+			}
+			violation := NewCodePolicy(config).Check(driver, tt.code, models.TechnicalQuestion{BannedBuiltins: tt.banned})
+			got := ""
+			if violation != nil {
+				got = violation.Error()
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultCodePolicyConfig(t *testing.T) {
+	t.Setenv("POLICY_MAX_CODE_BYTES", "-1")
+	t.Setenv("POLICY_FORBIDDEN_MODULES_PYTHON", " os, ,socket ")
+	t.Setenv("POLICY_BANNED_BUILTINS_JS", "")
+
+	config := DefaultCodePolicyConfig()
+	if config.MaxCodeBytes != 64*1024 {
+		t.Errorf("MaxCodeBytes = %d, want the default for an invalid limit", config.MaxCodeBytes)
+	}
+	if modules := config.ForbiddenModules[enums.CodingLanguagePython]; len(modules) != 2 || modules[0] != "os" || modules[1] != "socket" {
+		t.Errorf("Python forbidden modules = %q, want [os socket]", modules)
+	}
+	if builtins := config.BannedBuiltins[enums.CodingLanguageTypeScript]; len(builtins) != 0 {
+		t.Errorf("TypeScript banned built-ins = %q, want none once the variable is empty", builtins)
+	}
+	if builtins := config.BannedBuiltins[enums.CodingLanguagePython]; len(builtins) != len(defaultPythonBannedBuiltins) {
+		t.Errorf("Python banned built-ins = %q, want the defaults when the variable is unset", builtins)
+	}
+}
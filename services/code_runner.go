@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// CodeRunner executes a single program and reports what it produced
+type CodeRunner interface {
+	Run(ctx context.Context, req RunRequest) (*RunResult, error)
+}
+
+// RunRequest describes a program to execute
+type RunRequest struct {
+	Language      string
+	Code          string
+	Stdin         string
+	TimeLimit     time.Duration // zero uses the runner's default
+	MemoryLimitMB int           // zero uses the runner's default
+}
+
+// RunResult holds the outcome of a program execution
+type RunResult struct {
+	Stdout        string
+	Stderr        string
+	Output        string // stdout and stderr interleaved
+	ExitCode      int
+	Signal        string
+	CompileFailed bool   // the program never ran because compilation failed
+	CompileOutput string // compiler diagnostics, for compiled languages
+
+	// Set by the runner from how the program was stopped, not from its output
+	TimedOut       bool  // killed for exceeding the time limit
+	MemoryExceeded bool  // failed after reaching the memory limit
+	PeakMemoryKB   int64 // peak resident memory, if the runner reports it
+}
+
+// Available code runner backends
+const (
+	CodeRunnerPiston = "piston"
+	CodeRunnerLocal  = "local"
+)
+
+// CodeRunnerConfig holds code runner configuration
+type CodeRunnerConfig struct {
+	Backend       string
+	PistonBaseURL string
+	PistonAPIKey  string
+	CPUTime       time.Duration
+	MemoryLimitMB int
+	WorkDir       string
+	ReadOnlyPaths []string // besides the system paths, host paths the local runner's sandbox can read
+}
+
+// DefaultCodeRunnerConfig returns a default code runner configuration from environment variables
+func DefaultCodeRunnerConfig() CodeRunnerConfig {
+	backend := os.Getenv("CODE_RUNNER")
+	if backend == "" {
+		backend = CodeRunnerPiston
+	}
+
+	pistonBaseURL := os.Getenv("PISTON_BASE_URL")
+	if pistonBaseURL == "" {
+		pistonBaseURL = "https://emkc.org/api/v2/piston/"
+	}
+
+	cpuSeconds, err := strconv.Atoi(os.Getenv("LOCAL_RUNNER_CPU_SECONDS"))
+	if err != nil || cpuSeconds <= 0 {
+		cpuSeconds = 5
+	}
+
+	memoryLimitMB, err := strconv.Atoi(os.Getenv("LOCAL_RUNNER_MEMORY_MB"))
+	if err != nil || memoryLimitMB <= 0 {
+		memoryLimitMB = 256
+	}
+
+	workDir := os.Getenv("LOCAL_RUNNER_WORK_DIR")
+	if workDir == "" {
+		workDir = os.TempDir()
+	}
+
+	var readOnlyPaths []string
+	for _, path := range filepath.SplitList(os.Getenv("LOCAL_RUNNER_READONLY_PATHS")) {
+		if path != "" {
+			readOnlyPaths = append(readOnlyPaths, path)
+		}
+	}
+
+	return CodeRunnerConfig{
+		Backend:       backend,
+		PistonBaseURL: pistonBaseURL,
+		PistonAPIKey:  os.Getenv("PISTON_API_KEY"),
+		CPUTime:       time.Duration(cpuSeconds) * time.Second,
+		MemoryLimitMB: memoryLimitMB,
+		WorkDir:       workDir,
+		ReadOnlyPaths: readOnlyPaths,
+	}
+}
+
+// NewCodeRunner creates the code runner selected by the configuration
+func NewCodeRunner(config CodeRunnerConfig) (CodeRunner, error) {
+	switch config.Backend {
+	case CodeRunnerPiston:
+		return NewPistonRunner(config.PistonBaseURL, config.PistonAPIKey), nil
+	case CodeRunnerLocal:
+		return NewLocalRunner(config.WorkDir, config.CPUTime, config.MemoryLimitMB, config.ReadOnlyPaths), nil
+	default:
+		return nil, fmt.Errorf("unknown code runner: %s. Allowed runners: %s, %s", config.Backend, CodeRunnerPiston, CodeRunnerLocal)
+	}
+}
@@ -0,0 +1,442 @@
+package services
+
+import "strings"
+
+// sourceTokenKind classifies the tokens the code policy looks at
+type sourceTokenKind int
+
+const (
+	tokenIdentifier  sourceTokenKind = iota
+	tokenString                      // text is the literal's contents, escapes left as written
+	tokenPunctuation                 // a single character
+	tokenNumber
+)
+
+// sourceToken is one token of a candidate's code. Comments are dropped, and the
+// expressions interpolated into f-strings and template literals are scanned as code.
+type sourceToken struct {
+	kind sourceTokenKind
+	text string
+	line int
+}
+
+// sourceImport is a module the code imports. Module is empty when the code
+// loads a module whose name is not a literal, e.g. require(name).
+type sourceImport struct {
+	module string
+	line   int
+}
+
+// sourceScan is what a scanner found in a candidate's code
+type sourceScan struct {
+	tokens  []sourceToken
+	imports []sourceImport
+}
+
+// sourceScanner is implemented by language drivers whose code the policy can inspect
+type sourceScanner interface {
+	scanSource(code string) sourceScan
+}
+
+// codeScanner walks source text one byte at a time, collecting tokens
+type codeScanner struct {
+	src    string
+	pos    int
+	line   int
+	tokens []sourceToken
+}
+
+// isPunctuation reports whether the token is the given punctuation character
+func (t sourceToken) isPunctuation(text string) bool {
+	return t.kind == tokenPunctuation && t.text == text
+}
+
+func (s *codeScanner) emit(kind sourceTokenKind, text string, line int) {
+	s.tokens = append(s.tokens, sourceToken{kind: kind, text: text, line: line})
+}
+
+// word consumes identifier characters; bytes of multi-byte characters count as identifier characters
+func (s *codeScanner) word() string {
+	start := s.pos
+	for s.pos < len(s.src) && isSourceIdentifierByte(s.src[s.pos]) {
+		s.pos++
+	}
+	return s.src[start:s.pos]
+}
+
+// skipLine consumes everything up to the end of the line
+func (s *codeScanner) skipLine() {
+	for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+		s.pos++
+	}
+}
+
+// interpolate scans an expression embedded in a string literal as code
+func (s *codeScanner) interpolate(expression string, line int, scan func(string, int) []sourceToken) {
+	s.tokens = append(s.tokens, scan(expression, line)...)
+}
+
+func isSourceIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isSourceIdentifierByte(c byte) bool {
+	return isSourceIdentifierStart(c) || ('0' <= c && c <= '9')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scanPython tokenizes Python code starting at the given line
+func scanPython(code string, line int) []sourceToken {
+	s := &codeScanner{src: code, line: line}
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '\n':
+			s.line++
+			s.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\\':
+			s.pos++
+		case c == '#':
+			s.skipLine()
+		case c == '\'' || c == '"':
+			s.pythonString(false)
+		case isDigit(c):
+			start := s.pos
+			for s.pos < len(s.src) && (isSourceIdentifierByte(s.src[s.pos]) || s.src[s.pos] == '.') {
+				s.pos++
+			}
+			s.emit(tokenNumber, s.src[start:s.pos], s.line)
+		case isSourceIdentifierStart(c):
+			line := s.line
+			word := s.word()
+			prefix := strings.ToLower(word)
+			if s.pos < len(s.src) && (s.src[s.pos] == '\'' || s.src[s.pos] == '"') && len(prefix) <= 2 && strings.Trim(prefix, "rbuf") == "" {
+				s.pythonString(strings.Contains(prefix, "f"))
+				continue
+			}
+			s.emit(tokenIdentifier, word, line)
+		default:
+			s.emit(tokenPunctuation, string(c), s.line)
+			s.pos++
+		}
+	}
+	return s.tokens
+}
+
+// pythonString consumes a string literal, scanning the replacement fields of f-strings as code
+func (s *codeScanner) pythonString(formatted bool) {
+	line := s.line
+	quote := s.src[s.pos : s.pos+1]
+	if strings.HasPrefix(s.src[s.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	s.pos += len(quote)
+
+	start := s.pos
+	for s.pos < len(s.src) && !strings.HasPrefix(s.src[s.pos:], quote) {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos++
+		case '\n':
+			if len(quote) == 1 {
+				s.emit(tokenString, s.src[start:s.pos], line)
+				return
+			}
+			s.line++
+		}
+		s.pos++
+	}
+	end := s.pos
+	if end > len(s.src) {
+		end = len(s.src)
+	}
+	s.pos = end + len(quote)
+	contents := s.src[start:end]
+	s.emit(tokenString, contents, line)
+
+	if formatted {
+		for _, field := range replacementFields(contents, "{") {
+			s.interpolate(field, line, scanPython)
+		}
+	}
+}
+
+// scanJavaScript tokenizes JavaScript or TypeScript code starting at the given line
+func scanJavaScript(code string, line int) []sourceToken {
+	s := &codeScanner{src: code, line: line}
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '\n':
+			s.line++
+			s.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			s.pos++
+		case strings.HasPrefix(s.src[s.pos:], "//"):
+			s.skipLine()
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			end := strings.Index(s.src[s.pos+2:], "*/")
+			if end < 0 {
+				end = len(s.src) - s.pos - 2
+			}
+			s.line += strings.Count(s.src[s.pos:s.pos+2+end], "\n")
+			s.pos += end + 4
+		case c == '/' && s.regexAllowed():
+			s.skipRegex()
+		case c == '\'' || c == '"':
+			s.javaScriptString(c)
+		case c == '`':
+			s.templateLiteral()
+		case isDigit(c):
+			start := s.pos
+			for s.pos < len(s.src) && (isSourceIdentifierByte(s.src[s.pos]) || s.src[s.pos] == '.') {
+				s.pos++
+			}
+			s.emit(tokenNumber, s.src[start:s.pos], s.line)
+		case isSourceIdentifierStart(c):
+			line := s.line
+			s.emit(tokenIdentifier, s.word(), line)
+		default:
+			s.emit(tokenPunctuation, string(c), s.line)
+			s.pos++
+		}
+	}
+	if s.pos > len(s.src) {
+		s.pos = len(s.src)
+	}
+	return s.tokens
+}
+
+// javaScriptRegexKeywords can be followed by a regular expression literal
+var javaScriptRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true, "of": true,
+	"instanceof": true, "new": true, "delete": true, "void": true, "throw": true, "yield": true, "await": true,
+}
+
+// regexAllowed reports whether a slash starts a regular expression rather than a division
+func (s *codeScanner) regexAllowed() bool {
+	if len(s.tokens) == 0 {
+		return true
+	}
+	previous := s.tokens[len(s.tokens)-1]
+	switch previous.kind {
+	case tokenPunctuation:
+		return previous.text != ")" && previous.text != "]" && previous.text != "}"
+	case tokenIdentifier:
+		return javaScriptRegexKeywords[previous.text]
+	default:
+		return false
+	}
+}
+
+// skipRegex consumes a regular expression literal and its flags
+func (s *codeScanner) skipRegex() {
+	inClass := false
+	for s.pos++; s.pos < len(s.src) && s.src[s.pos] != '\n'; s.pos++ {
+		switch c := s.src[s.pos]; {
+		case c == '\\':
+			s.pos++
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			s.pos++
+			s.word()
+			return
+		}
+	}
+}
+
+// javaScriptString consumes a single or double quoted string literal
+func (s *codeScanner) javaScriptString(quote byte) {
+	line := s.line
+	start := s.pos + 1
+	for s.pos = start; s.pos < len(s.src) && s.src[s.pos] != quote && s.src[s.pos] != '\n'; s.pos++ {
+		if s.src[s.pos] == '\\' {
+			s.pos++
+		}
+	}
+	end := s.pos
+	if end > len(s.src) {
+		end = len(s.src)
+	}
+	s.emit(tokenString, s.src[start:end], line)
+	s.pos = end
+	if s.pos < len(s.src) && s.src[s.pos] == quote {
+		s.pos++
+	}
+}
+
+// templateLiteral consumes a template literal, scanning its substitutions as code
+func (s *codeScanner) templateLiteral() {
+	line := s.line
+	start := s.pos + 1
+	for s.pos = start; s.pos < len(s.src) && s.src[s.pos] != '`'; s.pos++ {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos++
+		case '\n':
+			s.line++
+		case '$':
+			if s.pos+1 < len(s.src) && s.src[s.pos+1] == '{' {
+				length := matchingBrace(s.src, s.pos+1)
+				s.line += strings.Count(s.src[s.pos:s.pos+1+length], "\n")
+				s.pos += length
+			}
+		}
+	}
+	end := s.pos
+	if end > len(s.src) {
+		end = len(s.src)
+	}
+	contents := s.src[start:end]
+	s.emit(tokenString, contents, line)
+	for _, substitution := range replacementFields(contents, "${") {
+		s.interpolate(substitution, line, scanJavaScript)
+	}
+	s.pos = end + 1
+}
+
+// replacementFields returns the expressions inside a string literal's
+// interpolations, each opened by open and closed by the matching brace.
+// Doubled braces in f-strings are literal braces.
+func replacementFields(contents string, open string) []string {
+	var fields []string
+	for i := 0; i < len(contents); i++ {
+		if !strings.HasPrefix(contents[i:], open) {
+			continue
+		}
+		if open == "{" && strings.HasPrefix(contents[i:], "{{") {
+			i++
+			continue
+		}
+		brace := i + len(open) - 1
+		length := matchingBrace(contents, brace)
+		fields = append(fields, contents[brace+1:brace+length])
+		i = brace + length
+	}
+	return fields
+}
+
+// matchingBrace returns the offset from the brace at start to the brace that
+// closes it, or to the end of text if it is never closed
+func matchingBrace(text string, start int) int {
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i - start
+			}
+		}
+	}
+	return len(text) - start
+}
+
+// pythonImports finds the modules imported by import and from ... import statements
+func pythonImports(tokens []sourceToken) []sourceImport {
+	var imports []sourceImport
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.kind != tokenIdentifier || (i > 0 && tokens[i-1].isPunctuation(".")) {
+			continue
+		}
+
+		switch token.text {
+		case "from":
+			// from a.b import c imports a.b; relative imports stay inside the program
+			module, next := dottedName(tokens, i+1)
+			if module != "" && next < len(tokens) && tokens[next].text == "import" {
+				imports = append(imports, sourceImport{module: module, line: token.line})
+				i = next
+			}
+
+		case "import":
+			// import a.b as c, d
+			for next := i + 1; ; {
+				module, after := dottedName(tokens, next)
+				if module == "" {
+					break
+				}
+				imports = append(imports, sourceImport{module: module, line: token.line})
+				if after+1 < len(tokens) && tokens[after].text == "as" {
+					after += 2
+				}
+				if after >= len(tokens) || !tokens[after].isPunctuation(",") {
+					break
+				}
+				next = after + 1
+			}
+		}
+	}
+	return imports
+}
+
+// dottedName reads a name like a.b.c starting at tokens[start], returning it and the index after it
+func dottedName(tokens []sourceToken, start int) (string, int) {
+	var parts []string
+	i := start
+	for i < len(tokens) && tokens[i].kind == tokenIdentifier {
+		parts = append(parts, tokens[i].text)
+		i++
+		if i+1 >= len(tokens) || !tokens[i].isPunctuation(".") || tokens[i+1].kind != tokenIdentifier {
+			break
+		}
+		i++
+	}
+	return strings.Join(parts, "."), i
+}
+
+// javaScriptImports finds the modules loaded by require(), import() and
+// import/export ... from statements, with node: prefixes removed
+func javaScriptImports(tokens []sourceToken) []sourceImport {
+	var imports []sourceImport
+	add := func(module string, line int) {
+		imports = append(imports, sourceImport{module: strings.TrimPrefix(module, "node:"), line: line})
+	}
+
+	for i, token := range tokens {
+		if token.kind != tokenIdentifier {
+			continue
+		}
+		next := func(offset int) sourceToken {
+			if i+offset < len(tokens) {
+				return tokens[i+offset]
+			}
+			return sourceToken{}
+		}
+
+		switch token.text {
+		case "require", "import":
+			if token.text == "import" && next(1).kind == tokenString {
+				add(next(1).text, token.line) // import 'module'
+				continue
+			}
+			if !next(1).isPunctuation("(") {
+				// Passing require around would let it load modules by computed names
+				if token.text == "require" && (i == 0 || !tokens[i-1].isPunctuation(".")) {
+					add("", token.line)
+				}
+				continue
+			}
+			if next(2).kind == tokenString && next(3).isPunctuation(")") {
+				add(next(2).text, token.line)
+			} else {
+				add("", token.line)
+			}
+
+		case "from":
+			if next(1).kind == tokenString && (i == 0 || !tokens[i-1].isPunctuation(".")) {
+				add(next(1).text, token.line)
+			}
+		}
+	}
+	return imports
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/repositories"
+	"stormhacks-be/types/enums"
+)
+
+// Complexity is estimated from the fastest of a few runs at each input size.
+// Sizes stop growing at the first one that fails or runs past the time limit,
+// or whose inputs are too large for the language's harness to embed.
+var complexityInputSizes = []int{100, 300, 1000, 3000, 10000, 30000, 100000}
+
+const (
+	complexityRunsPerSize     = 3
+	complexityMinSamples      = 3
+	complexityCaseTimeLimitMs = 2000 // per run, when the question sets no time limit
+)
+
+// complexityClass is a candidate growth rate fitted against measured timings
+type complexityClass struct {
+	bigO   string
+	growth func(n float64) float64
+}
+
+// complexityClasses is ordered from slowest to fastest growing, so simpler classes win close fits
+var complexityClasses = []complexityClass{
+	{"O(1)", func(n float64) float64 { return 1 }},
+	{"O(log n)", func(n float64) float64 { return math.Log2(n) }},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log2(n) }},
+	{"O(n^2)", func(n float64) float64 { return n * n }},
+	{"O(n^3)", func(n float64) float64 { return n * n * n }},
+}
+
+const (
+	// complexityFitTolerance lets a simpler class win if its error is within this factor of the best fit
+	complexityFitTolerance = 1.5
+
+	// complexityNoiseFloorMs is the shortest time treated as a real measurement
+	complexityNoiseFloorMs = 0.05
+)
+
+// inlineInputLimiter is implemented by drivers whose harness can only embed
+// inputs up to a size, as measured by inputSize
+type inlineInputLimiter interface {
+	MaxInlineInputSize() int
+}
+
+// measureSubmissionComplexity estimates the complexity of an accepted
+// submission and attaches it to the recorded submission. It runs after the
+// verdict has been returned, so failures are only logged.
+func measureSubmissionComplexity(submission *models.Submission, driver LanguageDriver, question models.TechnicalQuestion, codeRunner CodeRunner, interviewRepo *repositories.InterviewRepository) {
+	estimate, err := estimateComplexity(codeRunner, driver, submission.Code, question)
+	if err != nil {
+		log.Printf("Warning: Failed to estimate complexity for question %s: %v", submission.QuestionID, err)
+		return
+	}
+	if err := interviewRepo.SetSubmissionComplexity(submission.ID, estimate); err != nil {
+		log.Printf("Warning: Failed to record complexity for submission %s: %v", submission.ID.Hex(), err)
+	}
+}
+
+// estimateComplexity times code on generated inputs of growing size and fits
+// the timings against common complexity classes
+func estimateComplexity(codeRunner CodeRunner, driver LanguageDriver, code string, question models.TechnicalQuestion) (*models.ComplexityEstimate, error) {
+	if question.Generator == nil {
+		return nil, fmt.Errorf("question has no input generator")
+	}
+	generator, err := newInputGenerator(question.Generator.Arguments, time.Now().UnixNano())
+	if err != nil {
+		return nil, fmt.Errorf("invalid input generator: %w", err)
+	}
+
+	// Every run is held to a time limit so a slow solution cannot stall the estimate
+	timeLimitMs := question.LimitsFor(driver.Language()).TimeLimitMs
+	if timeLimitMs <= 0 {
+		timeLimitMs = complexityCaseTimeLimitMs
+	}
+	limits := models.ResourceLimits{TimeLimitMs: timeLimitMs}
+	if question.Limits != nil {
+		limits.MemoryLimitMB = question.Limits.MemoryLimitMB
+	}
+	question.Limits = &limits
+	question.LanguageLimits = nil
+
+	maxInputSize := 0
+	if limiter, ok := driver.(inlineInputLimiter); ok {
+		maxInputSize = limiter.MaxInlineInputSize()
+	}
+
+	estimate := &models.ComplexityEstimate{}
+	for _, n := range complexityInputSizes {
+		testCases := make([]models.TestCase, complexityRunsPerSize)
+		tooLarge := false
+		for i := range testCases {
+			input, err := generator.GenerateSized(n)
+			if err != nil {
+				return nil, err
+			}
+			if size, _ := inputSize(input); maxInputSize > 0 && size > maxInputSize {
+				tooLarge = true
+			}
+			testCases[i] = models.TestCase{Input: input}
+		}
+		if tooLarge {
+			estimate.Note = fmt.Sprintf("sizes from n=%d were skipped: their inputs are too large to embed in a %s program", n, driver.Language())
+			break
+		}
+
+		caseRuns, _, err := runTestCasesBatched(codeRunner, driver, code, question, testCases)
+		if err != nil {
+			return nil, err
+		}
+
+		fastest := math.Inf(1)
+		var failure enums.Verdict
+		for _, caseRun := range caseRuns {
+			if _, _, verdict := judgeCaseRun(caseRun); verdict != "" {
+				failure = verdict
+				break
+			}
+			fastest = math.Min(fastest, caseRun.Output.Time)
+		}
+		if failure != "" {
+			estimate.Note = fmt.Sprintf("stopped at n=%d: %s", n, failure)
+			break
+		}
+		estimate.Samples = append(estimate.Samples, models.TimingSample{N: n, TimeMs: fastest})
+	}
+
+	estimate.BigO = fitComplexity(estimate.Samples)
+	return estimate, nil
+}
+
+// fitComplexity picks the complexity class whose growth best explains the
+// timings, fitting time = a + b*growth(n) by least squares for each class
+func fitComplexity(samples []models.TimingSample) string {
+	if len(samples) < complexityMinSamples {
+		return ""
+	}
+
+	fitErrors := make([]float64, len(complexityClasses))
+	bestError := math.Inf(1)
+	for i, class := range complexityClasses {
+		fitErrors[i] = fitError(samples, class.growth)
+		bestError = math.Min(bestError, fitErrors[i])
+	}
+
+	for i, class := range complexityClasses {
+		if fitErrors[i] <= bestError*complexityFitTolerance {
+			return class.bigO
+		}
+	}
+	return ""
+}
+
+// fitError fits time = a + b*growth(n) by weighted least squares, with b kept
+// non-negative, and returns the sum of squared relative residuals. Weighting
+// by relative error stops the largest inputs from deciding the fit alone.
+func fitError(samples []models.TimingSample, growth func(n float64) float64) float64 {
+	var sumW, sumWX, sumWY, sumWXX, sumWXY float64
+	for _, sample := range samples {
+		x, y := growth(float64(sample.N)), measuredTime(sample)
+		w := 1 / (y * y)
+		sumW += w
+		sumWX += w * x
+		sumWY += w * y
+		sumWXX += w * x * x
+		sumWXY += w * x * y
+	}
+
+	var slope float64
+	if variance := sumW*sumWXX - sumWX*sumWX; variance > 0 {
+		slope = math.Max(0, (sumW*sumWXY-sumWX*sumWY)/variance)
+	}
+	intercept := (sumWY - slope*sumWX) / sumW
+
+	var residuals float64
+	for _, sample := range samples {
+		y := measuredTime(sample)
+		residual := (y - (intercept + slope*growth(float64(sample.N)))) / y
+		residuals += residual * residual
+	}
+	return residuals
+}
+
+// measuredTime is a sample's time, raised to the timer noise floor so differences
+// between near-instant runs are not mistaken for growth
+func measuredTime(sample models.TimingSample) float64 {
+	return math.Max(sample.TimeMs, complexityNoiseFloorMs)
+}
+
+// describeComplexity writes a complexity estimate out for a feedback prompt
+func describeComplexity(estimate *models.ComplexityEstimate) string {
+	if estimate == nil || len(estimate.Samples) == 0 {
+		return "Not measured"
+	}
+
+	timings := make([]string, len(estimate.Samples))
+	for i, sample := range estimate.Samples {
+		timings[i] = fmt.Sprintf("n=%d: %.3f ms", sample.N, sample.TimeMs)
+	}
+
+	bigO := estimate.BigO
+	if bigO == "" {
+		bigO = "undetermined"
+	}
+	if estimate.Note != "" {
+		return fmt.Sprintf("%s (measured %s; %s)", bigO, strings.Join(timings, ", "), estimate.Note)
+	}
+	return fmt.Sprintf("%s (measured %s)", bigO, strings.Join(timings, ", "))
+}
+
+// latestComplexity returns the complexity measured for the most recent accepted
+// submission in a question's history, oldest first
+func latestComplexity(history []models.Submission) *models.ComplexityEstimate {
+	for i := len(history) - 1; i >= 0; i-- {
+		submission := history[i]
+		if submission.Mode == enums.ExecutionModeSubmit && submission.Verdict == enums.VerdictAccepted && submission.Complexity != nil {
+			return submission.Complexity
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestFitComplexity(t *testing.T) {
+	// noise alternately slows and speeds up runs by a few percent
+	noise := []float64{1.04, 0.97, 1.02, 0.96, 1.03, 0.98, 1.01}
+
+	tests := []struct {
+		name  string
+		sizes []int
+		time  func(n float64) float64
+		want  string
+	}{
+		{name: "constant", sizes: complexityInputSizes, time: func(n float64) float64 { return 0.4 }, want: "O(1)"},
+		{name: "below the timer's resolution", sizes: complexityInputSizes, time: func(n float64) float64 { return 0.001 * math.Log2(n) }, want: "O(1)"},
+		{name: "logarithmic", sizes: complexityInputSizes, time: func(n float64) float64 { return 0.2 + 0.3*math.Log2(n) }, want: "O(log n)"},
+		{name: "linear", sizes: complexityInputSizes, time: func(n float64) float64 { return 0.3 + 0.002*n }, want: "O(n)"},
+		{name: "linearithmic", sizes: complexityInputSizes, time: func(n float64) float64 { return 0.3 + 0.0005*n*math.Log2(n) }, want: "O(n log n)"},
+		{name: "quadratic", sizes: complexityInputSizes[:5], time: func(n float64) float64 { return 0.3 + 0.00001*n*n }, want: "O(n^2)"},
+		{name: "cubic", sizes: complexityInputSizes[:4], time: func(n float64) float64 { return 0.3 + 1e-7*n*n*n }, want: "O(n^3)"},
+		{name: "too few sizes", sizes: complexityInputSizes[:2], time: func(n float64) float64 { return 0.002 * n }, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := make([]models.TimingSample, len(tt.sizes))
+			for i, n := range tt.sizes {
+				samples[i] = models.TimingSample{N: n, TimeMs: tt.time(float64(n)) * noise[i]}
+			}
+			if got := fitComplexity(samples); got != tt.want {
+				t.Errorf("fitComplexity(%v) = %q, want %q", samples, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeComplexity(t *testing.T) {
+	samples := []models.TimingSample{{N: 100, TimeMs: 0.5}, {N: 1000, TimeMs: 4.25}}
+	tests := []struct {
+		name     string
+		estimate *models.ComplexityEstimate
+		want     string
+	}{
+		{name: "not measured", want: "Not measured"},
+		{name: "no samples", estimate: &models.ComplexityEstimate{BigO: "O(n)"}, want: "Not measured"},
+		{name: "fitted", estimate: &models.ComplexityEstimate{BigO: "O(n)", Samples: samples}, want: "O(n) (measured n=100: 0.500 ms, n=1000: 4.250 ms)"},
+		{
+			name:     "stopped early",
+			estimate: &models.ComplexityEstimate{Samples: samples, Note: "stopped at n=1000"},
+			want:     "undetermined (measured n=100: 0.500 ms, n=1000: 4.250 ms; stopped at n=1000)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeComplexity(tt.estimate); got != tt.want {
+				t.Errorf("describeComplexity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestComplexity(t *testing.T) {
+	older := &models.ComplexityEstimate{BigO: "O(n^2)"}
+	newer := &models.ComplexityEstimate{BigO: "O(n)"}
+	accepted := func(complexity *models.ComplexityEstimate) models.Submission {
+		return models.Submission{Mode: enums.ExecutionModeSubmit, Verdict: enums.VerdictAccepted, Complexity: complexity}
+	}
+
+	tests := []struct {
+		name    string
+		history []models.Submission
+		want    *models.ComplexityEstimate
+	}{
+		{name: "no submissions"},
+		{name: "latest accepted", history: []models.Submission{accepted(older), accepted(newer)}, want: newer},
+		{name: "not yet measured", history: []models.Submission{accepted(older), accepted(nil)}, want: older},
+		{
+			name:    "runs do not count",
+			history: []models.Submission{accepted(older), {Mode: enums.ExecutionModeRun, Verdict: enums.VerdictAccepted, Complexity: newer}},
+			want:    older,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latestComplexity(tt.history); got != tt.want {
+				t.Errorf("latestComplexity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
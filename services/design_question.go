@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/responses"
+)
+
+// designIdentifier matches class and method names that are safe to splice into every harness language
+var designIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// formatDesignCalls parses a design question's test case input, the list of
+// calls and the list of their arguments, into calls with formatted arguments
+func formatDesignCalls(driver LanguageDriver, input string, class models.ClassSpec) ([]HarnessCall, error) {
+	operations, arguments, err := parseDesignInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if operations[0] != class.ClassName {
+		return nil, fmt.Errorf("first call must construct %s, got %s", class.ClassName, operations[0])
+	}
+
+	methods := make(map[string]models.MethodSpec, len(class.Methods))
+	for _, method := range class.Methods {
+		methods[method.Name] = method
+	}
+
+	calls := make([]HarnessCall, len(operations))
+	for i, operation := range operations {
+		parameters := class.Constructor
+		call := HarnessCall{Method: operation}
+		if i > 0 {
+			method, exists := methods[operation]
+			if !exists {
+				return nil, fmt.Errorf("call %d: %s has no method %s", i+1, class.ClassName, operation)
+			}
+			parameters = method.Parameters
+			call.Void = method.ReturnType == "" || method.ReturnType == "void"
+		}
+
+		call.Args, err = formatArguments(driver, arguments[i], parameters)
+		if err != nil {
+			return nil, fmt.Errorf("call %d (%s): %w", i+1, operation, err)
+		}
+		calls[i] = call
+	}
+	return calls, nil
+}
+
+// parseDesignInput splits a design test case input into the called names and their argument lists
+func parseDesignInput(input string) ([]string, [][]interface{}, error) {
+	parsed, ok := parseOutputValue("[" + input + "]").([]interface{})
+	if !ok || len(parsed) != 2 {
+		return nil, nil, fmt.Errorf("design test case input must be a list of calls and a list of arguments: %s", input)
+	}
+	names, namesOk := parsed[0].([]interface{})
+	argumentLists, argumentsOk := parsed[1].([]interface{})
+	if !namesOk || !argumentsOk || len(names) == 0 || len(names) != len(argumentLists) {
+		return nil, nil, fmt.Errorf("design test case input must list one argument list per call: %s", input)
+	}
+
+	operations := make([]string, len(names))
+	arguments := make([][]interface{}, len(names))
+	for i := range names {
+		name, ok := names[i].(string)
+		if !ok || !designIdentifier.MatchString(name) {
+			return nil, nil, fmt.Errorf("call %d: %v is not a method name", i+1, names[i])
+		}
+		args, ok := argumentLists[i].([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("call %d: arguments are not a list: %v", i+1, argumentLists[i])
+		}
+		operations[i] = name
+		arguments[i] = args
+	}
+	return operations, arguments, nil
+}
+
+// describeCallMismatch explains the first call of a design test case whose result was wrong
+func describeCallMismatch(result responses.TestCaseResult, call int) string {
+	operation := "?"
+	if operations, _, err := parseDesignInput(result.Input); err == nil && call <= len(operations) {
+		operation = operations[call-1]
+	}
+	return fmt.Sprintf("Call %d (%s): Expected '%s', got '%s'", call, operation,
+		callResult(result.Expected, call), callResult(result.Actual, call))
+}
+
+// callResult picks one call's result out of a serialized list of results
+func callResult(results string, call int) string {
+	list, ok := parseOutputValue(results).([]interface{})
+	if !ok || call > len(list) {
+		return "(missing)"
+	}
+	encoded, err := json.Marshal(list[call-1])
+	if err != nil {
+		return fmt.Sprint(list[call-1])
+	}
+	return string(encoded)
+}
+
+// exportedName capitalizes a method name the way Go requires for it to be callable from the harness
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestFormatDesignCalls(t *testing.T) {
+	class := models.ClassSpec{
+		ClassName:   "LRUCache",
+		Constructor: []models.Parameter{{Name: "capacity", Type: "int"}},
+		Methods: []models.MethodSpec{
+			{Name: "put", Parameters: []models.Parameter{{Name: "key", Type: "int"}, {Name: "value", Type: "int"}}, ReturnType: "void"},
+			{Name: "get", Parameters: []models.Parameter{{Name: "key", Type: "int"}}, ReturnType: "int"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		language enums.CodingLanguage
+		input    string
+		want     []HarnessCall
+		wantErr  bool
+	}{
+		{
+			name:     "python calls",
+			language: enums.CodingLanguagePython,
+			input:    `["LRUCache", "put", "get"], [[2], [1, 1], [1]]`,
+			want: []HarnessCall{
+				{Method: "LRUCache", Args: []string{"2"}},
+				{Method: "put", Args: []string{"1", "1"}, Void: true},
+				{Method: "get", Args: []string{"1"}},
+			},
+		},
+		{
+			name:     "go typed arguments",
+			language: enums.CodingLanguageGo,
+			input:    `["LRUCache", "get"], [[2], [7]]`,
+			want:     []HarnessCall{{Method: "LRUCache", Args: []string{"2"}}, {Method: "get", Args: []string{"7"}}},
+		},
+		{name: "first call is not the constructor", language: enums.CodingLanguagePython, input: `["get"], [[1]]`, wantErr: true},
+		{name: "unknown method", language: enums.CodingLanguagePython, input: `["LRUCache", "evict"], [[2], []]`, wantErr: true},
+		{name: "wrong argument count", language: enums.CodingLanguageJava, input: `["LRUCache", "put"], [[2], [1]]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := GetLanguageDriver(string(tt.language))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := formatDesignCalls(driver, tt.input, class)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatDesignCalls() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formatDesignCalls() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDesignInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantOperations []string
+		wantErr        bool
+	}{
+		{name: "calls", input: `["Stack", "push", "pop"], [[], [1], []]`, wantOperations: []string{"Stack", "push", "pop"}},
+		{name: "not two lists", input: `["Stack"]`, wantErr: true},
+		{name: "no calls", input: `[], []`, wantErr: true},
+		{name: "one argument list short", input: `["Stack", "push"], [[]]`, wantErr: true},
+		{name: "name unsafe to splice", input: `["Stack", "push(); evil"], [[], []]`, wantErr: true},
+		{name: "arguments not a list", input: `["Stack", "push"], [[], 1]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operations, arguments, err := parseDesignInput(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDesignInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(operations, tt.wantOperations) {
+				t.Errorf("parseDesignInput() operations = %q, want %q", operations, tt.wantOperations)
+			}
+			if len(arguments) != len(tt.wantOperations) {
+				t.Errorf("parseDesignInput() returned %d argument lists, want %d", len(arguments), len(tt.wantOperations))
+			}
+		})
+	}
+}
+
+func TestCallResult(t *testing.T) {
+	tests := []struct {
+		results string
+		call    int
+		want    string
+	}{
+		{results: `[null, null, 1, [2, 3]]`, call: 3, want: "1"},
+		{results: `[null, null, 1, [2, 3]]`, call: 4, want: "[2,3]"},
+		{results: `[null, "a"]`, call: 2, want: `"a"`},
+		{results: `[null]`, call: 2, want: "(missing)"},
+		{results: "Error", call: 1, want: "(missing)"},
+	}
+	for _, tt := range tests {
+		if got := callResult(tt.results, tt.call); got != tt.want {
+			t.Errorf("callResult(%q, %d) = %q, want %q", tt.results, tt.call, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/repositories"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrSnapshotOutOfSync is returned for a delta that does not apply to the latest snapshot
+	ErrSnapshotOutOfSync = errors.New("delta is not based on the latest snapshot, send the whole code instead")
+
+	// ErrInvalidSnapshot is returned for a snapshot that cannot be recorded
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+)
+
+const (
+	// snapshotKeyframeInterval is how often a snapshot stores the whole code rather than a delta
+	snapshotKeyframeInterval = 50
+
+	// maxSnapshotCodeBytes bounds the code a snapshot can hold
+	maxSnapshotCodeBytes = 256 * 1024
+
+	// maxSnapshotAttempts bounds how often recording retries after losing a
+	// sequence number to a concurrent recording
+	maxSnapshotAttempts = 3
+)
+
+// editorSnapshotLocks serializes snapshot recording for each question of a
+// session, since each snapshot's delta and sequence number depend on the one before it
+var editorSnapshotLocks = &snapshotLocks{locks: make(map[snapshotKey]*snapshotLock)}
+
+// snapshotKey identifies the snapshots of one question in one session
+type snapshotKey struct {
+	sessionID  string
+	questionID string
+}
+
+// snapshotLocks hands out one lock per question being recorded, dropping each
+// lock once nobody holds or waits for it
+type snapshotLocks struct {
+	mu    sync.Mutex
+	locks map[snapshotKey]*snapshotLock
+}
+
+type snapshotLock struct {
+	mu      sync.Mutex
+	holders int // goroutines holding or waiting for mu
+}
+
+// lock locks a question's snapshots and returns the function that unlocks them
+func (l *snapshotLocks) lock(key snapshotKey) func() {
+	l.mu.Lock()
+	lock, exists := l.locks[key]
+	if !exists {
+		lock = &snapshotLock{}
+		l.locks[key] = lock
+	}
+	lock.holders++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		l.mu.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// RecordEditorSnapshot stores the candidate's code for a question as a delta
+// from their previous snapshot, or as a keyframe every snapshotKeyframeInterval snapshots
+func RecordEditorSnapshot(input requests.EditorSnapshotInput, interviewRepo *repositories.InterviewRepository) (*responses.EditorSnapshotResponse, error) {
+	if _, err := getSession(interviewRepo, input.SessionID); err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	unlock := editorSnapshotLocks.lock(snapshotKey{sessionID: input.SessionID, questionID: input.QuestionID})
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		response, err := recordEditorSnapshot(input, interviewRepo)
+		if mongo.IsDuplicateKeyError(err) && attempt < maxSnapshotAttempts {
+			// Another server recorded this sequence number first, so build on its snapshot
+			continue
+		}
+		return response, err
+	}
+}
+
+// recordEditorSnapshot stores a snapshot after the latest one recorded for its question
+func recordEditorSnapshot(input requests.EditorSnapshotInput, interviewRepo *repositories.InterviewRepository) (*responses.EditorSnapshotResponse, error) {
+	latestSequence, latestCode, err := latestEditorCode(interviewRepo, input.SessionID, input.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var code string
+	switch {
+	case input.Code != nil:
+		code = *input.Code
+	case input.Delta != nil:
+		if input.BaseSequence != latestSequence {
+			return nil, ErrSnapshotOutOfSync
+		}
+		code, err = applyDelta(latestCode, models.CodeDelta{Start: input.Delta.Start, Deleted: input.Delta.Deleted, Inserted: input.Delta.Inserted})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: either code or delta is required", ErrInvalidSnapshot)
+	}
+	if len(code) > maxSnapshotCodeBytes {
+		return nil, fmt.Errorf("%w: code is larger than %d bytes", ErrInvalidSnapshot, maxSnapshotCodeBytes)
+	}
+
+	response := &responses.EditorSnapshotResponse{
+		SessionID:  input.SessionID,
+		QuestionID: input.QuestionID,
+		Sequence:   latestSequence,
+	}
+	if code == latestCode {
+		response.Unchanged = true
+		response.RecordedAt = time.Now()
+		return response, nil
+	}
+
+	snapshot := &models.EditorSnapshot{
+		SessionID:  input.SessionID,
+		QuestionID: input.QuestionID,
+		Sequence:   latestSequence + 1,
+	}
+	if snapshot.Sequence%snapshotKeyframeInterval == 1 {
+		snapshot.Keyframe = true
+		snapshot.Code = code
+	} else {
+		delta := computeDelta(latestCode, code)
+		snapshot.Delta = &delta
+	}
+	if err := interviewRepo.CreateEditorSnapshot(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to record snapshot: %w", err)
+	}
+
+	response.Sequence = snapshot.Sequence
+	response.RecordedAt = snapshot.RecordedAt
+	return response, nil
+}
+
+// latestEditorCode rebuilds a question's latest code from its last keyframe,
+// returning its sequence number, which is zero before the first snapshot
+func latestEditorCode(interviewRepo *repositories.InterviewRepository, sessionID string, questionID string) (int, string, error) {
+	keyframe, err := interviewRepo.GetLatestEditorKeyframe(sessionID, questionID)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	fromSequence := 1
+	if keyframe != nil {
+		fromSequence = keyframe.Sequence
+	}
+
+	snapshots, err := interviewRepo.GetEditorSnapshots(sessionID, questionID, fromSequence, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get snapshots: %w", err)
+	}
+
+	code := ""
+	sequence := 0
+	for _, snapshot := range snapshots {
+		if code, err = applySnapshot(code, snapshot); err != nil {
+			return 0, "", err
+		}
+		sequence = snapshot.Sequence
+	}
+	return sequence, code, nil
+}
+
+// BuildReplay reconstructs a question's coding session: every edit, hint and
+// execution in time order, and the code as it stood at the given time, or at
+// the end if at is nil. Snapshots recorded after that time are not read.
+func BuildReplay(sessionID string, questionID string, at *time.Time, interviewRepo *repositories.InterviewRepository) (*responses.ReplayResponse, error) {
+	session, err := getSession(interviewRepo, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	snapshots, err := interviewRepo.GetEditorSnapshots(sessionID, questionID, 1, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	submissions, err := interviewRepo.GetSubmissionsBySession(sessionID, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission history: %w", err)
+	}
+
+	before := func(t time.Time) bool { return at == nil || !t.After(*at) }
+	replay := &responses.ReplayResponse{
+		SessionID:  sessionID,
+		QuestionID: questionID,
+		At:         at,
+		Events:     []responses.ReplayEvent{},
+	}
+
+	// Keyframes are sent as deltas too, so clients can rebuild the code from
+	// the edit events alone
+	for _, snapshot := range snapshots {
+		if !before(snapshot.RecordedAt) {
+			break
+		}
+		code, err := applySnapshot(replay.Code, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		delta := computeDelta(replay.Code, code)
+		replay.Events = append(replay.Events, responses.ReplayEvent{
+			Type:     enums.ReplayEventEdit,
+			Time:     snapshot.RecordedAt,
+			Sequence: snapshot.Sequence,
+			Delta:    &delta,
+		})
+		replay.Code = code
+	}
+
+	for _, usage := range session.HintUsages {
+		if usage.QuestionID == questionID && before(usage.RequestedAt) {
+			replay.Events = append(replay.Events, responses.ReplayEvent{Type: enums.ReplayEventHint, Time: usage.RequestedAt})
+		}
+	}
+
+	for _, submission := range submissions {
+		if !before(submission.CreatedAt) {
+			continue
+		}
+		replay.Events = append(replay.Events, responses.ReplayEvent{
+			Type: enums.ReplayEventExecution,
+			Time: submission.CreatedAt,
+			Execution: &responses.ReplayExecution{
+				SubmissionID: submission.ID.Hex(),
+				Mode:         submission.Mode,
+				Language:     submission.Language,
+				Verdict:      submission.Verdict,
+				Passed:       submission.Passed,
+				Total:        submission.Total,
+			},
+		})
+	}
+
+	sort.SliceStable(replay.Events, func(i, j int) bool {
+		return replay.Events[i].Time.Before(replay.Events[j].Time)
+	})
+	return replay, nil
+}
+
+// applySnapshot returns the code after a snapshot, given the code before it
+func applySnapshot(code string, snapshot models.EditorSnapshot) (string, error) {
+	if snapshot.Keyframe {
+		return snapshot.Code, nil
+	}
+	if snapshot.Delta == nil {
+		return "", fmt.Errorf("snapshot %d has neither code nor a delta", snapshot.Sequence)
+	}
+	updated, err := applyDelta(code, *snapshot.Delta)
+	if err != nil {
+		return "", fmt.Errorf("snapshot %d: %w", snapshot.Sequence, err)
+	}
+	return updated, nil
+}
+
+// computeDelta describes the change from previous to current as one
+// replacement, trimming the characters they share at both ends
+func computeDelta(previous, current string) models.CodeDelta {
+	before, after := []rune(previous), []rune(current)
+
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	return models.CodeDelta{
+		Start:    prefix,
+		Deleted:  len(before) - prefix - suffix,
+		Inserted: string(after[prefix : len(after)-suffix]),
+	}
+}
+
+// applyDelta replaces the characters a delta covers
+func applyDelta(code string, delta models.CodeDelta) (string, error) {
+	runes := []rune(code)
+	if delta.Start < 0 || delta.Deleted < 0 || delta.Start+delta.Deleted > len(runes) {
+		return "", fmt.Errorf("delta replaces characters %d to %d of a %d character snapshot", delta.Start, delta.Start+delta.Deleted, len(runes))
+	}
+	return string(runes[:delta.Start]) + delta.Inserted + string(runes[delta.Start+delta.Deleted:]), nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stormhacks-be/models"
+)
+
+func TestComputeDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     models.CodeDelta
+	}{
+		{name: "unchanged", previous: "abc", current: "abc", want: models.CodeDelta{Start: 3}},
+		{name: "from empty", previous: "", current: "abc", want: models.CodeDelta{Inserted: "abc"}},
+		{name: "to empty", previous: "abc", current: "", want: models.CodeDelta{Deleted: 3}},
+		{name: "append", previous: "abc", current: "abcd", want: models.CodeDelta{Start: 3, Inserted: "d"}},
+		{name: "prepend", previous: "abc", current: "xabc", want: models.CodeDelta{Inserted: "x"}},
+		{name: "replace in the middle", previous: "return a + b", current: "return a * b", want: models.CodeDelta{Start: 9, Deleted: 1, Inserted: "*"}},
+		{name: "delete in the middle", previous: "abcdef", current: "abef", want: models.CodeDelta{Start: 2, Deleted: 2}},
+		{name: "repeated characters", previous: "aaa", current: "aaaa", want: models.CodeDelta{Start: 3, Inserted: "a"}},
+		{name: "positions count characters", previous: "é = 1", current: "é = 2", want: models.CodeDelta{Start: 4, Deleted: 1, Inserted: "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeDelta(tt.previous, tt.current)
+			if got != tt.want {
+				t.Errorf("computeDelta() = %+v, want %+v", got, tt.want)
+			}
+
+			applied, err := applyDelta(tt.previous, got)
+			if err != nil {
+				t.Fatalf("applyDelta() error = %v", err)
+			}
+			if applied != tt.current {
+				t.Errorf("applyDelta(computeDelta()) = %q, want %q", applied, tt.current)
+			}
+		})
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		delta   models.CodeDelta
+		want    string
+		wantErr bool
+	}{
+		{name: "insert", code: "ac", delta: models.CodeDelta{Start: 1, Inserted: "b"}, want: "abc"},
+		{name: "replace to the end", code: "abc", delta: models.CodeDelta{Start: 1, Deleted: 2, Inserted: "x"}, want: "ax"},
+		{name: "multibyte characters", code: "日本語", delta: models.CodeDelta{Start: 1, Deleted: 1, Inserted: "x"}, want: "日x語"},
+		{name: "past the end", code: "abc", delta: models.CodeDelta{Start: 2, Deleted: 2}, wantErr: true},
+		{name: "negative start", code: "abc", delta: models.CodeDelta{Start: -1}, wantErr: true},
+		{name: "negative deletion", code: "abc", delta: models.CodeDelta{Start: 1, Deleted: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyDelta(tt.code, tt.delta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyDelta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("applyDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotLocks(t *testing.T) {
+	locks := &snapshotLocks{locks: make(map[snapshotKey]*snapshotLock)}
+	first := snapshotKey{sessionID: "s", questionID: "q1"}
+
+	unlockFirst := locks.lock(first)
+	// Another question of the same session is not held up
+	unlockOther := locks.lock(snapshotKey{sessionID: "s", questionID: "q2"})
+	unlockOther()
+
+	acquired, released := make(chan struct{}), make(chan struct{})
+	go func() {
+		unlock := locks.lock(first)
+		close(acquired)
+		unlock()
+		close(released)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the same question was locked twice")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockFirst()
+	<-released
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks left after every holder unlocked, want none", len(locks.locks))
+	}
+}
@@ -1,62 +1,190 @@
 package services
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"log"
+	"math"
 	"strings"
-	"time"
 
-	piston "github.com/milindmadhukar/go-piston"
+	"stormhacks-be/models"
 	"stormhacks-be/repositories"
 	"stormhacks-be/types/enums"
 	"stormhacks-be/types/requests"
 	"stormhacks-be/types/responses"
 )
 
-func ExecuteCode(input requests.ExecuteTechnicalInput, interviewRepo *repositories.InterviewRepository) (*responses.ExecuteTechnicalResponse, error) {
+// ExecuteCode executes code against a question's test cases and, when it is
+// run within a session, records it in the session's submission history. The
+// complexity of an accepted submission is measured afterwards, by a job handed
+// to scheduleFollowUp, and attached to the recorded submission.
+func ExecuteCode(input requests.ExecuteTechnicalInput, interviewRepo *repositories.InterviewRepository, codeRunner CodeRunner, codePolicy *CodePolicy, executionCache *ExecutionCache, scheduleFollowUp func(job func()) bool) (*responses.ExecuteTechnicalResponse, error) {
+	response, question, err := executeCode(input, interviewRepo, codeRunner, codePolicy, executionCache)
+	if err != nil || input.SessionID == "" {
+		return response, err
+	}
+
+	submission := newSubmission(input, response)
+	if err := interviewRepo.CreateSubmission(submission); err != nil {
+		log.Printf("Warning: Failed to record submission for session %s: %v", input.SessionID, err)
+		return response, nil
+	}
+
+	// Measure how an accepted submission scales, when the question can generate inputs
+	if response.Mode == enums.ExecutionModeSubmit && response.Verdict == enums.VerdictAccepted && question.Generator != nil && question.IsFunction() {
+		driver, _ := GetLanguageDriver(string(input.Language))
+		response.ComplexityPending = scheduleFollowUp(func() {
+			measureSubmissionComplexity(submission, driver, *question, codeRunner, interviewRepo)
+		})
+	}
+	return response, nil
+}
+
+// executeCode judges the code, also returning the question it was judged against
+// when it ran against the question's test cases
+func executeCode(input requests.ExecuteTechnicalInput, interviewRepo *repositories.InterviewRepository, codeRunner CodeRunner, codePolicy *CodePolicy, executionCache *ExecutionCache) (*responses.ExecuteTechnicalResponse, *models.TechnicalQuestion, error) {
 	// Validate language
-	if !enums.IsValidCodingLanguage(string(input.Language)) {
-		return nil, fmt.Errorf("invalid language: %s. Allowed languages: python, javascript", input.Language)
+	driver, err := GetLanguageDriver(string(input.Language))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mode := input.Mode
+	if mode == "" {
+		mode = enums.ExecutionModeRun
+	}
+
+	// Submitting records a verdict on the session, and any run in a session is
+	// kept in its history, so make sure the session exists before running anything
+	if mode == enums.ExecutionModeSubmit || input.SessionID != "" {
+		if _, err := getSession(interviewRepo, input.SessionID); err != nil {
+			return nil, nil, fmt.Errorf("failed to get session: %w", err)
+		}
 	}
 
 	// Get the technical question by ID
 	question, err := interviewRepo.GetTechnicalQuestionByID(input.QuestionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get question: %w", err)
+		return nil, nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	// Code that breaks the policy is refused before it reaches the runner
+	if violation := codePolicy.Check(driver, input.Code, question.Question); violation != nil {
+		response, err := policyViolationResponse(input, mode, question.Question, violation, interviewRepo)
+		return response, nil, err
+	}
+
+	// Stress mode judges random inputs instead of the stored test cases
+	if mode == enums.ExecutionModeStress {
+		response, err := executeStress(input, driver, question.Question, codeRunner)
+		return response, nil, err
+	}
+
+	// Run and submit results only depend on the code and the question, so
+	// unchanged code run again is answered from the cache
+	cacheKey := newExecutionCacheKey(input.Code, driver, mode, *question)
+	response, cached := executionCache.Get(cacheKey)
+	if cached {
+		response.Code = input.Code
+		response.Cached = true
+	} else {
+		var cacheable bool
+		response, cacheable, err = judgeTestCases(input, mode, driver, question.Question, codeRunner)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cacheable {
+			executionCache.Put(cacheKey, response)
+		}
+	}
+
+	// Record the final verdict of a submission
+	if mode == enums.ExecutionModeSubmit {
+		err := interviewRepo.AddTechnicalVerdict(input.SessionID, models.TechnicalVerdict{
+			QuestionID: input.QuestionID,
+			Language:   string(input.Language),
+			Verdict:    response.Verdict,
+			Passed:     response.Passed,
+			Total:      response.Total,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to record verdict: %w", err)
+		}
+	}
+
+	return response, &question.Question, nil
+}
+
+// judgeTestCases runs code against the question's test cases for the mode and
+// judges it, reporting whether the result may be cached. Results that depend on
+// more than the code, like runner failures and time limits, are not cacheable.
+func judgeTestCases(input requests.ExecuteTechnicalInput, mode enums.ExecutionMode, driver LanguageDriver, question models.TechnicalQuestion, codeRunner CodeRunner) (*responses.ExecuteTechnicalResponse, bool, error) {
+	// Run mode only sees the sample test cases; submit judges every case
+	testCases := question.SampleTestCases()
+	if mode == enums.ExecutionModeSubmit {
+		testCases = question.TestCases
+	}
+	if len(testCases) == 0 {
+		return nil, false, fmt.Errorf("question has no test cases to %s", mode)
+	}
+
+	comparator := NewOutputComparator(question, codeRunner)
+	limits := question.LimitsFor(driver.Language())
+
+	// Execute the sample cases in one harness program and any hidden cases in another
+	caseRuns, totalExecutionTime, err := runHiddenTestCasesApart(codeRunner, driver, input.Code, question, testCases)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cacheable := true
+	for _, caseRun := range caseRuns {
+		if caseRun.RunErr != nil {
+			cacheable = false
+		}
 	}
 
-	// Execute code for each test case
 	var allOutputs []string
 	var allErrors []string
-	var totalExecutionTime int64
+	var results []responses.TestCaseResult
 	success := true
+	passed := 0
 
-	for i, testCase := range question.Question.TestCases {
-		// Prepare the code with test case input
-		executionCode := prepareCodeWithTestCase(input.Code, testCase.Input, string(input.Language), question.Question.FunctionName)
-		
-		// Execute the code
-		startTime := time.Now()
-		output, err := executeCodeWithPiston(executionCode, string(input.Language))
-		executionTime := time.Since(startTime).Milliseconds()
-		totalExecutionTime += executionTime
-
+	for i, testCase := range testCases {
+		// Judge the test case
+		result, err := evaluateTestCase(testCase, caseRuns[i], comparator)
 		if err != nil {
-			allErrors = append(allErrors, fmt.Sprintf("Test case %d: %v", i+1, err))
+			return nil, false, fmt.Errorf("failed to compare output for test case %d: %w", i+1, err)
+		}
+
+		if result.Verdict == enums.VerdictAccepted {
+			passed++
+		} else {
 			success = false
+		}
+
+		// Hidden test cases only report their verdict
+		if testCase.Hidden {
+			results = append(results, redactHiddenResult(result))
+			if result.Verdict == enums.VerdictTimeLimitExceeded || result.Verdict == enums.VerdictMemoryLimitExceeded {
+				allErrors = append(allErrors, fmt.Sprintf("Test case %d: %s", i+1, limitExceededMessage(result.Verdict, limits)))
+			} else if result.Verdict != enums.VerdictAccepted {
+				allErrors = append(allErrors, fmt.Sprintf("Test case %d: hidden test case failed (%s)", i+1, result.Verdict))
+			}
 			continue
 		}
+		results = append(results, result)
 
-		// Clean and compare output
-		cleanedOutput := cleanOutput(output)
-		cleanedExpected := cleanOutput(testCase.ExpectedOutput)
-		
-		allOutputs = append(allOutputs, cleanedOutput)
-		
-		// Check if output matches expected
-		if !compareOutputs(cleanedOutput, cleanedExpected) {
-			allErrors = append(allErrors, fmt.Sprintf("Test case %d: Expected '%s', got '%s'", i+1, cleanedExpected, cleanedOutput))
-			success = false
+		switch result.Verdict {
+		case enums.VerdictAccepted:
+			allOutputs = append(allOutputs, result.Actual)
+		case enums.VerdictWrongAnswer:
+			allOutputs = append(allOutputs, result.Actual)
+			allErrors = append(allErrors, fmt.Sprintf("Test case %d: %s", i+1, wrongAnswerMessage(result)))
+		case enums.VerdictTimeLimitExceeded, enums.VerdictMemoryLimitExceeded:
+			allErrors = append(allErrors, fmt.Sprintf("Test case %d: %s", i+1, limitExceededMessage(result.Verdict, limits)))
+		default:
+			allErrors = append(allErrors, fmt.Sprintf("Test case %d: %s", i+1, result.Stderr))
 		}
 	}
 
@@ -72,123 +200,212 @@ func ExecuteCode(input requests.ExecuteTechnicalInput, interviewRepo *repositori
 		finalOutput = strings.Join(allOutputs, "\n")
 	}
 
+	verdict := overallVerdict(results)
+	for _, result := range results {
+		if result.Verdict == enums.VerdictTimeLimitExceeded {
+			cacheable = false
+		}
+	}
+
 	return &responses.ExecuteTechnicalResponse{
 		QuestionID:    input.QuestionID,
 		Code:         input.Code,
 		Language:     string(input.Language),
+		Mode:         mode,
 		Output:       finalOutput,
 		Error:        finalError,
 		ExecutionTime: totalExecutionTime,
 		Success:      success,
+		Verdict:      verdict,
+		Passed:       passed,
+		Total:        len(testCases),
+		Results:      results,
+	}, cacheable, nil
+}
+
+// policyViolationResponse reports code refused by the code policy, recording
+// the verdict on the session when it was submitted
+func policyViolationResponse(input requests.ExecuteTechnicalInput, mode enums.ExecutionMode, question models.TechnicalQuestion, violation *PolicyViolation, interviewRepo *repositories.InterviewRepository) (*responses.ExecuteTechnicalResponse, error) {
+	total := len(question.SampleTestCases())
+	if mode == enums.ExecutionModeSubmit {
+		total = len(question.TestCases)
+		err := interviewRepo.AddTechnicalVerdict(input.SessionID, models.TechnicalVerdict{
+			QuestionID: input.QuestionID,
+			Language:   string(input.Language),
+			Verdict:    enums.VerdictPolicyViolation,
+			Total:      total,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record verdict: %w", err)
+		}
+	}
+
+	return &responses.ExecuteTechnicalResponse{
+		QuestionID: input.QuestionID,
+		Code:       input.Code,
+		Language:   string(input.Language),
+		Mode:       mode,
+		Error:      "Policy Violation: " + violation.Error(),
+		Verdict:    enums.VerdictPolicyViolation,
+		Total:      total,
 	}, nil
 }
 
-// prepareCodeWithTestCase prepares code with test case input
-func prepareCodeWithTestCase(code, testInput, language, functionName string) string {
-	switch language {
-	case string(enums.CodingLanguagePython):
-		return fmt.Sprintf(`
-%s
-
-# Test with input: %s
-print(%s(%s))
-`, code, testInput, functionName, testInput)
-	case string(enums.CodingLanguageJavaScript):
-		return fmt.Sprintf(`
-%s
-
-// Test with input: %s
-console.log(%s(%s));
-`, code, testInput, functionName, testInput)
-	default:
-		return fmt.Sprintf(`
-%s
+// wrongAnswerMessage describes how a result differs from the expected output,
+// pointing at the first wrong call for design questions and the first wrong
+// line for stdin/stdout questions
+func wrongAnswerMessage(result responses.TestCaseResult) string {
+	if len(result.MismatchedCalls) > 0 {
+		return describeCallMismatch(result, result.MismatchedCalls[0])
+	}
+	if result.MismatchedLine > 0 {
+		return describeLineMismatch(result, result.MismatchedLine)
+	}
+	if result.Stderr != "" {
+		return fmt.Sprintf("Expected '%s', got '%s' (%s)", result.Expected, result.Actual, result.Stderr)
+	}
+	return fmt.Sprintf("Expected '%s', got '%s'", result.Expected, result.Actual)
+}
 
-# Test with input: %s
-print(%s(%s))
-`, code, testInput, functionName, testInput)
+// limitExceededMessage describes a time or memory limit verdict, including the limit when the question sets one
+func limitExceededMessage(verdict enums.Verdict, limits models.ResourceLimits) string {
+	if verdict == enums.VerdictTimeLimitExceeded {
+		if limits.TimeLimitMs > 0 {
+			return fmt.Sprintf("Time Limit Exceeded (limit %d ms)", limits.TimeLimitMs)
+		}
+		return "Time Limit Exceeded"
+	}
+	if limits.MemoryLimitMB > 0 {
+		return fmt.Sprintf("Memory Limit Exceeded (limit %d MB)", limits.MemoryLimitMB)
 	}
+	return "Memory Limit Exceeded"
 }
 
-// executeCodeWithPiston executes code using the piston client
-func executeCodeWithPiston(code, language string) (string, error) {
-	client := piston.CreateDefaultClient()
-	
-	// Map language to piston language code
-	languageCode := mapLanguageToPistonCode(language)
-	
-	result, err := client.Execute(languageCode, "", []piston.Code{
-		{Content: code},
-	})
-	if err != nil {
-		return "", err
+// redactHiddenResult strips everything but the verdict and runtime from a hidden test case's result.
+// Even compile errors are dropped, since compiled harnesses embed the hidden inputs in their source.
+func redactHiddenResult(result responses.TestCaseResult) responses.TestCaseResult {
+	return responses.TestCaseResult{
+		Runtime: result.Runtime,
+		Verdict: result.Verdict,
+		Hidden:  true,
 	}
-	
-	return result.GetOutput(), nil
 }
 
-// mapLanguageToPistonCode maps language names to piston language codes
-func mapLanguageToPistonCode(language string) string {
-	languageMap := map[string]string{
-		string(enums.CodingLanguagePython):     "python3",
-		string(enums.CodingLanguageJavaScript): "js",
+// evaluateTestCase turns the harness output of a single test case into a judged result
+func evaluateTestCase(testCase models.TestCase, caseRun batchCaseRun, comparator *OutputComparator) (responses.TestCaseResult, error) {
+	result := responses.TestCaseResult{
+		Input:    testCase.Input,
+		Expected: strings.TrimSpace(testCase.ExpectedOutput),
+		Logs:     caseRun.Output.Logs,
+		Runtime:  int64(math.Round(caseRun.Output.Time)),
 	}
-	
-	if code, exists := languageMap[strings.ToLower(language)]; exists {
-		return code
+
+	actual, stderr, verdict := judgeCaseRun(caseRun)
+	if verdict != "" {
+		result.Stderr = stderr
+		result.Verdict = verdict
+		return result, nil
+	}
+	result.Actual = actual
+
+	var correct bool
+	var err error
+	if comparator.design {
+		correct, result.MismatchedCalls, err = comparator.CompareCalls(testCase, result.Actual)
+	} else {
+		correct, err = comparator.Compare(testCase, result.Actual)
+	}
+	var checkerErr *CheckerFailedError
+	if errors.As(err, &checkerErr) {
+		// A checker that cannot judge the output rejects it, like any wrong answer
+		result.Stderr = checkerErr.Error()
+		result.Verdict = enums.VerdictWrongAnswer
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	if comparator.stdio && !correct {
+		result.MismatchedLine = comparator.MismatchedLine(testCase.ExpectedOutput, result.Actual)
 	}
-	return "python3" // default to Python
+
+	if correct {
+		result.Verdict = enums.VerdictAccepted
+	} else {
+		result.Verdict = enums.VerdictWrongAnswer
+	}
+	return result, nil
 }
 
-// cleanOutput cleans the output for comparison
-func cleanOutput(output string) string {
-	// Remove extra whitespace and newlines
-	cleaned := strings.TrimSpace(output)
-	// Remove common prefixes like "Result:" or "Output:"
-	cleaned = regexp.MustCompile(`^(Result:|Output:)\s*`).ReplaceAllString(cleaned, "")
-	cleaned = strings.TrimSpace(cleaned)
-	
-	// Normalize array formatting - remove spaces around commas and brackets
-	cleaned = regexp.MustCompile(`\[\s*`).ReplaceAllString(cleaned, "[")
-	cleaned = regexp.MustCompile(`\s*\]`).ReplaceAllString(cleaned, "]")
-	cleaned = regexp.MustCompile(`\s*,\s*`).ReplaceAllString(cleaned, ",")
-	
-	return cleaned
+// judgeCaseRun extracts a case's return value, or the verdict and error output if it failed.
+// The verdict is empty when the function returned normally.
+func judgeCaseRun(caseRun batchCaseRun) (string, string, enums.Verdict) {
+	if caseRun.RunErr != nil {
+		return "", caseRun.RunErr.Error(), enums.VerdictRuntimeError
+	}
+
+	switch caseRun.Output.Status {
+	case "ok":
+		if caseRun.TimeLimitMs > 0 && caseRun.Output.Time > float64(caseRun.TimeLimitMs) {
+			return "", "", enums.VerdictTimeLimitExceeded
+		}
+		return caseRun.Output.Result, "", ""
+
+	case "error":
+		// The harness caught an exception thrown by the candidate's function
+		if isMemoryErrorType(caseRun.Output.ErrorType) {
+			return "", caseRun.Output.Error, enums.VerdictMemoryLimitExceeded
+		}
+		return "", caseRun.Output.Error, enums.VerdictRuntimeError
+
+	default:
+		// The program died before the case finished, or never reached it
+		stderr := caseRun.Run.Stderr
+		if caseRun.Run.CompileFailed {
+			stderr = caseRun.Run.CompileOutput
+		}
+		if verdict, failed := classifyRunFailure(caseRun.Run); failed {
+			return "", stderr, verdict
+		}
+		return "", stderr, enums.VerdictRuntimeError
+	}
 }
 
-// compareOutputs compares two outputs for equality
-func compareOutputs(actual, expected string) bool {
-	// Normalize both strings for comparison
-	actual = strings.TrimSpace(strings.ToLower(actual))
-	expected = strings.TrimSpace(strings.ToLower(expected))
-	
-	// Direct comparison
-	if actual == expected {
-		return true
+// classifyRunFailure reports whether a run failed and which verdict it earns.
+// Time and memory limits come from how the runner saw the program stop.
+func classifyRunFailure(runResult *RunResult) (enums.Verdict, bool) {
+	stderr := runResult.Stderr
+
+	switch {
+	case runResult.CompileFailed:
+		return enums.VerdictCompileError, true
+	case runResult.TimedOut:
+		return enums.VerdictTimeLimitExceeded, true
+	case runResult.MemoryExceeded:
+		return enums.VerdictMemoryLimitExceeded, true
+	// Fatal out-of-memory errors the harness cannot catch, raised below the runner's limit
+	case strings.Contains(stderr, "JavaScript heap out of memory") || strings.Contains(stderr, "runtime: out of memory") ||
+		strings.Contains(stderr, "fatal error: out of memory"):
+		return enums.VerdictMemoryLimitExceeded, true
+	// go run compiles in the run stage on Piston, so Go compile errors are only visible in stderr
+	case strings.Contains(stderr, "SyntaxError") || strings.Contains(stderr, "IndentationError") ||
+		strings.Contains(stderr, "TabError") || strings.Contains(stderr, "# command-line-arguments"):
+		return enums.VerdictCompileError, true
+	case runResult.ExitCode != 0 || runResult.Signal != "":
+		return enums.VerdictRuntimeError, true
 	}
-	
-	// Try to extract just the result value (remove any extra text)
-	actualValue := extractResultValue(actual)
-	expectedValue := extractResultValue(expected)
-	
-	return actualValue == expectedValue
+
+	return "", false
 }
 
-// extractResultValue extracts the actual result value from output
-func extractResultValue(output string) string {
-	// Try to extract the last meaningful value from the output
-	lines := strings.Split(output, "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line != "" && !strings.Contains(line, "Result:") && !strings.Contains(line, "Output:") {
-			// Extract just the value part
-			value := regexp.MustCompile(`.*?(\d+|true|false|"[^"]*"|'[^']*'|\[.*\]|\{.*\})`).FindString(line)
-			if value != "" {
-				return strings.TrimSpace(value)
-			}
+// overallVerdict returns the verdict of the first failing test case, or Accepted
+func overallVerdict(results []responses.TestCaseResult) enums.Verdict {
+	for _, result := range results {
+		if result.Verdict != enums.VerdictAccepted {
+			return result.Verdict
 		}
 	}
-	return strings.TrimSpace(output)
+	return enums.VerdictAccepted
 }
 
 // categorizeAndFormatErrors categorizes errors and formats them appropriately
@@ -210,14 +427,6 @@ func categorizeAndFormatErrors(errors []string, outputs []string) string {
 		}
 	}
 	
-	// Check for timeout or memory errors
-	for _, err := range errors {
-		if strings.Contains(err, "timeout") || strings.Contains(err, "memory") || 
-		  strings.Contains(err, "killed") {
-			return "Execution Error: " + extractMainError(err)
-		}
-	}
-	
 	// If it's just wrong answers, format them nicely
 	if len(errors) > 0 && strings.Contains(errors[0], "Expected") {
 		return strings.Join(errors, "\n")
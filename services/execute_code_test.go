@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+)
+
+func TestOverallVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		verdicts []enums.Verdict
+		want     enums.Verdict
+	}{
+		{name: "no cases", want: enums.VerdictAccepted},
+		{name: "all accepted", verdicts: []enums.Verdict{enums.VerdictAccepted, enums.VerdictAccepted}, want: enums.VerdictAccepted},
+		{name: "one wrong", verdicts: []enums.Verdict{enums.VerdictAccepted, enums.VerdictWrongAnswer}, want: enums.VerdictWrongAnswer},
+		{
+			name:     "first failure wins",
+			verdicts: []enums.Verdict{enums.VerdictAccepted, enums.VerdictRuntimeError, enums.VerdictTimeLimitExceeded},
+			want:     enums.VerdictRuntimeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []responses.TestCaseResult
+			for _, verdict := range tt.verdicts {
+				results = append(results, responses.TestCaseResult{Verdict: verdict})
+			}
+			if got := overallVerdict(results); got != tt.want {
+				t.Errorf("overallVerdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyRunFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        RunResult
+		want       enums.Verdict
+		wantFailed bool
+	}{
+		{name: "clean exit", run: RunResult{Stdout: "3\n"}},
+		{name: "compile failed", run: RunResult{CompileFailed: true, ExitCode: 1}, want: enums.VerdictCompileError, wantFailed: true},
+		{name: "timed out", run: RunResult{TimedOut: true, Signal: "SIGKILL"}, want: enums.VerdictTimeLimitExceeded, wantFailed: true},
+		{name: "memory exceeded", run: RunResult{MemoryExceeded: true, ExitCode: 1}, want: enums.VerdictMemoryLimitExceeded, wantFailed: true},
+		{
+			name:       "node heap exhausted",
+			run:        RunResult{Stderr: "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory", Signal: "SIGABRT"},
+			want:       enums.VerdictMemoryLimitExceeded,
+			wantFailed: true,
+		},
+		{name: "python syntax error", run: RunResult{Stderr: "SyntaxError: invalid syntax", ExitCode: 1}, want: enums.VerdictCompileError, wantFailed: true},
+		{name: "go run compile error", run: RunResult{Stderr: "# command-line-arguments\n./main.go:3:1: undefined: x", ExitCode: 1}, want: enums.VerdictCompileError, wantFailed: true},
+		{name: "non-zero exit", run: RunResult{Stderr: "Traceback", ExitCode: 1}, want: enums.VerdictRuntimeError, wantFailed: true},
+		{name: "killed by a signal", run: RunResult{Signal: "SIGSEGV"}, want: enums.VerdictRuntimeError, wantFailed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, failed := classifyRunFailure(&tt.run)
+			if got != tt.want || failed != tt.wantFailed {
+				t.Errorf("classifyRunFailure() = %q, %v, want %q, %v", got, failed, tt.want, tt.wantFailed)
+			}
+		})
+	}
+}
+
+// quotingCompiler fails every program with diagnostics that quote its whole source
+type quotingCompiler struct{}
+
+func (quotingCompiler) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	return &RunResult{CompileFailed: true, ExitCode: 1, CompileOutput: req.Code}, nil
+}
+
+func TestJudgeTestCasesRedactsHiddenCases(t *testing.T) {
+	question := models.TechnicalQuestion{
+		FunctionName: "solve",
+		TestCases: []models.TestCase{
+			{Input: "1", ExpectedOutput: "1"},
+			{Input: "424242", ExpectedOutput: "424242", Hidden: true},
+		},
+	}
+	driver, _ := GetLanguageDriver(string(enums.CodingLanguagePython))
+	response, _, err := judgeTestCases(requests.ExecuteTechnicalInput{Code: "def solve(x): return x"}, enums.ExecutionModeSubmit, driver, question, quotingCompiler{})
+	if err != nil {
+		t.Fatalf("judgeTestCases() error = %v", err)
+	}
+	hidden := response.Results[1]
+	if !reflect.DeepEqual(hidden, responses.TestCaseResult{Verdict: enums.VerdictCompileError, Hidden: true}) {
+		t.Errorf("hidden result = %+v, want only its verdict", hidden)
+	}
+	if strings.Contains(response.Error, "424242") || strings.Contains(response.Output, "424242") {
+		t.Errorf("response leaks the hidden case: error %q, output %q", response.Error, response.Output)
+	}
+}
+
+func TestJudgeCaseRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		caseRun     batchCaseRun
+		wantActual  string
+		wantStderr  string
+		wantVerdict enums.Verdict
+	}{
+		{
+			name:       "returned in time",
+			caseRun:    batchCaseRun{Output: harnessCaseOutput{Status: "ok", Result: "3", Time: 90}, TimeLimitMs: 100},
+			wantActual: "3",
+		},
+		{
+			name:        "returned too slowly",
+			caseRun:     batchCaseRun{Output: harnessCaseOutput{Status: "ok", Result: "3", Time: 101}, TimeLimitMs: 100},
+			wantVerdict: enums.VerdictTimeLimitExceeded,
+		},
+		{
+			name:       "no time limit",
+			caseRun:    batchCaseRun{Output: harnessCaseOutput{Status: "ok", Result: "3", Time: 5000}},
+			wantActual: "3",
+		},
+		{
+			name:        "out of memory inside the function",
+			caseRun:     batchCaseRun{Output: harnessCaseOutput{Status: "error", Error: "MemoryError", ErrorType: "MemoryError"}},
+			wantStderr:  "MemoryError",
+			wantVerdict: enums.VerdictMemoryLimitExceeded,
+		},
+		{
+			name:        "killed by the time limit",
+			caseRun:     batchCaseRun{Run: &RunResult{TimedOut: true, Signal: "SIGKILL", Stderr: "Killed"}},
+			wantStderr:  "Killed",
+			wantVerdict: enums.VerdictTimeLimitExceeded,
+		},
+		{
+			name:        "killed by the memory limit",
+			caseRun:     batchCaseRun{Run: &RunResult{MemoryExceeded: true, Signal: "SIGKILL"}},
+			wantVerdict: enums.VerdictMemoryLimitExceeded,
+		},
+		{
+			name:        "never reached",
+			caseRun:     batchCaseRun{Run: &RunResult{}},
+			wantVerdict: enums.VerdictRuntimeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, stderr, verdict := judgeCaseRun(tt.caseRun)
+			if actual != tt.wantActual || stderr != tt.wantStderr || verdict != tt.wantVerdict {
+				t.Errorf("judgeCaseRun() = %q, %q, %q, want %q, %q, %q", actual, stderr, verdict, tt.wantActual, tt.wantStderr, tt.wantVerdict)
+			}
+		})
+	}
+}
+
+func TestLimitExceededMessage(t *testing.T) {
+	tests := []struct {
+		verdict enums.Verdict
+		limits  models.ResourceLimits
+		want    string
+	}{
+		{verdict: enums.VerdictTimeLimitExceeded, limits: models.ResourceLimits{TimeLimitMs: 2000}, want: "Time Limit Exceeded (limit 2000 ms)"},
+		{verdict: enums.VerdictTimeLimitExceeded, want: "Time Limit Exceeded"},
+		{verdict: enums.VerdictMemoryLimitExceeded, limits: models.ResourceLimits{MemoryLimitMB: 64}, want: "Memory Limit Exceeded (limit 64 MB)"},
+		{verdict: enums.VerdictMemoryLimitExceeded, limits: models.ResourceLimits{TimeLimitMs: 2000}, want: "Memory Limit Exceeded"},
+	}
+	for _, tt := range tests {
+		if got := limitExceededMessage(tt.verdict, tt.limits); got != tt.want {
+			t.Errorf("limitExceededMessage(%q, %+v) = %q, want %q", tt.verdict, tt.limits, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateTestCaseKeepsLogsApart(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      harnessCaseOutput
+		wantActual  string
+		wantVerdict enums.Verdict
+	}{
+		{
+			name:        "prints alongside the right answer",
+			output:      harnessCaseOutput{Status: "ok", Result: "[0, 1]", Logs: "checking 0\nchecking 1\n"},
+			wantActual:  "[0, 1]",
+			wantVerdict: enums.VerdictAccepted,
+		},
+		{
+			name:        "prints the answer but returns another",
+			output:      harnessCaseOutput{Status: "ok", Result: "null", Logs: "[0, 1]\n"},
+			wantActual:  "null",
+			wantVerdict: enums.VerdictWrongAnswer,
+		},
+		{
+			name:        "prints before raising",
+			output:      harnessCaseOutput{Status: "error", Error: "IndexError", ErrorType: "IndexError", Logs: "[0, 1]\n"},
+			wantVerdict: enums.VerdictRuntimeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparator := NewOutputComparator(models.TechnicalQuestion{}, nil)
+			testCase := models.TestCase{Input: "[2, 7], 9", ExpectedOutput: "[0, 1]"}
+
+			result, err := evaluateTestCase(testCase, batchCaseRun{Output: tt.output}, comparator)
+			if err != nil {
+				t.Fatalf("evaluateTestCase() error = %v", err)
+			}
+			if result.Actual != tt.wantActual || result.Verdict != tt.wantVerdict || result.Logs != tt.output.Logs {
+				t.Errorf("evaluateTestCase() = actual %q, verdict %q, logs %q, want %q, %q, %q",
+					result.Actual, result.Verdict, result.Logs, tt.wantActual, tt.wantVerdict, tt.output.Logs)
+			}
+		})
+	}
+}
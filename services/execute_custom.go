@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"stormhacks-be/models"
+	"stormhacks-be/repositories"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+)
+
+// ExecuteCustom runs the candidate's code against an input of their choosing,
+// alongside the question's reference solution when it has one
+func ExecuteCustom(input requests.ExecuteCustomInput, interviewRepo *repositories.InterviewRepository, codeRunner CodeRunner, codePolicy *CodePolicy) (*responses.ExecuteCustomResponse, error) {
+	// Validate language
+	driver, err := GetLanguageDriver(string(input.Language))
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the technical question by ID
+	question, err := interviewRepo.GetTechnicalQuestionByID(input.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	// Code that breaks the policy is refused before it reaches the runner
+	if violation := codePolicy.Check(driver, input.Code, question.Question); violation != nil {
+		return &responses.ExecuteCustomResponse{
+			QuestionID: input.QuestionID,
+			Language:   string(input.Language),
+			Input:      input.Input,
+			Result: responses.CustomRunResult{
+				Stderr:  "Policy Violation: " + violation.Error(),
+				Verdict: enums.VerdictPolicyViolation,
+			},
+		}, nil
+	}
+
+	testCase := models.TestCase{Input: input.Input}
+
+	result, executionTime, err := runCustomInput(codeRunner, driver, input.Code, question.Question, testCase)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &responses.ExecuteCustomResponse{
+		QuestionID:    input.QuestionID,
+		Language:      string(input.Language),
+		Input:         input.Input,
+		Result:        *result,
+		ExecutionTime: executionTime,
+	}
+
+	// Show what the reference solution returns for the same input. Its own
+	// prints and errors are left out so its code cannot leak through them.
+	if reference := question.Question.ReferenceSolutionFor(input.Language); reference != nil {
+		referenceDriver, err := GetLanguageDriver(string(reference.Language))
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference solution: %w", err)
+		}
+
+		referenceResult, _, err := runCustomInput(codeRunner, referenceDriver, reference.Code, question.Question, testCase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run reference solution: %w", err)
+		}
+		response.Reference = &responses.CustomRunResult{
+			ReturnValue: referenceResult.ReturnValue,
+			Runtime:     referenceResult.Runtime,
+			Verdict:     referenceResult.Verdict,
+		}
+	}
+
+	return response, nil
+}
+
+// runCustomInput runs code against a single input through the batch harness
+func runCustomInput(codeRunner CodeRunner, driver LanguageDriver, code string, question models.TechnicalQuestion, testCase models.TestCase) (*responses.CustomRunResult, int64, error) {
+	caseRuns, executionTime, err := runTestCasesBatched(codeRunner, driver, code, question, []models.TestCase{testCase})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	caseRun := caseRuns[0]
+	returnValue, stderr, verdict := judgeCaseRun(caseRun)
+
+	return &responses.CustomRunResult{
+		ReturnValue: returnValue,
+		Logs:        caseRun.Output.Logs,
+		Stderr:      stderr,
+		Runtime:     int64(math.Round(caseRun.Output.Time)),
+		Verdict:     verdict,
+	}, executionTime, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/responses"
+)
+
+// runnerFunc adapts a function to the CodeRunner interface
+type runnerFunc func(req RunRequest) (*RunResult, error)
+
+func (f runnerFunc) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	return f(req)
+}
+
+// harnessNonceMarker finds the nonce a harness program tags its output with
+var harnessNonceMarker = regexp.MustCompile(`@@([0-9a-f]+) BEGIN`)
+
+// harnessReply returns a runner that answers every harness program with the
+// given output, with each {{nonce}} replaced by the program's nonce
+func harnessReply(stdout string, result RunResult) CodeRunner {
+	return runnerFunc(func(req RunRequest) (*RunResult, error) {
+		nonce := harnessNonceMarker.FindStringSubmatch(req.Code)[1]
+		result.Stdout = strings.ReplaceAll(stdout, "{{nonce}}", nonce)
+		return &result, nil
+	})
+}
+
+func TestRunCustomInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner CodeRunner
+		want   responses.CustomRunResult
+	}{
+		{
+			name:   "returned",
+			runner: harnessReply("@@{{nonce}} BEGIN 0@@\nhello\n\n@@{{nonce}} END 0 {\"status\": \"ok\", \"result\": \"[1, 2]\", \"time\": 1.6}@@\n", RunResult{}),
+			want:   responses.CustomRunResult{ReturnValue: "[1, 2]", Logs: "hello\n", Runtime: 2},
+		},
+		{
+			name:   "raised",
+			runner: harnessReply("@@{{nonce}} BEGIN 0@@\n\n@@{{nonce}} END 0 {\"status\": \"error\", \"error\": \"ValueError: bad\", \"errorType\": \"ValueError\"}@@\n", RunResult{}),
+			want:   responses.CustomRunResult{Stderr: "ValueError: bad", Verdict: enums.VerdictRuntimeError},
+		},
+		{
+			name:   "killed mid-case",
+			runner: harnessReply("@@{{nonce}} BEGIN 0@@\nworking", RunResult{TimedOut: true, Signal: "SIGKILL", Stderr: "Killed"}),
+			want:   responses.CustomRunResult{Logs: "working", Stderr: "Killed", Verdict: enums.VerdictTimeLimitExceeded},
+		},
+		{
+			name:   "compile error",
+			runner: harnessReply("", RunResult{CompileFailed: true, ExitCode: 1, CompileOutput: "SyntaxError: invalid syntax"}),
+			want:   responses.CustomRunResult{Stderr: "SyntaxError: invalid syntax", Verdict: enums.VerdictCompileError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, _ := GetLanguageDriver(string(enums.CodingLanguagePython))
+			question := models.TechnicalQuestion{FunctionName: "solve"}
+
+			got, _, err := runCustomInput(tt.runner, driver, "def solve(x): return x", question, models.TestCase{Input: "[1, 2]"})
+			if err != nil {
+				t.Fatalf("runCustomInput() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("runCustomInput() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
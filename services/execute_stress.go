@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+)
+
+// Stress tests run their trials in rounds of growing input size and stop after
+// the first round that finds a failing input, so counterexamples stay small
+const (
+	defaultStressTrials = 100
+	maxStressTrials     = 1000
+	stressRounds        = 4
+)
+
+// executeStress runs code and the question's trusted solution on random inputs
+// and reports the smallest input on which the code's result differs
+func executeStress(input requests.ExecuteTechnicalInput, driver LanguageDriver, question models.TechnicalQuestion, codeRunner CodeRunner) (*responses.ExecuteTechnicalResponse, error) {
+	if question.Generator == nil || !question.IsFunction() {
+		return nil, errors.New("question does not support stress testing")
+	}
+
+	trusted := question.Generator.TrustedSolution
+	if trusted == nil || trusted.Code == "" {
+		trusted = question.ReferenceSolutionFor(driver.Language())
+	}
+	if trusted == nil {
+		return nil, errors.New("question has no trusted solution to stress test against")
+	}
+	trustedDriver, err := GetLanguageDriver(string(trusted.Language))
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted solution: %w", err)
+	}
+
+	seed := input.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	generator, err := newInputGenerator(question.Generator.Arguments, seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input generator: %w", err)
+	}
+
+	trials := question.Generator.Trials
+	if trials <= 0 {
+		trials = defaultStressTrials
+	}
+	if trials > maxStressTrials {
+		trials = maxStressTrials
+	}
+
+	comparator := NewOutputComparator(question, codeRunner)
+	limits := question.LimitsFor(driver.Language())
+	response := &responses.ExecuteTechnicalResponse{
+		QuestionID: input.QuestionID,
+		Code:       input.Code,
+		Language:   string(input.Language),
+		Mode:       enums.ExecutionModeStress,
+		Results:    []responses.TestCaseResult{},
+		Seed:       seed,
+	}
+
+	var trustedFailure string
+	for round := 0; round < stressRounds && response.Counterexample == nil; round++ {
+		count := trials / stressRounds
+		if round == stressRounds-1 {
+			count = trials - count*(stressRounds-1)
+		}
+
+		testCases := make([]models.TestCase, count)
+		for i := range testCases {
+			generated, err := generator.Generate(float64(round+1) / stressRounds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate input: %w", err)
+			}
+			testCases[i] = models.TestCase{Input: generated}
+		}
+
+		// The trusted solution decides each expected output; inputs it fails on are dropped
+		trustedRuns, executionTime, err := runTestCasesBatched(codeRunner, trustedDriver, trusted.Code, question, testCases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run trusted solution: %w", err)
+		}
+		response.ExecutionTime += executionTime
+
+		var judged []models.TestCase
+		for i, testCase := range testCases {
+			expected, stderr, verdict := judgeCaseRun(trustedRuns[i])
+			if verdict != "" {
+				trustedFailure = fmt.Sprintf("%s on input %s: %s", verdict, testCase.Input, stderr)
+				continue
+			}
+			testCase.ExpectedOutput = expected
+			judged = append(judged, testCase)
+		}
+		if len(judged) == 0 {
+			continue
+		}
+
+		caseRuns, executionTime, err := runTestCasesBatched(codeRunner, driver, input.Code, question, judged)
+		if err != nil {
+			return nil, err
+		}
+		response.ExecutionTime += executionTime
+
+		for i, testCase := range judged {
+			result, err := evaluateTestCase(testCase, caseRuns[i], comparator)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare output for input %s: %w", testCase.Input, err)
+			}
+
+			response.Total++
+			if result.Verdict == enums.VerdictAccepted {
+				response.Passed++
+				continue
+			}
+			if response.Counterexample == nil || smallerInput(result.Input, response.Counterexample.Input) {
+				counterexample := result
+				response.Counterexample = &counterexample
+			}
+		}
+	}
+
+	if response.Total == 0 {
+		return nil, fmt.Errorf("trusted solution failed on every generated input, last: %s", trustedFailure)
+	}
+
+	if response.Counterexample == nil {
+		response.Success = true
+		response.Verdict = enums.VerdictAccepted
+		response.Output = fmt.Sprintf("No failing input found in %d random inputs", response.Total)
+		return response, nil
+	}
+
+	response.Verdict = response.Counterexample.Verdict
+	response.Results = append(response.Results, *response.Counterexample)
+	response.Error = describeCounterexample(*response.Counterexample, limits)
+	return response, nil
+}
+
+// smallerInput reports whether input a is smaller than input b
+func smallerInput(a, b string) bool {
+	countA, magnitudeA := inputSize(a)
+	countB, magnitudeB := inputSize(b)
+	if countA != countB {
+		return countA < countB
+	}
+	return magnitudeA < magnitudeB
+}
+
+// describeCounterexample explains how the code failed on a random input
+func describeCounterexample(result responses.TestCaseResult, limits models.ResourceLimits) string {
+	switch result.Verdict {
+	case enums.VerdictWrongAnswer:
+		return fmt.Sprintf("Input %s: Expected '%s', got '%s'", result.Input, result.Expected, result.Actual)
+	case enums.VerdictTimeLimitExceeded, enums.VerdictMemoryLimitExceeded:
+		return fmt.Sprintf("Input %s: %s", result.Input, limitExceededMessage(result.Verdict, limits))
+	default:
+		return fmt.Sprintf("Input %s: %s", result.Input, result.Stderr)
+	}
+}
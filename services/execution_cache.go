@@ -0,0 +1,255 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"stormhacks-be/models"
+	"stormhacks-be/repositories"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/responses"
+)
+
+// ExecutionCacheConfig holds execution cache configuration
+type ExecutionCacheConfig struct {
+	Size    int  // executions kept in memory, zero keeps none
+	Persist bool // also keep executions in MongoDB, shared between servers and across restarts
+}
+
+// DefaultExecutionCacheConfig returns a default execution cache configuration from environment variables
+func DefaultExecutionCacheConfig() ExecutionCacheConfig {
+	size, err := strconv.Atoi(os.Getenv("EXECUTION_CACHE_SIZE"))
+	if err != nil || size < 0 {
+		size = 1000
+	}
+
+	persist, _ := strconv.ParseBool(os.Getenv("EXECUTION_CACHE_MONGO"))
+
+	return ExecutionCacheConfig{
+		Size:    size,
+		Persist: persist,
+	}
+}
+
+// executionCacheKey identifies an execution: the code, the runtime it ran on,
+// the mode, and the revision of the question it was judged against
+type executionCacheKey struct {
+	hash       string
+	questionID string
+	revision   string
+}
+
+// newExecutionCacheKey builds the cache key for running code against a question
+func newExecutionCacheKey(code string, driver LanguageDriver, mode enums.ExecutionMode, question models.TechnicalBank) executionCacheKey {
+	questionID := question.ID.Hex()
+	revision := questionRevision(question)
+
+	hash := sha256.New()
+	for _, part := range []string{string(driver.Language()), driver.Version(), string(mode), questionID, revision, normalizeCode(code)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return executionCacheKey{
+		hash:       hex.EncodeToString(hash.Sum(nil)),
+		questionID: questionID,
+		revision:   revision,
+	}
+}
+
+// questionRevisionFields is everything stored for a question. The question's
+// own JSON leaves out what candidates must not see, which still changes how
+// code is judged: the checker, the reference solutions and the generator.
+type questionRevisionFields struct {
+	Question           models.TechnicalBank       `json:"question"`
+	Checker            *models.CheckerScript      `json:"checker"`
+	ReferenceSolutions []models.ReferenceSolution `json:"referenceSolutions"`
+	Generator          *models.InputGenerator     `json:"generator"`
+}
+
+// questionRevision hashes everything stored for a question, so editing its
+// test cases, limits, checker or anything else judged with them starts a new
+// revision. JSON rather than BSON, since it writes maps in a stable order.
+func questionRevision(question models.TechnicalBank) string {
+	fields := questionRevisionFields{
+		Question:           question,
+		ReferenceSolutions: question.Question.ReferenceSolutions,
+		Generator:          question.Question.Generator,
+	}
+	if question.Question.Comparison != nil {
+		fields.Checker = question.Question.Comparison.Checker
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalizeCode removes differences that cannot change how code runs: line
+// endings, whitespace at the end of lines and blank lines at the end
+func normalizeCode(code string) string {
+	lines := strings.Split(strings.ReplaceAll(code, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// executionCacheEntry is an execution kept in memory
+type executionCacheEntry struct {
+	key      executionCacheKey
+	response responses.ExecuteTechnicalResponse
+}
+
+// ExecutionCache remembers judged executions, so running unchanged code again
+// against an unchanged question answers without reaching the code runner. It
+// keeps the most recently used executions in memory and, optionally, every
+// execution in MongoDB. When a question changes, executions judged against its
+// old revision are dropped.
+type ExecutionCache struct {
+	size          int
+	interviewRepo *repositories.InterviewRepository // nil unless executions are persisted
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element // by key hash
+	order     *list.List               // most recently used first
+	revisions map[string]string        // latest revision seen of each question
+}
+
+// NewExecutionCache creates an execution cache
+func NewExecutionCache(config ExecutionCacheConfig, interviewRepo *repositories.InterviewRepository) *ExecutionCache {
+	c := &ExecutionCache{
+		size:      config.Size,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+		revisions: make(map[string]string),
+	}
+	if config.Persist {
+		c.interviewRepo = interviewRepo
+	}
+	return c
+}
+
+// Get returns a copy of the cached execution for a key, if there is one
+func (c *ExecutionCache) Get(key executionCacheKey) (*responses.ExecuteTechnicalResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.observeRevision(key)
+
+	c.mu.Lock()
+	if element, ok := c.entries[key.hash]; ok {
+		c.order.MoveToFront(element)
+		response := element.Value.(*executionCacheEntry).response
+		c.mu.Unlock()
+		return &response, true
+	}
+	c.mu.Unlock()
+
+	if c.interviewRepo == nil {
+		return nil, false
+	}
+	cached, err := c.interviewRepo.GetCachedExecution(key.hash)
+	if err != nil {
+		log.Printf("Warning: Failed to read cached execution: %v", err)
+		return nil, false
+	}
+	if cached == nil || cached.Revision != key.revision {
+		return nil, false
+	}
+	var response responses.ExecuteTechnicalResponse
+	if err := json.Unmarshal(cached.Response, &response); err != nil {
+		log.Printf("Warning: Failed to decode cached execution: %v", err)
+		return nil, false
+	}
+
+	c.remember(key, response)
+	return &response, true
+}
+
+// Put caches an execution
+func (c *ExecutionCache) Put(key executionCacheKey, response *responses.ExecuteTechnicalResponse) {
+	if c == nil {
+		return
+	}
+	c.remember(key, *response)
+
+	if c.interviewRepo == nil {
+		return
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Warning: Failed to encode execution for the cache: %v", err)
+		return
+	}
+	err = c.interviewRepo.SaveCachedExecution(&models.CachedExecution{
+		Key:        key.hash,
+		QuestionID: key.questionID,
+		Revision:   key.revision,
+		Response:   encoded,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to save cached execution: %v", err)
+	}
+}
+
+// remember keeps an execution in memory, evicting the least recently used
+// execution beyond the cache's size
+func (c *ExecutionCache) remember(key executionCacheKey, response responses.ExecuteTechnicalResponse) {
+	if c.size == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key.hash]; ok {
+		element.Value.(*executionCacheEntry).response = response
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key.hash] = c.order.PushFront(&executionCacheEntry{key: key, response: response})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*executionCacheEntry).key.hash)
+	}
+}
+
+// observeRevision notes the revision of a question being run, and drops the
+// question's executions judged against any other revision once it changes
+func (c *ExecutionCache) observeRevision(key executionCacheKey) {
+	c.mu.Lock()
+	previous, seen := c.revisions[key.questionID]
+	c.revisions[key.questionID] = key.revision
+	if !seen || previous == key.revision {
+		c.mu.Unlock()
+		return
+	}
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*executionCacheEntry)
+		if entry.key.questionID == key.questionID && entry.key.revision != key.revision {
+			c.order.Remove(element)
+			delete(c.entries, entry.key.hash)
+		}
+		element = next
+	}
+	c.mu.Unlock()
+
+	if c.interviewRepo != nil {
+		if err := c.interviewRepo.DeleteCachedExecutions(key.questionID, key.revision); err != nil {
+			log.Printf("Warning: Failed to drop cached executions of question %s: %v", key.questionID, err)
+		}
+	}
+}
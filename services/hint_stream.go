@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"stormhacks-be/types/responses"
+)
+
+// StreamHint generates a hint like GenerateHint, passing the conversational
+// hint to onHint piece by piece as the model writes it. The returned hint is
+// the final one: it differs from the streamed text only if the streamed reply
+// broke the hint schema and had to be repaired.
+func (s *LLMService) StreamHint(ctx context.Context, sessionID string, question string, userCode string, userSpeech string, previousHints []string, onHint func(string)) (*responses.HintResponse, error) {
+	// Render the session's version of the prompt
+	prompt, version, err := s.renderHintPrompt(sessionID, question, userCode, userSpeech, previousHints)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream the model's reply, reading the conversational hint out of the JSON as it arrives
+	conversationalHint := newJSONFieldStream("conversationalHint")
+	reply, err := s.llm.Stream(ctx, LLMRequest{Prompt: prompt, Schema: hintSchema()}, func(chunk string) {
+		if text := conversationalHint.Write(chunk); text != "" {
+			onHint(text)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream hints: %w", err)
+	}
+
+	var response hintReply
+	if err := s.repairStructured(ctx, prompt, reply, hintSchema(), &response); err != nil {
+		return nil, fmt.Errorf("failed to stream hints: %w", err)
+	}
+
+	return &responses.HintResponse{
+		ConversationalHint: response.ConversationalHint,
+		HintSummary:        response.HintSummary,
+		PromptVersion:      version,
+	}, nil
+}
+
+// jsonFieldStream reads one string field's value out of a JSON object that
+// arrives in pieces, decoding the value as soon as its text is received
+type jsonFieldStream struct {
+	start   *regexp.Regexp // matches the field's name up to the value's opening quote
+	pending []byte         // received text not decoded yet
+	started bool
+	done    bool
+}
+
+// newJSONFieldStream creates a stream for the named string field
+func newJSONFieldStream(field string) *jsonFieldStream {
+	return &jsonFieldStream{
+		start: regexp.MustCompile(`"` + regexp.QuoteMeta(field) + `"\s*:\s*"`),
+	}
+}
+
+// Write takes the next piece of the JSON and returns the part of the field's
+// value it completes. Escapes and characters split across pieces are held
+// back until the rest of them arrives.
+func (f *jsonFieldStream) Write(chunk string) string {
+	if f.done {
+		return ""
+	}
+	f.pending = append(f.pending, chunk...)
+
+	if !f.started {
+		match := f.start.FindIndex(f.pending)
+		if match == nil {
+			return ""
+		}
+		f.started = true
+		f.pending = f.pending[match[1]:]
+	}
+
+	var text strings.Builder
+	i := 0
+	for i < len(f.pending) {
+		c := f.pending[i]
+		if c == '"' {
+			f.done = true
+			f.pending = nil
+			return text.String()
+		}
+		if c != '\\' {
+			if !utf8.FullRune(f.pending[i:]) {
+				break
+			}
+			_, size := utf8.DecodeRune(f.pending[i:])
+			text.Write(f.pending[i : i+size])
+			i += size
+			continue
+		}
+
+		r, size := decodeJSONEscape(f.pending[i:])
+		if size == 0 {
+			break
+		}
+		text.WriteRune(r)
+		i += size
+	}
+	f.pending = f.pending[i:]
+
+	return text.String()
+}
+
+// decodeJSONEscape decodes the escape sequence at the start of data, returning
+// a size of 0 when data ends before the sequence does
+func decodeJSONEscape(data []byte) (rune, int) {
+	if len(data) < 2 {
+		return 0, 0
+	}
+	switch data[1] {
+	case 'n':
+		return '\n', 2
+	case 't':
+		return '\t', 2
+	case 'r':
+		return '\r', 2
+	case 'b':
+		return '\b', 2
+	case 'f':
+		return '\f', 2
+	case 'u':
+		if len(data) < 6 {
+			return 0, 0
+		}
+		r := parseJSONHex(data[2:6])
+		if !utf16.IsSurrogate(r) {
+			return r, 6
+		}
+		// A character outside the basic plane is escaped as a pair of surrogates
+		if len(data) < 12 {
+			return 0, 0
+		}
+		if data[6] != '\\' || data[7] != 'u' {
+			return utf8.RuneError, 6
+		}
+		return utf16.DecodeRune(r, parseJSONHex(data[8:12])), 12
+	default:
+		// \" \\ and \/ stand for the character itself
+		return rune(data[1]), 2
+	}
+}
+
+// parseJSONHex parses the four hex digits of a \u escape
+func parseJSONHex(digits []byte) rune {
+	value, err := strconv.ParseUint(string(digits), 16, 16)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(value)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONFieldStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string // text returned for each chunk
+	}{
+		{
+			name:   "whole object at once",
+			chunks: []string{`{"hintSummary": "x", "conversationalHint": "Try a map.", "other": "y"}`},
+			want:   []string{"Try a map."},
+		},
+		{
+			name:   "value in pieces",
+			chunks: []string{`{"conversationalHint": "Try`, ` a `, `map."}`},
+			want:   []string{"Try", " a ", "map."},
+		},
+		{
+			name:   "field name split",
+			chunks: []string{`{"conversa`, `tionalHint"`, ` : `, `"Hi"}`},
+			want:   []string{"", "", "", "Hi"},
+		},
+		{
+			name:   "other field first",
+			chunks: []string{`{"hintSummary": "not this", `, `"conversationalHint": "this"}`},
+			want:   []string{"", "this"},
+		},
+		{
+			name:   "escapes",
+			chunks: []string{`{"conversationalHint": "a\"b\\c\/d\ne\tf"}`},
+			want:   []string{"a\"b\\c/d\ne\tf"},
+		},
+		{
+			name:   "escape split",
+			chunks: []string{`{"conversationalHint": "line\`, `nnext"}`},
+			want:   []string{"line", "\nnext"},
+		},
+		{
+			name:   "unicode escape split",
+			chunks: []string{`{"conversationalHint": "caf\u00`, `e9!"}`},
+			want:   []string{"caf", "é!"},
+		},
+		{
+			name:   "surrogate pair split",
+			chunks: []string{`{"conversationalHint": "ok \ud83d`, `\ude00"}`},
+			want:   []string{"ok ", "😀"},
+		},
+		{
+			name:   "unpaired surrogate",
+			chunks: []string{`{"conversationalHint": "\ud83d and more"}`},
+			want:   []string{"� and more"},
+		},
+		{
+			name:   "character split across chunks",
+			chunks: []string{"{\"conversationalHint\": \"caf\xc3", "\xa9\"}"},
+			want:   []string{"caf", "é"},
+		},
+		{
+			name:   "nothing after the closing quote",
+			chunks: []string{`{"conversationalHint": "done"`, `, "conversationalHint": "again"}`},
+			want:   []string{"done", ""},
+		},
+		{
+			name:   "field missing",
+			chunks: []string{`{"hintSummary": "x"}`},
+			want:   []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := newJSONFieldStream("conversationalHint")
+			got := make([]string, len(tt.chunks))
+			for i, chunk := range tt.chunks {
+				got[i] = stream.Write(chunk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stormhacks-be/models"
+)
+
+// Defaults for argument specs that leave a bound unset
+const (
+	defaultGeneratorMin       = -100
+	defaultGeneratorMax       = 100
+	defaultGeneratorMaxLength = 10
+	defaultGeneratorAlphabet  = "abcdefghijklmnopqrstuvwxyz"
+)
+
+// inputGenerator produces random test case inputs from a question's argument specs
+type inputGenerator struct {
+	arguments []models.ArgumentSpec
+	rng       *rand.Rand
+}
+
+// newInputGenerator checks the argument specs and seeds a generator with them
+func newInputGenerator(arguments []models.ArgumentSpec, seed int64) (*inputGenerator, error) {
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("input generator has no arguments")
+	}
+	for i, spec := range arguments {
+		baseType, _ := splitValueType(spec.Type)
+		switch baseType {
+		case "int", "long", "double", "bool", "string", "char", listNodeType, treeNodeType, graphNodeType:
+		default:
+			return nil, fmt.Errorf("argument %d: unsupported generator type: %s", i+1, spec.Type)
+		}
+		if spec.Min != nil && spec.Max != nil && *spec.Min > *spec.Max {
+			return nil, fmt.Errorf("argument %d: min is greater than max", i+1)
+		}
+		if spec.MaxLength > 0 && spec.MinLength > spec.MaxLength {
+			return nil, fmt.Errorf("argument %d: minLength is greater than maxLength", i+1)
+		}
+	}
+	return &inputGenerator{arguments: arguments, rng: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Generate returns one random input, written like a test case input.
+// Scale, between 0 and 1, caps how close array and string lengths may get
+// to their maximum, so early inputs can be kept small.
+func (g *inputGenerator) Generate(scale float64) (string, error) {
+	return g.generate(scale, -1)
+}
+
+// GenerateSized returns one random input whose scaled arguments have size n:
+// n elements in an array's outer dimension, n characters in a string, n nodes
+// in a linked list, tree or graph, or the value n for a number. Arguments are
+// scaled if marked so, otherwise the first array, string or node argument is.
+func (g *inputGenerator) GenerateSized(n int) (string, error) {
+	return g.generate(1, n)
+}
+
+// generate writes out one random value per argument, sizing the scaled ones to n if n is not negative
+func (g *inputGenerator) generate(scale float64, n int) (string, error) {
+	scaled := g.scaledArguments()
+	if n >= 0 && len(scaled) == 0 {
+		return "", fmt.Errorf("input generator has no argument that can grow with n")
+	}
+
+	literals := make([]string, len(g.arguments))
+	for i, spec := range g.arguments {
+		baseType, depth := splitValueType(spec.Type)
+
+		var value interface{}
+		switch {
+		case n < 0 || !scaled[i]:
+			value = g.generateValue(spec, baseType, depth, scale, -1)
+		case depth > 0:
+			value = g.generateValue(spec, baseType, depth, scale, n)
+		case baseType == "string":
+			value = g.generateString(spec, n)
+		case isNodeType(baseType):
+			value = g.generateNodes(spec, baseType, n, 1)
+		default:
+			value = float64(n)
+		}
+
+		literal, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		literals[i] = string(literal)
+	}
+	return strings.Join(literals, ", "), nil
+}
+
+// scaledArguments returns which arguments grow with n
+func (g *inputGenerator) scaledArguments() map[int]bool {
+	scaled := make(map[int]bool)
+	for i, spec := range g.arguments {
+		if spec.Scaled {
+			scaled[i] = true
+		}
+	}
+	if len(scaled) > 0 {
+		return scaled
+	}
+
+	for i, spec := range g.arguments {
+		if baseType, depth := splitValueType(spec.Type); depth > 0 || baseType == "string" || isNodeType(baseType) {
+			scaled[i] = true
+			break
+		}
+	}
+	return scaled
+}
+
+// generateValue builds a value with depth array dimensions around baseType.
+// A non-negative length fixes the length of this dimension.
+func (g *inputGenerator) generateValue(spec models.ArgumentSpec, baseType string, depth int, scale float64, length int) interface{} {
+	if depth == 0 {
+		return g.generateScalar(spec, baseType, scale)
+	}
+
+	if length < 0 {
+		length = g.generateLength(spec, scale)
+	}
+	if depth == 1 {
+		return g.generateList(spec, baseType, length, scale)
+	}
+
+	// Rectangular arrays pick each inner length once for the whole dimension
+	innerLength := -1
+	if spec.Rectangular {
+		innerLength = g.generateLength(spec, scale)
+	}
+	values := make([]interface{}, length)
+	for i := range values {
+		values[i] = g.generateValue(spec, baseType, depth-1, scale, innerLength)
+	}
+	return values
+}
+
+// generateList builds an innermost array, honouring the unique and sorted constraints
+func (g *inputGenerator) generateList(spec models.ArgumentSpec, baseType string, length int, scale float64) []interface{} {
+	values := make([]interface{}, 0, length)
+	seen := make(map[string]bool)
+	for attempts := 0; len(values) < length && attempts < length*20+20; attempts++ {
+		value := g.generateScalar(spec, baseType, scale)
+		if spec.Unique {
+			key := fmt.Sprint(value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, value)
+	}
+
+	if spec.Sorted {
+		sort.SliceStable(values, func(i, j int) bool {
+			return scalarLess(values[i], values[j])
+		})
+	}
+	return values
+}
+
+// generateScalar picks a single random value of a base type
+func (g *inputGenerator) generateScalar(spec models.ArgumentSpec, baseType string, scale float64) interface{} {
+	switch baseType {
+	case "int", "long":
+		low, high := generatorBounds(spec)
+		low, high = math.Ceil(low), math.Floor(high)
+		if high < low {
+			return low
+		}
+		return low + float64(g.rng.Int63n(int64(high-low)+1))
+	case "double":
+		low, high := generatorBounds(spec)
+		value := low + g.rng.Float64()*(high-low)
+		// Keep literals short and exactly representable in every language
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', 4, 64), 64)
+		return rounded
+	case "bool":
+		return g.rng.Intn(2) == 1
+	case "char":
+		return g.generateString(spec, 1)
+	case listNodeType, treeNodeType, graphNodeType:
+		return g.generateNodes(spec, baseType, g.generateLength(spec, scale), scale)
+	default:
+		return g.generateString(spec, g.generateLength(spec, scale))
+	}
+}
+
+// generateNodes builds a linked list, tree or graph of count nodes, written
+// the way test cases write it. List and tree values follow the spec's number
+// bounds and unique constraint; a sorted list is in ascending order and a
+// sorted tree is a binary search tree. Graphs are connected and undirected.
+func (g *inputGenerator) generateNodes(spec models.ArgumentSpec, nodeType string, count int, scale float64) []interface{} {
+	switch nodeType {
+	case listNodeType:
+		return g.generateList(spec, "int", count, scale)
+	case treeNodeType:
+		return g.generateTree(spec, count, scale)
+	default:
+		return g.generateGraph(count)
+	}
+}
+
+// generateTree builds a random binary tree and writes it out level by level
+func (g *inputGenerator) generateTree(spec models.ArgumentSpec, count int, scale float64) []interface{} {
+	type treeNode struct {
+		value       interface{}
+		left, right *treeNode
+	}
+
+	unsorted := spec
+	unsorted.Sorted = false
+	values := g.generateList(unsorted, "int", count, scale)
+	if len(values) == 0 {
+		return []interface{}{}
+	}
+
+	root := &treeNode{value: values[0]}
+	open := []*treeNode{root} // nodes with a free child
+	for _, value := range values[1:] {
+		node := &treeNode{value: value}
+		if spec.Sorted {
+			// Insert as into a binary search tree, equal values to the right
+			for parent := root; ; {
+				child := &parent.right
+				if scalarLess(value, parent.value) {
+					child = &parent.left
+				}
+				if *child == nil {
+					*child = node
+					break
+				}
+				parent = *child
+			}
+			continue
+		}
+
+		i := g.rng.Intn(len(open))
+		parent := open[i]
+		if parent.left == nil && (parent.right != nil || g.rng.Intn(2) == 0) {
+			parent.left = node
+		} else {
+			parent.right = node
+		}
+		if parent.left != nil && parent.right != nil {
+			open[i] = open[len(open)-1]
+			open = open[:len(open)-1]
+		}
+		open = append(open, node)
+	}
+
+	var levelOrder []interface{}
+	queue := []*treeNode{root}
+	for head := 0; head < len(queue); head++ {
+		node := queue[head]
+		if node == nil {
+			levelOrder = append(levelOrder, nil)
+			continue
+		}
+		levelOrder = append(levelOrder, node.value)
+		queue = append(queue, node.left, node.right)
+	}
+	for len(levelOrder) > 0 && levelOrder[len(levelOrder)-1] == nil {
+		levelOrder = levelOrder[:len(levelOrder)-1]
+	}
+	return levelOrder
+}
+
+// generateGraph builds a random connected undirected graph: a random spanning
+// tree plus about as many extra edges again, as an adjacency list
+func (g *inputGenerator) generateGraph(count int) []interface{} {
+	neighbors := make([][]int, count)
+	edges := make(map[[2]int]bool)
+	connect := func(a, b int) {
+		if a == b || edges[[2]int{a, b}] {
+			return
+		}
+		edges[[2]int{a, b}], edges[[2]int{b, a}] = true, true
+		neighbors[a] = append(neighbors[a], b+1)
+		neighbors[b] = append(neighbors[b], a+1)
+	}
+	for i := 1; i < count; i++ {
+		connect(i, g.rng.Intn(i))
+	}
+	for i := 1; i < count; i++ {
+		connect(g.rng.Intn(count), g.rng.Intn(count))
+	}
+
+	adjacency := make([]interface{}, count)
+	for i, list := range neighbors {
+		values := make([]interface{}, len(list))
+		for j, value := range list {
+			values[j] = float64(value)
+		}
+		adjacency[i] = values
+	}
+	return adjacency
+}
+
+// generatorBounds returns the range numbers are drawn from
+func generatorBounds(spec models.ArgumentSpec) (float64, float64) {
+	low, high := float64(defaultGeneratorMin), float64(defaultGeneratorMax)
+	if spec.Min != nil {
+		low = *spec.Min
+	}
+	if spec.Max != nil {
+		high = *spec.Max
+	}
+	if low > high {
+		// Only one bound was set, past the other's default
+		if spec.Min != nil {
+			high = low
+		} else {
+			low = high
+		}
+	}
+	return low, high
+}
+
+// generateString builds a random string from the spec's alphabet
+func (g *inputGenerator) generateString(spec models.ArgumentSpec, length int) string {
+	alphabet := []rune(spec.Alphabet)
+	if len(alphabet) == 0 {
+		alphabet = []rune(defaultGeneratorAlphabet)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteRune(alphabet[g.rng.Intn(len(alphabet))])
+	}
+	return sb.String()
+}
+
+// generateLength picks an array or string length, capped by the scale
+func (g *inputGenerator) generateLength(spec models.ArgumentSpec, scale float64) int {
+	maxLength := spec.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultGeneratorMaxLength
+	}
+	if maxLength < spec.MinLength {
+		maxLength = spec.MinLength
+	}
+
+	limit := spec.MinLength + int(math.Ceil(float64(maxLength-spec.MinLength)*scale))
+	return spec.MinLength + g.rng.Intn(limit-spec.MinLength+1)
+}
+
+// scalarLess orders generated scalars of the same type
+func scalarLess(a, b interface{}) bool {
+	switch x := a.(type) {
+	case float64:
+		return x < b.(float64)
+	case string:
+		return x < b.(string)
+	case bool:
+		return !x && b.(bool)
+	}
+	return false
+}
+
+// inputSize measures how big a test case input is, so the smallest of several
+// failing inputs can be shown. Inputs are ranked by how many values and
+// characters they hold, then by the magnitude of their numbers.
+func inputSize(input string) (int, float64) {
+	var count int
+	var magnitude float64
+
+	var measure func(value interface{})
+	measure = func(value interface{}) {
+		switch v := value.(type) {
+		case []interface{}:
+			count++
+			for _, item := range v {
+				measure(item)
+			}
+		case string:
+			count += 1 + len(v)
+		case float64, json.Number:
+			number, _ := numberFloat(v)
+			count++
+			magnitude += math.Abs(number)
+		default:
+			count++
+		}
+	}
+	measure(parseOutputValue("[" + input + "]"))
+
+	return count, magnitude
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"stormhacks-be/models"
+)
+
+func TestNewInputGenerator(t *testing.T) {
+	low, high := 5.0, 1.0
+	tests := []struct {
+		name      string
+		arguments []models.ArgumentSpec
+		wantErr   string
+	}{
+		{name: "valid", arguments: []models.ArgumentSpec{{Type: "int[][]"}, {Type: "string"}, {Type: "TreeNode"}}},
+		{name: "no arguments", wantErr: "no arguments"},
+		{name: "unknown type", arguments: []models.ArgumentSpec{{Type: "float"}}, wantErr: "unsupported generator type: float"},
+		{name: "empty range", arguments: []models.ArgumentSpec{{Type: "int", Min: &low, Max: &high}}, wantErr: "min is greater than max"},
+		{name: "empty length range", arguments: []models.ArgumentSpec{{Type: "int[]", MinLength: 5, MaxLength: 2}}, wantErr: "minLength is greater than maxLength"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newInputGenerator(tt.arguments, 1)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("newInputGenerator() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInputGeneratorGenerate(t *testing.T) {
+	low, high, minimum := -3.0, 3.0, 1000.0
+	tests := []struct {
+		name  string
+		spec  models.ArgumentSpec
+		check func(t *testing.T, value interface{})
+	}{
+		{
+			name: "integers within bounds",
+			spec: models.ArgumentSpec{Type: "int", Min: &low, Max: &high},
+			check: func(t *testing.T, value interface{}) {
+				if number := generatedInteger(t, value); number < -3 || number > 3 {
+					t.Errorf("generated %d outside [-3, 3]", number)
+				}
+			},
+		},
+		{
+			name: "only a minimum",
+			spec: models.ArgumentSpec{Type: "long", Min: &minimum},
+			check: func(t *testing.T, value interface{}) {
+				if number := generatedInteger(t, value); number != 1000 {
+					t.Errorf("generated %d, want 1000", number)
+				}
+			},
+		},
+		{
+			name: "unique sorted array",
+			spec: models.ArgumentSpec{Type: "int[]", Min: &low, Max: &high, MinLength: 7, MaxLength: 7, Unique: true, Sorted: true},
+			check: func(t *testing.T, value interface{}) {
+				list := value.([]interface{})
+				if len(list) != 7 {
+					t.Fatalf("generated %d values, want all 7 in [-3, 3]", len(list))
+				}
+				for i := 1; i < len(list); i++ {
+					if generatedInteger(t, list[i-1]) >= generatedInteger(t, list[i]) {
+						t.Errorf("generated %v, want strictly ascending", list)
+					}
+				}
+			},
+		},
+		{
+			name: "rectangular matrix",
+			spec: models.ArgumentSpec{Type: "int[][]", MinLength: 1, MaxLength: 6, Rectangular: true},
+			check: func(t *testing.T, value interface{}) {
+				rows := value.([]interface{})
+				for _, row := range rows {
+					if len(row.([]interface{})) != len(rows[0].([]interface{})) {
+						t.Errorf("generated a ragged matrix %v", rows)
+					}
+				}
+			},
+		},
+		{
+			name: "string from an alphabet",
+			spec: models.ArgumentSpec{Type: "string", Alphabet: "ab", MinLength: 2, MaxLength: 4},
+			check: func(t *testing.T, value interface{}) {
+				text := value.(string)
+				if len(text) < 2 || len(text) > 4 || strings.Trim(text, "ab") != "" {
+					t.Errorf("generated %q, want 2 to 4 of a and b", text)
+				}
+			},
+		},
+		{
+			name: "char",
+			spec: models.ArgumentSpec{Type: "char", Alphabet: "xyz"},
+			check: func(t *testing.T, value interface{}) {
+				if text := value.(string); len(text) != 1 || !strings.Contains("xyz", text) {
+					t.Errorf("generated %q, want one of x, y and z", text)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				generator, err := newInputGenerator([]models.ArgumentSpec{tt.spec}, seed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				input, err := generator.Generate(1)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				tt.check(t, parseOutputValue(input))
+			}
+		})
+	}
+}
+
+func TestInputGeneratorNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  models.ArgumentSpec
+		check func(t *testing.T, values []interface{})
+	}{
+		{
+			name: "binary search tree",
+			spec: models.ArgumentSpec{Type: "TreeNode", MinLength: 1, MaxLength: 12, Sorted: true},
+			check: func(t *testing.T, values []interface{}) {
+				// Rebuild the tree from its level-order list and read it in order
+				left, right := map[int]int{}, map[int]int{}
+				parents := []int{0}
+				for i := 1; i < len(values); i++ {
+					children := left
+					if i%2 == 0 {
+						children = right
+					}
+					if values[i] != nil {
+						children[parents[(i-1)/2]] = i
+						parents = append(parents, i)
+					}
+				}
+				var inOrder []int64
+				var walk func(node int, exists bool)
+				walk = func(node int, exists bool) {
+					if exists {
+						child, ok := left[node]
+						walk(child, ok)
+						inOrder = append(inOrder, generatedInteger(t, values[node]))
+						child, ok = right[node]
+						walk(child, ok)
+					}
+				}
+				walk(0, true)
+				if !slices.IsSorted(inOrder) {
+					t.Errorf("generated %v, want a binary search tree", values)
+				}
+			},
+		},
+		{
+			name: "connected graph",
+			spec: models.ArgumentSpec{Type: "Node", MinLength: 1, MaxLength: 12},
+			check: func(t *testing.T, values []interface{}) {
+				neighbors, err := graphNeighbors(values)
+				if err != nil {
+					t.Fatalf("generated %v: %v", values, err)
+				}
+				reached := map[int]bool{1: true}
+				for queue := []int{1}; len(queue) > 0; queue = queue[1:] {
+					for _, neighbor := range neighbors[queue[0]-1] {
+						if !slices.Contains(neighbors[neighbor-1], queue[0]) {
+							t.Fatalf("generated %v, want undirected edges", values)
+						}
+						if !reached[neighbor] {
+							reached[neighbor] = true
+							queue = append(queue, neighbor)
+						}
+					}
+				}
+				if len(reached) != len(neighbors) {
+					t.Errorf("generated %v, want a connected graph", values)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				generator, err := newInputGenerator([]models.ArgumentSpec{tt.spec}, seed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				input, err := generator.Generate(1)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				tt.check(t, parseOutputValue(input).([]interface{}))
+			}
+		})
+	}
+}
+
+func TestInputGeneratorSeed(t *testing.T) {
+	arguments := []models.ArgumentSpec{{Type: "int[]"}, {Type: "string"}}
+	generate := func(seed int64) string {
+		generator, _ := newInputGenerator(arguments, seed)
+		input, _ := generator.Generate(1)
+		return input
+	}
+	if generate(7) != generate(7) {
+		t.Error("the same seed generated different inputs")
+	}
+	if generate(7) == generate(8) {
+		t.Error("different seeds generated the same input")
+	}
+}
+
+func TestSmallerInput(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "[1, 2]", b: "[1, 2, 3]", want: true},
+		{a: "[1, 2, 3]", b: "[1, 2]"},
+		{a: "[1, 2], 5", b: "[1, 2], -50", want: true},
+		{a: `"ab"`, b: `"abc"`, want: true},
+		{a: "[1]", b: "[1]"},
+	}
+	for _, tt := range tests {
+		if got := smallerInput(tt.a, tt.b); got != tt.want {
+			t.Errorf("smallerInput(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// generatedInteger reads a generated number that must be whole
+func generatedInteger(t *testing.T, value interface{}) int64 {
+	t.Helper()
+	integer, ok := numberInteger(value)
+	if !ok {
+		t.Fatalf("generated %v, want an integer", value)
+	}
+	return integer.Int64()
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"stormhacks-be/models"
 	"stormhacks-be/repositories"
@@ -9,22 +11,53 @@ import (
 	"stormhacks-be/types/requests"
 	"stormhacks-be/types/responses"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // InterviewService handles interview business logic
 type InterviewService struct {
-	interviewRepo *repositories.InterviewRepository
+	interviewRepo   *repositories.InterviewRepository
+	submissionQueue *SubmissionQueue
+	aiService       *LLMService // nil when no language model could be set up
 }
 
-// NewInterviewService creates a new interview service
-func NewInterviewService(interviewRepo *repositories.InterviewRepository) *InterviewService {
+// NewInterviewService creates a new interview service. Without an AI service,
+// AI features return ErrAIUnavailable and everything else still works.
+func NewInterviewService(interviewRepo *repositories.InterviewRepository, submissionQueue *SubmissionQueue, aiService *LLMService) *InterviewService {
 	return &InterviewService{
-		interviewRepo: interviewRepo,
+		interviewRepo:   interviewRepo,
+		submissionQueue: submissionQueue,
+		aiService:       aiService,
 	}
 }
 
+// AIAvailable reports whether AI features can be used
+func (s *InterviewService) AIAvailable() bool {
+	return s.aiService != nil
+}
+
+// ai returns the AI service, or ErrAIUnavailable without a language model
+func (s *InterviewService) ai() (*LLMService, error) {
+	if s.aiService == nil {
+		return nil, ErrAIUnavailable
+	}
+	return s.aiService, nil
+}
+
+// ErrSessionNotFound is returned for unknown interview session IDs
+var ErrSessionNotFound = errors.New("session not found")
+
+// getSession retrieves an interview session, returning ErrSessionNotFound if there is none
+func getSession(interviewRepo *repositories.InterviewRepository, sessionID string) (*models.InterviewSession, error) {
+	session, err := interviewRepo.GetBySessionID(sessionID)
+	if err != nil && err.Error() == "not found" {
+		return nil, ErrSessionNotFound
+	}
+	return session, err
+}
+
 func (s *InterviewService) GetInterviewSession(sessionID string) (*models.InterviewSession, error) {
 	return s.interviewRepo.GetBySessionID(sessionID)
 }
@@ -77,9 +110,9 @@ func (s *InterviewService) CreateInterviewSession(input requests.InterviewSessio
 }
 
 // GenerateInterviewQuestions generates interview questions based on the session
-func (s *InterviewService) GenerateInterviewQuestions(sessionID string) (*responses.InterviewSessionQuestionsResponse, error) {
+func (s *InterviewService) GenerateInterviewQuestions(ctx context.Context, sessionID string) (*responses.InterviewSessionQuestionsResponse, error) {
 	// Get the session first
-	session, err := s.interviewRepo.GetBySessionID(sessionID)
+	session, err := getSession(s.interviewRepo, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +129,18 @@ func (s *InterviewService) GenerateInterviewQuestions(sessionID string) (*respon
 		return nil, err
 	}
 
-	// Use Gemini to customize questions based on job description and resume
-	googleGeminiService := NewGoogleGeminiService()
-	customizedQuestions, err := googleGeminiService.CustomizeInterviewQuestions(session, questions)
+	// Use AI to customize questions based on job description and resume
+	var customizedQuestions []CustomizedQuestion
+	var promptVersion string
+	aiService, err := s.ai()
+	if err == nil {
+		customizedQuestions, promptVersion, err = aiService.CustomizeInterviewQuestions(ctx, session, questions)
+	}
 	if err != nil {
-		// If Gemini fails, fall back to original questions
-		log.Printf("Warning: Failed to customize questions with Gemini: %v. Using original questions.", err)
+		// If AI fails, fall back to original questions
+		log.Printf("Warning: Failed to customize questions: %v. Using original questions.", err)
+		customizedQuestions = uncustomizedQuestions(questions)
+		promptVersion = ""
 	}
 
 	// Convert to response format
@@ -116,8 +155,9 @@ func (s *InterviewService) GenerateInterviewQuestions(sessionID string) (*respon
 	}
 
 	return &responses.InterviewSessionQuestionsResponse{
-		SessionID: session.SessionID,
-		Questions: responseQuestions,
+		SessionID:     session.SessionID,
+		Questions:     responseQuestions,
+		PromptVersion: promptVersion,
 	}, nil
 }
 
@@ -141,6 +181,20 @@ func (s *InterviewService) GetQuestionsByTopic(topic string) ([]models.QuestionB
 	return s.interviewRepo.GetQuestionsByBehavioralTopic(topic)
 }
 
+// uncustomizedQuestions returns questions from the bank as they are, with hints for their topics
+func uncustomizedQuestions(questions []models.QuestionBank) []CustomizedQuestion {
+	customizedQuestions := make([]CustomizedQuestion, len(questions))
+	for i, q := range questions {
+		customizedQuestions[i] = CustomizedQuestion{
+			ID:              q.ID.Hex(),
+			Question:        q.Question,
+			BehavioralTopic: enums.BehaviouralTopic(q.BehavioralTopic),
+			Hints:           generateHintsForTopic(enums.BehaviouralTopic(q.BehavioralTopic)),
+		}
+	}
+	return customizedQuestions
+}
+
 // generateHintsForTopic generates appropriate hints based on the behavioral topic
 func generateHintsForTopic(topic enums.BehaviouralTopic) []string {
 	switch topic {
@@ -168,18 +222,21 @@ func generateHintsForTopic(topic enums.BehaviouralTopic) []string {
 }
 
 
-func (s *InterviewService) GenerateInterviewFeedback(input requests.InterviewFeedbackInput) (*responses.InterviewFeedbackResponse, error) {
+func (s *InterviewService) GenerateInterviewFeedback(ctx context.Context, input requests.InterviewFeedbackInput) (*responses.InterviewFeedbackResponse, error) {
 	existingSession, err := s.interviewRepo.GetBySessionID(input.SessionID)
 	if err != nil && err.Error() != "not found" {
 		return nil, err
 	}
 	if existingSession == nil {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 	
-	// Create Gemini service and generate feedback
-	googleGeminiService := NewGoogleGeminiService()
-	feedbackResponse, err := googleGeminiService.GenerateInterviewFeedback(existingSession, input.InterviewQuestionsWithAnswers)
+	// Generate feedback with AI
+	aiService, err := s.ai()
+	if err != nil {
+		return nil, err
+	}
+	feedbackResponse, err := aiService.GenerateInterviewFeedback(ctx, existingSession, input.InterviewQuestionsWithAnswers)
 	if err != nil {
 		return nil, err
 	}
@@ -208,35 +265,77 @@ func (s *InterviewService) GetTechnicalQuestion(difficulty string) (*models.Tech
 		return nil, err
 	}
 
+	// Hidden test cases are only used when judging submissions
+	question.Question.TestCases = question.Question.SampleTestCases()
+
 	return question, nil
 }
 
-// ExecuteCode executes submitted code and validates against test cases
+// ExecuteCode executes submitted code and validates against test cases, waiting
+// for a free worker in the submission queue
 func (s *InterviewService) ExecuteCode(input requests.ExecuteTechnicalInput) (*responses.ExecuteTechnicalResponse, error) {
-	return ExecuteCode(input, s.interviewRepo)
+	return s.submissionQueue.Execute(input)
 }
 
-// GenerateHint generates hints for a user's response to an interview question
-func (s *InterviewService) GenerateHint(input requests.HintRequest) (*responses.HintResponse, error) {
-	// Validate session exists
-	_, err := s.interviewRepo.GetBySessionID(input.SessionID)
-	if err != nil {
+// QueueSubmission queues submitted code for execution and returns its submission ID straight away
+func (s *InterviewService) QueueSubmission(input requests.ExecuteTechnicalInput) (*responses.SubmissionResponse, error) {
+	return s.submissionQueue.Enqueue(input)
+}
+
+// GetSubmission returns the status, and once finished the result, of a queued submission
+func (s *InterviewService) GetSubmission(submissionID string) (*responses.SubmissionResponse, error) {
+	return s.submissionQueue.Get(submissionID)
+}
+
+// WatchSubmission streams the status of a queued submission until it finishes
+func (s *InterviewService) WatchSubmission(submissionID string) (<-chan responses.SubmissionResponse, func(), error) {
+	return s.submissionQueue.Subscribe(submissionID)
+}
+
+// ExecuteCustom runs submitted code against a candidate-supplied input,
+// waiting for a free worker in the submission queue
+func (s *InterviewService) ExecuteCustom(input requests.ExecuteCustomInput) (*responses.ExecuteCustomResponse, error) {
+	return s.submissionQueue.ExecuteCustom(input)
+}
+
+// GetSubmissionHistory returns a session's submissions, oldest first, optionally only those for one question
+func (s *InterviewService) GetSubmissionHistory(sessionID string, questionID string) (*responses.SubmissionHistoryResponse, error) {
+	if _, err := getSession(s.interviewRepo, sessionID); err != nil {
 		return nil, err
 	}
 
-	// Get the technical question by ID
-	question, err := s.interviewRepo.GetTechnicalQuestionByID(input.QuestionID)
+	submissions, err := s.interviewRepo.GetSubmissionsBySession(sessionID, questionID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create Gemini service and generate hints with full question context
-	googleGeminiService := NewGoogleGeminiService()
-	
-	// Combine question and description for better context
-	fullQuestion := question.Question.Question + "\n\nDescription: " + question.Question.Description
+	return &responses.SubmissionHistoryResponse{
+		SessionID:   sessionID,
+		QuestionID:  questionID,
+		Submissions: submissions,
+	}, nil
+}
+
+// RecordEditorSnapshot stores a snapshot of the candidate's code for a question
+func (s *InterviewService) RecordEditorSnapshot(input requests.EditorSnapshotInput) (*responses.EditorSnapshotResponse, error) {
+	return RecordEditorSnapshot(input, s.interviewRepo)
+}
 
-	hintResponse, err := googleGeminiService.GenerateHint(
+// GetReplay reconstructs a question's coding session, up to the given time if at is set
+func (s *InterviewService) GetReplay(sessionID string, questionID string, at *time.Time) (*responses.ReplayResponse, error) {
+	return BuildReplay(sessionID, questionID, at, s.interviewRepo)
+}
+
+// GenerateHint generates hints for a user's response to an interview question
+func (s *InterviewService) GenerateHint(ctx context.Context, input requests.HintRequest) (*responses.HintResponse, error) {
+	aiService, fullQuestion, err := s.prepareHint(input)
+	if err != nil {
+		return nil, err
+	}
+
+	hintResponse, err := aiService.GenerateHint(
+		ctx,
+		input.SessionID,
 		fullQuestion, 
 		input.UserCode, 
 		input.UserSpeech, 
@@ -246,16 +345,74 @@ func (s *InterviewService) GenerateHint(input requests.HintRequest) (*responses.
 		return nil, err
 	}
 
-	// Set the session ID in the response
-	hintResponse.SessionID = input.SessionID
+	s.finishHint(input, hintResponse)
+	return hintResponse, nil
+}
+
+// StreamHint generates a hint like GenerateHint, passing the conversational
+// hint to onHint piece by piece as the model writes it
+func (s *InterviewService) StreamHint(ctx context.Context, input requests.HintRequest, onHint func(string)) (*responses.HintResponse, error) {
+	aiService, fullQuestion, err := s.prepareHint(input)
+	if err != nil {
+		return nil, err
+	}
 
+	hintResponse, err := aiService.StreamHint(
+		ctx,
+		input.SessionID,
+		fullQuestion,
+		input.UserCode,
+		input.UserSpeech,
+		input.PreviousHints,
+		onHint,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.finishHint(input, hintResponse)
 	return hintResponse, nil
 }
 
+// prepareHint checks a hint request and returns the AI service and the question to hint at
+func (s *InterviewService) prepareHint(input requests.HintRequest) (*LLMService, string, error) {
+	// Validate session exists
+	_, err := getSession(s.interviewRepo, input.SessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Get the technical question by ID
+	question, err := s.interviewRepo.GetTechnicalQuestionByID(input.QuestionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Generate hints with AI, with full question context
+	aiService, err := s.ai()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Combine question and description for better context
+	fullQuestion := question.Question.Question + "\n\nDescription: " + question.Question.Description
+
+	return aiService, fullQuestion, nil
+}
+
+// finishHint sets the session ID on a generated hint and counts it towards the technical feedback
+func (s *InterviewService) finishHint(input requests.HintRequest, hintResponse *responses.HintResponse) {
+	hintResponse.SessionID = input.SessionID
+
+	if err := s.interviewRepo.AddHintUsage(input.SessionID, input.QuestionID, hintResponse.PromptVersion); err != nil {
+		log.Printf("Warning: Failed to record hint usage for session %s: %v", input.SessionID, err)
+	}
+}
+
 // GenerateTechnicalFeedback generates feedback for technical question performance
-func (s *InterviewService) GenerateTechnicalFeedback(input requests.TechnicalFeedbackInput) (*responses.TechnicalFeedbackResponse, error) {
+func (s *InterviewService) GenerateTechnicalFeedback(ctx context.Context, input requests.TechnicalFeedbackInput) (*responses.TechnicalFeedbackResponse, error) {
 	// Validate session exists and get session info
-	session, err := s.interviewRepo.GetBySessionID(input.SessionID)
+	session, err := getSession(s.interviewRepo, input.SessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -266,9 +423,29 @@ func (s *InterviewService) GenerateTechnicalFeedback(input requests.TechnicalFee
 		return nil, err
 	}
 
-	// Create Gemini service and generate feedback
-	googleGeminiService := NewGoogleGeminiService()
-	
+	// Judge the candidate on the recorded submissions and hints rather than what
+	// the client reports. Without any submissions for the question only the
+	// client's values are left, and the feedback is marked unverified.
+	history, err := s.interviewRepo.GetSubmissionsBySession(input.SessionID, input.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission history: %w", err)
+	}
+	verified := len(history) > 0
+	userCode, isCompleted, hintsUsed := input.UserCode, input.IsCompleted, input.HintsUsed
+	if verified {
+		userCode = history[len(history)-1].Code
+		isCompleted = solvedInHistory(history)
+		hintsUsed = countHintUsages(session.HintUsages, input.QuestionID)
+	} else if hints := countHintUsages(session.HintUsages, input.QuestionID); hints > hintsUsed {
+		// Never report fewer hints than were given through the API
+		hintsUsed = hints
+	}
+
+	aiService, err := s.ai()
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare question info for the prompt including job context
 	companyName := ""
 	if session.CompanyName != nil {
@@ -283,13 +460,17 @@ func (s *InterviewService) GenerateTechnicalFeedback(input requests.TechnicalFee
 		"companyName": companyName,
 	}
 
-	// Generate feedback using Gemini
-	feedbackResponse, err := googleGeminiService.GenerateTechnicalFeedback(
+	// Generate feedback using AI
+	feedbackResponse, err := aiService.GenerateTechnicalFeedback(
+		ctx,
+		input.SessionID,
 		questionInfo,
-		input.UserCode,
-		input.HintsUsed,
-		input.IsCompleted,
+		userCode,
+		hintsUsed,
+		isCompleted,
 		input.TimeTaken,
+		describeComplexity(latestComplexity(history)),
+		describeSubmissionHistory(history),
 	)
 	if err != nil {
 		return nil, err
@@ -297,6 +478,7 @@ func (s *InterviewService) GenerateTechnicalFeedback(input requests.TechnicalFee
 
 	// Set the session ID in the response
 	feedbackResponse.SessionID = input.SessionID
+	feedbackResponse.Verified = verified
 
 	return feedbackResponse, nil
 }
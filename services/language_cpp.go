@@ -0,0 +1,422 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// cppDriver runs C++17 solutions written as a method on a Solution class,
+// or as the question's own class for design questions
+type cppDriver struct{}
+
+func (d *cppDriver) Language() enums.CodingLanguage { return enums.CodingLanguageCpp }
+func (d *cppDriver) RunnerLanguage() string         { return "c++" }
+func (d *cppDriver) Version() string                { return "10.2.0" }
+func (d *cppDriver) SourceFile() string             { return "main.cpp" }
+func (d *cppDriver) LocalCompileCommand() string    { return "g++ -std=c++17 -O2 -o main main.cpp" }
+func (d *cppDriver) RequiresParameterTypes() bool   { return true }
+func (d *cppDriver) LocalMemoryOverheadMB() int     { return 0 }
+
+func (d *cppDriver) LocalRunCommand(memoryLimitMB int) string {
+	return "./main"
+}
+
+// cppLiteralSyntax spells typed literals in C++
+var cppLiteralSyntax = typedLiteralSyntax{
+	typeName: cppTypeName,
+	scalar: func(baseType string, value interface{}) (string, error) {
+		switch baseType {
+		case "int":
+			return integerLiteral(value)
+		case "long":
+			literal, err := integerLiteral(value)
+			if err != nil {
+				return "", err
+			}
+			return literal + "LL", nil
+		case "double":
+			return floatLiteral(value)
+		case "bool":
+			return boolLiteral(value)
+		case "string":
+			// Wrapped so auto-typed arguments are std::string rather than const char*
+			literal, err := cStringLiteral(value)
+			if err != nil {
+				return "", err
+			}
+			return "string(" + literal + ")", nil
+		case "char":
+			return cCharLiteral(value)
+		case listNodeType:
+			values, err := nodeValueLiterals(value, baseType, "nullopt")
+			if err != nil {
+				return "", err
+			}
+			return "harness::buildList(vector<int>{" + strings.Join(values, ", ") + "})", nil
+		case treeNodeType:
+			values, err := nodeValueLiterals(value, baseType, "nullopt")
+			if err != nil {
+				return "", err
+			}
+			return "harness::buildTree(vector<optional<int>>{" + strings.Join(values, ", ") + "})", nil
+		case graphNodeType:
+			neighbors, err := graphNeighborLiterals(value)
+			if err != nil {
+				return "", err
+			}
+			return "harness::buildGraph(vector<vector<int>>{" + strings.Join(neighbors, ", ") + "})", nil
+		default:
+			return "", fmt.Errorf("unsupported parameter type for cpp: %s", baseType)
+		}
+	},
+	array: func(arrayType string, elements []string) (string, error) {
+		typeName, err := cppTypeName(arrayType)
+		if err != nil {
+			return "", err
+		}
+		return typeName + "{" + strings.Join(elements, ", ") + "}", nil
+	},
+}
+
+// cppTypeName spells a parameter type in C++, with arrays as vectors
+func cppTypeName(valueType string) (string, error) {
+	baseType, depth := splitValueType(valueType)
+	names := map[string]string{
+		"int":    "int",
+		"long":   "long long",
+		"double": "double",
+		"bool":   "bool",
+		"string": "string",
+		"char":   "char",
+
+		listNodeType: listNodeType + "*",
+		treeNodeType: treeNodeType + "*",
+
+		graphNodeType: graphNodeType + "*",
+	}
+	name, exists := names[baseType]
+	if !exists {
+		return "", fmt.Errorf("unsupported parameter type for cpp: %s", baseType)
+	}
+	return strings.Repeat("vector<", depth) + name + strings.Repeat(">", depth), nil
+}
+
+func (d *cppDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	return formatTypedLiteral(cppLiteralSyntax, value, valueType)
+}
+
+func (d *cppDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	return renderHarness(cppHarnessTemplate, spec)
+}
+
+// cppHarnessTemplate binds every argument to a local first so solutions taking
+// non-const references (e.g. vector<int>& nums) can be called. Design questions
+// serialize each call's result as it returns and join them into one list.
+var cppHarnessTemplate = template.Must(template.New("cpp").Funcs(harnessTemplateFuncs).Parse(`#include <bits/stdc++.h>
+using namespace std;
+{{- if .ListNode}}
+
+struct ListNode {
+    int val;
+    ListNode *next;
+    ListNode() : val(0), next(nullptr) {}
+    ListNode(int x) : val(x), next(nullptr) {}
+    ListNode(int x, ListNode *next) : val(x), next(next) {}
+};
+{{- end}}
+{{- if .TreeNode}}
+
+struct TreeNode {
+    int val;
+    TreeNode *left;
+    TreeNode *right;
+    TreeNode() : val(0), left(nullptr), right(nullptr) {}
+    TreeNode(int x) : val(x), left(nullptr), right(nullptr) {}
+    TreeNode(int x, TreeNode *left, TreeNode *right) : val(x), left(left), right(right) {}
+};
+{{- end}}
+{{- if .GraphNode}}
+
+class Node {
+public:
+    int val;
+    vector<Node*> neighbors;
+    Node() : val(0) {}
+    Node(int _val) : val(_val) {}
+    Node(int _val, vector<Node*> _neighbors) : val(_val), neighbors(_neighbors) {}
+};
+{{- end}}
+
+{{.Code}}
+
+namespace harness {
+
+double elapsedMs = 0;
+
+string quote(const string& text);
+string toJson(const string& value);
+string toJson(const char* value);
+string toJson(char value);
+string toJson(bool value);
+template <typename T> typename enable_if<is_integral<T>::value, string>::type toJson(T value);
+template <typename T> typename enable_if<is_floating_point<T>::value, string>::type toJson(T value);
+template <typename T> string toJson(const vector<T>& values);
+template <typename T> string toJson(const set<T>& values);
+template <typename T> string toJson(const unordered_set<T>& values);
+template <typename A, typename B> string toJson(const pair<A, B>& value);
+template <typename K, typename V> string toJson(const map<K, V>& values);
+template <typename K, typename V> string toJson(const unordered_map<K, V>& values);
+{{- if .ListNode}}
+string toJson(ListNode* head);
+{{- end}}
+{{- if .TreeNode}}
+string toJson(TreeNode* root);
+{{- end}}
+{{- if .GraphNode}}
+string toJson(Node* start);
+{{- end}}
+
+string quote(const string& text) {
+    string out = "\"";
+    for (unsigned char c : text) {
+        switch (c) {
+            case '"': out += "\\\""; break;
+            case '\\': out += "\\\\"; break;
+            case '\n': out += "\\n"; break;
+            case '\r': out += "\\r"; break;
+            case '\t': out += "\\t"; break;
+            default:
+                if (c < 0x20) {
+                    char buf[8];
+                    snprintf(buf, sizeof(buf), "\\u%04x", c);
+                    out += buf;
+                } else {
+                    out += (char) c;
+                }
+        }
+    }
+    return out + "\"";
+}
+
+string toJson(const string& value) { return quote(value); }
+string toJson(const char* value) { return quote(value); }
+string toJson(char value) { return quote(string(1, value)); }
+string toJson(bool value) { return value ? "true" : "false"; }
+
+template <typename T> typename enable_if<is_integral<T>::value, string>::type toJson(T value) {
+    return to_string(value);
+}
+
+template <typename T> typename enable_if<is_floating_point<T>::value, string>::type toJson(T value) {
+    if (isnan(value)) return "NaN";
+    if (isinf(value)) return value > 0 ? "Infinity" : "-Infinity";
+    char buf[32];
+    snprintf(buf, sizeof(buf), "%.17g", (double) value);
+    return buf;
+}
+
+template <typename Iterable> string listToJson(const Iterable& values) {
+    string out = "[";
+    bool first = true;
+    for (const auto& item : values) {
+        if (!first) out += ",";
+        first = false;
+        out += toJson(item);
+    }
+    return out + "]";
+}
+
+template <typename Map> string mapToJson(const Map& values) {
+    string out = "{";
+    bool first = true;
+    for (const auto& entry : values) {
+        if (!first) out += ",";
+        first = false;
+        string key = toJson(entry.first);
+        if (key.empty() || key[0] != '"') key = quote(key);
+        out += key + ":" + toJson(entry.second);
+    }
+    return out + "}";
+}
+
+template <typename T> string toJson(const vector<T>& values) { return listToJson(values); }
+template <typename T> string toJson(const set<T>& values) { return listToJson(values); }
+template <typename T> string toJson(const unordered_set<T>& values) { return listToJson(values); }
+template <typename A, typename B> string toJson(const pair<A, B>& value) {
+    return "[" + toJson(value.first) + "," + toJson(value.second) + "]";
+}
+template <typename K, typename V> string toJson(const map<K, V>& values) { return mapToJson(values); }
+template <typename K, typename V> string toJson(const unordered_map<K, V>& values) { return mapToJson(values); }
+
+
+string jsonList(const vector<string>& serialized) {
+    string out = "[";
+    for (size_t i = 0; i < serialized.size(); i++) {
+        if (i > 0) out += ",";
+        out += serialized[i];
+    }
+    return out + "]";
+}
+{{- if .ListNode}}
+
+ListNode* buildList(const vector<int>& values) {
+    ListNode* head = nullptr;
+    for (auto it = values.rbegin(); it != values.rend(); ++it) {
+        head = new ListNode(*it, head);
+    }
+    return head;
+}
+
+string toJson(ListNode* head) {
+    vector<int> values;
+    unordered_set<ListNode*> seen;
+    for (ListNode* node = head; node != nullptr; node = node->next) {
+        if (!seen.insert(node).second) throw runtime_error("linked list contains a cycle");
+        values.push_back(node->val);
+    }
+    return toJson(values);
+}
+{{- end}}
+{{- if .TreeNode}}
+
+TreeNode* buildTree(const vector<optional<int>>& values) {
+    if (values.empty() || !values[0]) return nullptr;
+    TreeNode* root = new TreeNode(*values[0]);
+    vector<TreeNode*> queue = {root};
+    for (size_t head = 0, i = 1; head < queue.size() && i < values.size(); head++, i += 2) {
+        TreeNode* node = queue[head];
+        if (values[i]) {
+            node->left = new TreeNode(*values[i]);
+            queue.push_back(node->left);
+        }
+        if (i + 1 < values.size() && values[i + 1]) {
+            node->right = new TreeNode(*values[i + 1]);
+            queue.push_back(node->right);
+        }
+    }
+    return root;
+}
+
+string toJson(TreeNode* root) {
+    vector<string> values;
+    vector<TreeNode*> queue = {root};
+    for (size_t head = 0; head < queue.size(); head++) {
+        TreeNode* node = queue[head];
+        if (node == nullptr) {
+            values.push_back("null");
+            continue;
+        }
+        values.push_back(to_string(node->val));
+        queue.push_back(node->left);
+        queue.push_back(node->right);
+    }
+    while (!values.empty() && values.back() == "null") values.pop_back();
+    return jsonList(values);
+}
+{{- end}}
+{{- if .GraphNode}}
+
+Node* buildGraph(const vector<vector<int>>& adjacency) {
+    vector<Node*> nodes;
+    for (size_t i = 0; i < adjacency.size(); i++) {
+        nodes.push_back(new Node((int) i + 1));
+    }
+    for (size_t i = 0; i < adjacency.size(); i++) {
+        for (int value : adjacency[i]) {
+            nodes[i]->neighbors.push_back(nodes[value - 1]);
+        }
+    }
+    return nodes.empty() ? nullptr : nodes[0];
+}
+
+string toJson(Node* start) {
+    if (start == nullptr) return "[]";
+    vector<Node*> nodes = {start};
+    unordered_set<Node*> seen = {start};
+    for (size_t head = 0; head < nodes.size(); head++) {
+        for (Node* neighbor : nodes[head]->neighbors) {
+            if (seen.insert(neighbor).second) nodes.push_back(neighbor);
+        }
+    }
+    unordered_map<int, Node*> byValue;
+    for (Node* node : nodes) byValue[node->val] = node;
+    vector<vector<int>> values;
+    for (int value = 1; value <= (int) nodes.size(); value++) {
+        auto found = byValue.find(value);
+        if (found == byValue.end()) throw runtime_error("graph node values must run from 1 to the number of nodes");
+        vector<int> neighbors;
+        for (Node* neighbor : found->second->neighbors) neighbors.push_back(neighbor->val);
+        values.push_back(neighbors);
+    }
+    return toJson(values);
+}
+{{- end}}
+
+}  // namespace harness
+
+int main() {
+    vector<function<string()>> cases = {
+{{- range .Cases}}
+        []() -> string {
+{{- range $i, $arg := .}}
+            auto a{{$i}} = {{$arg}};
+{{- end}}
+            Solution solution;
+            auto start = chrono::steady_clock::now();
+            auto result = solution.{{$.FunctionName}}({{range $i, $arg := .}}{{if $i}}, {{end}}a{{$i}}{{end}});
+            harness::elapsedMs = chrono::duration<double, milli>(chrono::steady_clock::now() - start).count();
+            return harness::toJson(result);
+        },
+{{- end}}
+{{- range .Calls}}
+        []() -> string {
+{{- range $c, $call := .}}{{range $i, $arg := $call.Args}}
+            auto a{{$c}}_{{$i}} = {{$arg}};
+{{- end}}{{end}}
+            vector<string> results;
+            auto start = chrono::steady_clock::now();
+            auto instance = make_unique<{{$.ClassName}}>({{range $i, $arg := (index . 0).Args}}{{if $i}}, {{end}}a0_{{$i}}{{end}});
+            results.push_back("null");
+{{- range $c, $call := .}}{{if $c}}
+{{- if $call.Void}}
+            instance->{{$call.Method}}({{range $i, $arg := $call.Args}}{{if $i}}, {{end}}a{{$c}}_{{$i}}{{end}});
+            results.push_back("null");
+{{- else}}
+            results.push_back(harness::toJson(instance->{{$call.Method}}({{range $i, $arg := $call.Args}}{{if $i}}, {{end}}a{{$c}}_{{$i}}{{end}})));
+{{- end}}{{end}}{{end}}
+            harness::elapsedMs = chrono::duration<double, milli>(chrono::steady_clock::now() - start).count();
+            return harness::jsonList(results);
+        },
+{{- end}}
+    };
+
+    for (size_t i = 0; i < cases.size(); i++) {
+        cout << "@@{{.Nonce}} BEGIN " << i << "@@" << endl;
+        harness::elapsedMs = 0;
+        char timing[64];
+        string record;
+        try {
+            string serialized = cases[i]();
+            snprintf(timing, sizeof(timing), "%.3f", harness::elapsedMs);
+            record = string("{\"status\":\"ok\",\"time\":") + timing + ",\"result\":" + harness::quote(serialized) + "}";
+        } catch (const bad_alloc& e) {
+            snprintf(timing, sizeof(timing), "%.3f", harness::elapsedMs);
+            record = string("{\"status\":\"error\",\"time\":") + timing
+                + ",\"error\":" + harness::quote(e.what()) + ",\"errorType\":\"bad_alloc\"}";
+        } catch (const exception& e) {
+            snprintf(timing, sizeof(timing), "%.3f", harness::elapsedMs);
+            record = string("{\"status\":\"error\",\"time\":") + timing
+                + ",\"error\":" + harness::quote(e.what()) + ",\"errorType\":\"exception\"}";
+        } catch (...) {
+            snprintf(timing, sizeof(timing), "%.3f", harness::elapsedMs);
+            record = string("{\"status\":\"error\",\"time\":") + timing
+                + ",\"error\":\"unknown exception\",\"errorType\":\"exception\"}";
+        }
+        cout << "\n@@{{.Nonce}} END " << i << " " << record << "@@" << endl;
+    }
+    return 0;
+}
+`))
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+// LanguageDriver knows how to build, run and talk to programs in one language
+type LanguageDriver interface {
+	// Language is the value clients send to select this driver
+	Language() enums.CodingLanguage
+
+	// RunnerLanguage and Version identify the runtime on Piston
+	RunnerLanguage() string
+	Version() string
+
+	// SourceFile is the file name the entry program is written to
+	SourceFile() string
+
+	// LocalCompileCommand is the shell command the local runner compiles with,
+	// or empty for interpreted languages
+	LocalCompileCommand() string
+
+	// LocalRunCommand is the shell command the local runner starts the program with
+	LocalRunCommand(memoryLimitMB int) string
+
+	// LocalMemoryOverheadMB is how much memory the runtime itself needs on top of the
+	// program's limit; runtimes with their own heap limit enforce the limit themselves
+	LocalMemoryOverheadMB() int
+
+	// FormatLiteral spells a parsed test input value as a literal of valueType
+	FormatLiteral(value interface{}, valueType string) (string, error)
+
+	// BuildHarness wraps candidate code in a program that calls the function once
+	// per case, or makes a design question's calls on a fresh instance per case,
+	// and prints each serialized result between nonce-tagged markers
+	BuildHarness(spec HarnessSpec) (string, error)
+
+	// RequiresParameterTypes reports whether FormatLiteral needs declared parameter types
+	RequiresParameterTypes() bool
+}
+
+// HarnessSpec is everything a driver needs to generate a harness program.
+// Function questions fill FunctionName and Cases; design questions fill
+// ClassName and Calls, and their result is the list of every call's result.
+type HarnessSpec struct {
+	Code         string
+	FunctionName string
+	ClassName    string
+	Nonce        string
+	Cases        [][]string      // formatted argument literals, one slice per test case
+	Calls        [][]HarnessCall // the constructor call then the method calls, one slice per test case
+	ListNode     bool            // the question uses ListNode, so the harness defines it
+	TreeNode     bool            // the question uses TreeNode, so the harness defines it
+	GraphNode    bool            // the question uses Node, so the harness defines it
+}
+
+// HarnessCall is one call a design question's test case makes
+type HarnessCall struct {
+	Method string   // the method name, or the class name for the constructor
+	Args   []string // formatted argument literals
+	Void   bool     // the method returns nothing, so its result is null
+}
+
+// languageDrivers is the registry of supported languages
+var languageDrivers = map[enums.CodingLanguage]LanguageDriver{
+	enums.CodingLanguagePython:     &pythonDriver{},
+	enums.CodingLanguageJavaScript: &javaScriptDriver{},
+	enums.CodingLanguageTypeScript: &typeScriptDriver{},
+	enums.CodingLanguageGo:         &goDriver{},
+	enums.CodingLanguageJava:       &javaDriver{},
+	enums.CodingLanguageCpp:        &cppDriver{},
+}
+
+// GetLanguageDriver returns the driver registered for a language
+func GetLanguageDriver(language string) (LanguageDriver, error) {
+	driver, exists := languageDrivers[enums.CodingLanguage(strings.ToLower(language))]
+	if !exists {
+		return nil, fmt.Errorf("unsupported language: %s. Allowed languages: %s", language, supportedLanguagesList())
+	}
+	return driver, nil
+}
+
+// supportedLanguagesList returns the registered languages as a comma separated list
+func supportedLanguagesList() string {
+	var names []string
+	for _, language := range enums.GetAllCodingLanguages() {
+		names = append(names, string(language))
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatTestCaseArguments parses a test case input into one literal per parameter
+func formatTestCaseArguments(driver LanguageDriver, input string, parameters []models.Parameter) ([]string, error) {
+	parsed, ok := parseOutputValue("[" + input + "]").([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("test case input is not a list of literal arguments: %s", input)
+	}
+
+	if driver.RequiresParameterTypes() && len(parameters) == 0 {
+		return nil, fmt.Errorf("question does not declare parameter types, which %s requires", driver.Language())
+	}
+	return formatArguments(driver, parsed, parameters)
+}
+
+// formatArguments formats parsed argument values as literals of the declared parameter types
+func formatArguments(driver LanguageDriver, values []interface{}, parameters []models.Parameter) ([]string, error) {
+	if driver.RequiresParameterTypes() && len(parameters) != len(values) {
+		return nil, fmt.Errorf("test case input has %d arguments but the question declares %d parameters", len(values), len(parameters))
+	}
+
+	args := make([]string, len(values))
+	for i, value := range values {
+		valueType := ""
+		if i < len(parameters) {
+			valueType = parameters[i].Type
+		}
+
+		literal, err := driver.FormatLiteral(value, valueType)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		args[i] = literal
+	}
+	return args, nil
+}
+
+// splitValueType splits a parameter type such as "int[][]" into its element type and array depth
+func splitValueType(valueType string) (string, int) {
+	depth := 0
+	for strings.HasSuffix(valueType, "[]") {
+		valueType = strings.TrimSuffix(valueType, "[]")
+		depth++
+	}
+	return valueType, depth
+}
+
+// typedLiteralSyntax describes how a statically typed language spells literals
+type typedLiteralSyntax struct {
+	// typeName spells a parameter type such as "int[]" in the target language
+	typeName func(valueType string) (string, error)
+	// scalar spells a non-array value of the given base type
+	scalar func(baseType string, value interface{}) (string, error)
+	// array spells an array literal of arrayType from its formatted elements
+	array func(arrayType string, elements []string) (string, error)
+}
+
+// formatTypedLiteral formats a parsed value as a literal of valueType
+func formatTypedLiteral(syntax typedLiteralSyntax, value interface{}, valueType string) (string, error) {
+	baseType, depth := splitValueType(valueType)
+	if depth == 0 {
+		return syntax.scalar(baseType, value)
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("expected a list for type %s, got %v", valueType, value)
+	}
+
+	elementType := strings.TrimSuffix(valueType, "[]")
+	elements := make([]string, len(list))
+	for i, item := range list {
+		element, err := formatTypedLiteral(syntax, item, elementType)
+		if err != nil {
+			return "", err
+		}
+		elements[i] = element
+	}
+	return syntax.array(valueType, elements)
+}
+
+// integerLiteral formats a parsed number that must be whole. Integers are
+// written from their original text, so values beyond float64 precision survive.
+func integerLiteral(value interface{}) (string, error) {
+	if integer, ok := numberInteger(value); ok {
+		return integer.String(), nil
+	}
+	number, ok := numberFloat(value)
+	if !ok || number != math.Trunc(number) || math.IsInf(number, 0) {
+		return "", fmt.Errorf("expected an integer, got %v", value)
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64), nil
+}
+
+// floatLiteral formats a parsed number so it always reads as floating point
+func floatLiteral(value interface{}) (string, error) {
+	number, ok := numberFloat(value)
+	if !ok || math.IsInf(number, 0) || math.IsNaN(number) {
+		return "", fmt.Errorf("expected a finite number, got %v", value)
+	}
+	literal := strconv.FormatFloat(number, 'g', -1, 64)
+	if !strings.ContainsAny(literal, ".e") {
+		literal += ".0"
+	}
+	return literal, nil
+}
+
+// boolLiteral formats a parsed boolean in lower case
+func boolLiteral(value interface{}) (string, error) {
+	boolean, ok := value.(bool)
+	if !ok {
+		return "", fmt.Errorf("expected a boolean, got %v", value)
+	}
+	return strconv.FormatBool(boolean), nil
+}
+
+// cStringLiteral formats a string using the escapes C, C++ and Java share
+func cStringLiteral(value interface{}) (string, error) {
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %v", value)
+	}
+	return `"` + escapeCString(text, '"') + `"`, nil
+}
+
+// cCharLiteral formats a one-character string as a char literal
+func cCharLiteral(value interface{}) (string, error) {
+	text, ok := value.(string)
+	if !ok || len([]rune(text)) != 1 {
+		return "", fmt.Errorf("expected a single character, got %v", value)
+	}
+	return "'" + escapeCString(text, '\'') + "'", nil
+}
+
+// escapeCString escapes backslashes, the quote character and control characters
+func escapeCString(text string, quote rune) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == quote:
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20:
+			fmt.Fprintf(&sb, `\%03o`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isMemoryErrorType reports whether a harness error type means the program ran out of memory
+func isMemoryErrorType(errorType string) bool {
+	switch errorType {
+	case "MemoryError", "OutOfMemoryError", "bad_alloc":
+		return true
+	}
+	return false
+}
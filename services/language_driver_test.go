@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestFormatTestCaseArguments(t *testing.T) {
+	parameters := func(types ...string) []models.Parameter {
+		params := make([]models.Parameter, len(types))
+		for i, valueType := range types {
+			params[i] = models.Parameter{Name: string(rune('a' + i)), Type: valueType}
+		}
+		return params
+	}
+
+	tests := []struct {
+		name       string
+		language   enums.CodingLanguage
+		input      string
+		parameters []models.Parameter
+		want       []string
+		wantErr    bool
+	}{
+		{name: "python values", language: enums.CodingLanguagePython, input: `[1, 2], "a", true, null`, want: []string{"[1, 2]", `"a"`, "True", "None"}},
+		{name: "python large integer", language: enums.CodingLanguagePython, input: "9007199254740993", want: []string{"9007199254740993"}},
+		{name: "python double", language: enums.CodingLanguagePython, input: "2", parameters: parameters("double"), want: []string{"2.0"}},
+		{name: "javascript values", language: enums.CodingLanguageJavaScript, input: `[1.5, -3], {"k": null}`, want: []string{"[1.5, -3]", `{"k": null}`}},
+		{name: "go long", language: enums.CodingLanguageGo, input: "9007199254740993", parameters: parameters("long"), want: []string{"int64(9007199254740993)"}},
+		{name: "go int array", language: enums.CodingLanguageGo, input: "[1, 2]", parameters: parameters("int[]"), want: []string{"[]int{1, 2}"}},
+		{name: "java long", language: enums.CodingLanguageJava, input: "-9223372036854775807", parameters: parameters("long"), want: []string{"-9223372036854775807L"}},
+		{name: "cpp whole double as int", language: enums.CodingLanguageCpp, input: "3.0", parameters: parameters("int"), want: []string{"3"}},
+		{name: "cpp fraction as int", language: enums.CodingLanguageCpp, input: "3.5", parameters: parameters("int"), wantErr: true},
+		{name: "missing parameter types", language: enums.CodingLanguageJava, input: "1", wantErr: true},
+		{name: "wrong argument count", language: enums.CodingLanguageGo, input: "1, 2", parameters: parameters("int"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, err := GetLanguageDriver(string(tt.language))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := formatTestCaseArguments(driver, tt.input, tt.parameters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatTestCaseArguments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formatTestCaseArguments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
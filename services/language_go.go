@@ -0,0 +1,421 @@
+package services
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// goDriver runs Go solutions written as a free function in package main, or for
+// design questions as a type with a Constructor function and exported methods
+type goDriver struct{}
+
+func (d *goDriver) Language() enums.CodingLanguage { return enums.CodingLanguageGo }
+func (d *goDriver) RunnerLanguage() string         { return "go" }
+func (d *goDriver) Version() string                { return "1.16.2" }
+func (d *goDriver) SourceFile() string             { return "main.go" }
+func (d *goDriver) RequiresParameterTypes() bool   { return true }
+func (d *goDriver) LocalMemoryOverheadMB() int     { return 0 }
+
+func (d *goDriver) LocalCompileCommand() string {
+	return "go build -o main main.go"
+}
+
+func (d *goDriver) LocalRunCommand(memoryLimitMB int) string {
+	return "./main"
+}
+
+// goLiteralSyntax spells typed literals in Go
+var goLiteralSyntax = typedLiteralSyntax{
+	typeName: goTypeName,
+	scalar: func(baseType string, value interface{}) (string, error) {
+		switch baseType {
+		case "int":
+			return integerLiteral(value)
+		case "long":
+			// Typed so it stays int64 when bound to a variable
+			literal, err := integerLiteral(value)
+			if err != nil {
+				return "", err
+			}
+			return "int64(" + literal + ")", nil
+		case "double":
+			return floatLiteral(value)
+		case "bool":
+			return boolLiteral(value)
+		case "string":
+			text, ok := value.(string)
+			if !ok {
+				return "", fmt.Errorf("expected a string, got %v", value)
+			}
+			return strconv.Quote(text), nil
+		case "char":
+			literal, err := cCharLiteral(value)
+			if err != nil {
+				return "", err
+			}
+			return "byte(" + literal + ")", nil
+		case listNodeType:
+			values, err := nodeValueLiterals(value, baseType, "nil")
+			if err != nil {
+				return "", err
+			}
+			return "harnessBuildList([]int{" + strings.Join(values, ", ") + "})", nil
+		case treeNodeType:
+			values, err := nodeValueLiterals(value, baseType, "nil")
+			if err != nil {
+				return "", err
+			}
+			return "harnessBuildTree([]interface{}{" + strings.Join(values, ", ") + "})", nil
+		case graphNodeType:
+			neighbors, err := graphNeighborLiterals(value)
+			if err != nil {
+				return "", err
+			}
+			return "harnessBuildGraph([][]int{" + strings.Join(neighbors, ", ") + "})", nil
+		default:
+			return "", fmt.Errorf("unsupported parameter type for go: %s", baseType)
+		}
+	},
+	array: func(arrayType string, elements []string) (string, error) {
+		typeName, err := goTypeName(arrayType)
+		if err != nil {
+			return "", err
+		}
+		return typeName + "{" + strings.Join(elements, ", ") + "}", nil
+	},
+}
+
+// goTypeName spells a parameter type in Go
+func goTypeName(valueType string) (string, error) {
+	baseType, depth := splitValueType(valueType)
+	names := map[string]string{
+		"int":    "int",
+		"long":   "int64",
+		"double": "float64",
+		"bool":   "bool",
+		"string": "string",
+		"char":   "byte",
+
+		listNodeType: "*" + listNodeType,
+		treeNodeType: "*" + treeNodeType,
+
+		graphNodeType: "*" + graphNodeType,
+	}
+	name, exists := names[baseType]
+	if !exists {
+		return "", fmt.Errorf("unsupported parameter type for go: %s", baseType)
+	}
+	return strings.Repeat("[]", depth) + name, nil
+}
+
+func (d *goDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	return formatTypedLiteral(goLiteralSyntax, value, valueType)
+}
+
+// goPackageClause matches an existing package clause in candidate code
+var goPackageClause = regexp.MustCompile(`(?m)^\s*package\s+\w+`)
+
+// goHarnessImports are aliased so they cannot clash with the candidate's own imports
+const goHarnessImports = `import (
+	harnessjson "encoding/json"
+	harnessfmt "fmt"
+	harnessreflect "reflect"
+	harnessdebug "runtime/debug"
+	harnesstime "time"
+)`
+
+// BuildHarness produces a single file, since Piston only runs the entry file.
+// Go requires imports before declarations, so the harness imports are spliced
+// in after the candidate's own import block.
+// Design questions call exported methods, so method names are capitalized.
+func (d *goDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	spec.Code = spliceGoImports(spec.Code, goHarnessImports)
+	calls := make([][]HarnessCall, len(spec.Calls))
+	for i, caseCalls := range spec.Calls {
+		calls[i] = make([]HarnessCall, len(caseCalls))
+		for j, call := range caseCalls {
+			call.Method = exportedName(call.Method)
+			calls[i][j] = call
+		}
+	}
+	spec.Calls = calls
+	return renderHarness(goHarnessTemplate, spec)
+}
+
+// spliceGoImports puts the candidate's code in package main and inserts imports after its import block
+func spliceGoImports(code, imports string) string {
+	if goPackageClause.MatchString(code) {
+		code = goPackageClause.ReplaceAllString(code, "package main")
+	} else {
+		code = "package main\n\n" + code
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, parser.ImportsOnly)
+	if err != nil {
+		// Leave broken code alone so the compiler reports the candidate's own error
+		return code + "\n\n" + imports
+	}
+
+	insertAt := fset.Position(file.Name.End()).Offset
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			insertAt = fset.Position(genDecl.End()).Offset
+		}
+	}
+	return code[:insertAt] + "\n\n" + imports + "\n" + code[insertAt:]
+}
+
+var goHarnessTemplate = template.Must(template.New("go").Funcs(harnessTemplateFuncs).Parse(`{{.Code}}
+
+func main() {
+	// Each case binds its arguments and returns the call to time, so building
+	// the arguments is not measured
+	harnessCases := []func() func() interface{}{
+{{- range .Cases}}
+		func() func() interface{} {
+{{- range $i, $arg := .}}
+			a{{$i}} := {{$arg}}
+{{- end}}
+			return func() interface{} { return {{$.FunctionName}}({{range $i, $arg := .}}{{if $i}}, {{end}}a{{$i}}{{end}}) }
+		},
+{{- end}}
+{{- range .Calls}}
+		func() func() interface{} {
+{{- range $c, $call := .}}{{range $i, $arg := $call.Args}}
+			a{{$c}}_{{$i}} := {{$arg}}
+{{- end}}{{end}}
+			return func() interface{} {
+				instance := Constructor({{range $i, $arg := (index . 0).Args}}{{if $i}}, {{end}}a0_{{$i}}{{end}})
+				results := []interface{}{nil}
+{{- range $c, $call := .}}{{if $c}}
+{{- if $call.Void}}
+				instance.{{$call.Method}}({{range $i, $arg := $call.Args}}{{if $i}}, {{end}}a{{$c}}_{{$i}}{{end}})
+				results = append(results, nil)
+{{- else}}
+				results = append(results, instance.{{$call.Method}}({{range $i, $arg := $call.Args}}{{if $i}}, {{end}}a{{$c}}_{{$i}}{{end}}))
+{{- end}}{{end}}{{end}}
+{{- if eq (len .) 1}}
+				_ = instance
+{{- end}}
+				return results
+			}
+		},
+{{- end}}
+	}
+
+	for i, prepare := range harnessCases {
+		harnessfmt.Printf("@@{{.Nonce}} BEGIN %d@@\n", i)
+		result, record := harnessRunCase(prepare)
+		if record["status"] == "ok" {
+			serialized, err := harnessjson.Marshal(result)
+			if err != nil {
+				serialized, _ = harnessjson.Marshal(harnessfmt.Sprint(result))
+			}
+			record["result"] = string(serialized)
+		}
+		encoded, _ := harnessjson.Marshal(record)
+		harnessfmt.Printf("\n@@{{.Nonce}} END %d %s@@\n", i, encoded)
+	}
+}
+
+// harnessRunCase prepares and calls one case and normalizes its result,
+// turning a panic into an error record
+func harnessRunCase(prepare func() func() interface{}) (result interface{}, record map[string]interface{}) {
+	start := harnesstime.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			record = map[string]interface{}{
+				"status":    "error",
+				"time":      float64(harnesstime.Since(start).Nanoseconds()) / 1e6,
+				"error":     harnessfmt.Sprintf("panic: %v\n\n%s", r, harnessdebug.Stack()),
+				"errorType": "panic",
+			}
+		}
+	}()
+	call := prepare()
+	start = harnesstime.Now()
+	value := call()
+	record = map[string]interface{}{"status": "ok", "time": float64(harnesstime.Since(start).Nanoseconds()) / 1e6}
+	return harnessNormalize(harnessreflect.ValueOf(value)), record
+}
+
+// harnessNormalize turns nil slices into empty lists so they serialize as [], and bytes into characters
+func harnessNormalize(v harnessreflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case harnessreflect.Slice, harnessreflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = harnessNormalize(v.Index(i))
+		}
+		return items
+	case harnessreflect.Interface, harnessreflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+{{- if .ListNode}}
+		if head, ok := v.Interface().(*ListNode); ok {
+			return harnessListValues(head)
+		}
+{{- end}}
+{{- if .TreeNode}}
+		if root, ok := v.Interface().(*TreeNode); ok {
+			return harnessTreeValues(root)
+		}
+{{- end}}
+{{- if .GraphNode}}
+		if start, ok := v.Interface().(*Node); ok {
+			return harnessGraphValues(start)
+		}
+{{- end}}
+		return harnessNormalize(v.Elem())
+	case harnessreflect.Uint8:
+		// char parameters are bytes, so report them as one-character strings
+		return string(rune(v.Uint()))
+	default:
+		return v.Interface()
+	}
+}
+{{- if .ListNode}}
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+func harnessBuildList(values []int) *ListNode {
+	var head *ListNode
+	for i := len(values) - 1; i >= 0; i-- {
+		head = &ListNode{Val: values[i], Next: head}
+	}
+	return head
+}
+
+// harnessListValues lists a linked list's values, failing on a cycle rather than looping forever
+func harnessListValues(head *ListNode) []interface{} {
+	values := []interface{}{}
+	seen := make(map[*ListNode]bool)
+	for node := head; node != nil; node = node.Next {
+		if seen[node] {
+			panic("linked list contains a cycle")
+		}
+		seen[node] = true
+		values = append(values, node.Val)
+	}
+	return values
+}
+{{- end}}
+{{- if .TreeNode}}
+
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+
+func harnessBuildTree(values []interface{}) *TreeNode {
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	root := &TreeNode{Val: values[0].(int)}
+	queue := []*TreeNode{root}
+	for head, i := 0, 1; head < len(queue) && i < len(values); head, i = head+1, i+2 {
+		node := queue[head]
+		if values[i] != nil {
+			node.Left = &TreeNode{Val: values[i].(int)}
+			queue = append(queue, node.Left)
+		}
+		if i+1 < len(values) && values[i+1] != nil {
+			node.Right = &TreeNode{Val: values[i+1].(int)}
+			queue = append(queue, node.Right)
+		}
+	}
+	return root
+}
+
+// harnessTreeValues lists a tree's values level by level, with nil for missing children
+func harnessTreeValues(root *TreeNode) []interface{} {
+	values := []interface{}{}
+	queue := []*TreeNode{root}
+	for head := 0; head < len(queue); head++ {
+		node := queue[head]
+		if node == nil {
+			values = append(values, nil)
+			continue
+		}
+		values = append(values, node.Val)
+		queue = append(queue, node.Left, node.Right)
+	}
+	for len(values) > 0 && values[len(values)-1] == nil {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+{{- end}}
+{{- if .GraphNode}}
+
+type Node struct {
+	Val       int
+	Neighbors []*Node
+}
+
+func harnessBuildGraph(adjacency [][]int) *Node {
+	nodes := make([]*Node, len(adjacency))
+	for i := range nodes {
+		nodes[i] = &Node{Val: i + 1}
+	}
+	for i, neighbors := range adjacency {
+		for _, value := range neighbors {
+			nodes[i].Neighbors = append(nodes[i].Neighbors, nodes[value-1])
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// harnessGraphValues writes out the graph reachable from a node as the
+// neighbors of each node, in order of value
+func harnessGraphValues(start *Node) []interface{} {
+	nodes := []*Node{start}
+	seen := map[*Node]bool{start: true}
+	for head := 0; head < len(nodes); head++ {
+		for _, neighbor := range nodes[head].Neighbors {
+			if !seen[neighbor] {
+				seen[neighbor] = true
+				nodes = append(nodes, neighbor)
+			}
+		}
+	}
+	byValue := make(map[int]*Node, len(nodes))
+	for _, node := range nodes {
+		byValue[node.Val] = node
+	}
+	values := []interface{}{}
+	for value := 1; value <= len(nodes); value++ {
+		node, ok := byValue[value]
+		if !ok {
+			panic("graph node values must run from 1 to the number of nodes")
+		}
+		neighbors := []interface{}{}
+		for _, neighbor := range node.Neighbors {
+			neighbors = append(neighbors, neighbor.Val)
+		}
+		values = append(values, neighbors)
+	}
+	return values
+}
+{{- end}}
+`))
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// javaDriver runs Java solutions written as a method on a Solution class,
+// or as the question's own class for design questions
+type javaDriver struct{}
+
+func (d *javaDriver) Language() enums.CodingLanguage { return enums.CodingLanguageJava }
+func (d *javaDriver) RunnerLanguage() string         { return "java" }
+func (d *javaDriver) Version() string                { return "15.0.2" }
+func (d *javaDriver) SourceFile() string             { return "Main.java" }
+func (d *javaDriver) LocalCompileCommand() string    { return "javac -encoding UTF-8 Main.java" }
+func (d *javaDriver) RequiresParameterTypes() bool   { return true }
+func (d *javaDriver) LocalMemoryOverheadMB() int     { return 256 }
+
+// javaMaxInlineInputSize bounds the inputs a harness case embeds. Each value in
+// an array literal compiles to up to about 11 bytes of bytecode, and the JVM
+// refuses methods over 64KB, so larger inputs would fail to compile.
+const javaMaxInlineInputSize = 5000
+
+// MaxInlineInputSize is how large an input, as measured by inputSize, one harness case can embed
+func (d *javaDriver) MaxInlineInputSize() int { return javaMaxInlineInputSize }
+
+// LocalRunCommand gives the Java heap the whole memory limit; the sandbox allows extra for the JVM itself
+func (d *javaDriver) LocalRunCommand(memoryLimitMB int) string {
+	return fmt.Sprintf("java -Xmx%dm -XX:+UseSerialGC Main", memoryLimitMB)
+}
+
+// javaLiteralSyntax spells typed literals in Java
+var javaLiteralSyntax = typedLiteralSyntax{
+	typeName: javaTypeName,
+	scalar: func(baseType string, value interface{}) (string, error) {
+		switch baseType {
+		case "int":
+			return integerLiteral(value)
+		case "long":
+			literal, err := integerLiteral(value)
+			if err != nil {
+				return "", err
+			}
+			return literal + "L", nil
+		case "double":
+			return floatLiteral(value)
+		case "bool":
+			return boolLiteral(value)
+		case "string":
+			return cStringLiteral(value)
+		case "char":
+			return cCharLiteral(value)
+		case listNodeType:
+			values, err := nodeValueLiterals(value, baseType, "null")
+			if err != nil {
+				return "", err
+			}
+			return "buildList(new int[]{" + strings.Join(values, ", ") + "})", nil
+		case treeNodeType:
+			values, err := nodeValueLiterals(value, baseType, "null")
+			if err != nil {
+				return "", err
+			}
+			return "buildTree(new Integer[]{" + strings.Join(values, ", ") + "})", nil
+		case graphNodeType:
+			neighbors, err := graphNeighborLiterals(value)
+			if err != nil {
+				return "", err
+			}
+			return "buildGraph(new int[][]{" + strings.Join(neighbors, ", ") + "})", nil
+		default:
+			return "", fmt.Errorf("unsupported parameter type for java: %s", baseType)
+		}
+	},
+	array: func(arrayType string, elements []string) (string, error) {
+		typeName, err := javaTypeName(arrayType)
+		if err != nil {
+			return "", err
+		}
+		return "new " + typeName + "{" + strings.Join(elements, ", ") + "}", nil
+	},
+}
+
+// javaTypeName spells a parameter type in Java
+func javaTypeName(valueType string) (string, error) {
+	baseType, depth := splitValueType(valueType)
+	names := map[string]string{
+		"int":    "int",
+		"long":   "long",
+		"double": "double",
+		"bool":   "boolean",
+		"string": "String",
+		"char":   "char",
+
+		listNodeType: listNodeType,
+		treeNodeType: treeNodeType,
+
+		graphNodeType: graphNodeType,
+	}
+	name, exists := names[baseType]
+	if !exists {
+		return "", fmt.Errorf("unsupported parameter type for java: %s", baseType)
+	}
+	return name + strings.Repeat("[]", depth), nil
+}
+
+func (d *javaDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	return formatTypedLiteral(javaLiteralSyntax, value, valueType)
+}
+
+var (
+	// javaImportLine matches import and package statements in candidate code
+	javaImportLine = regexp.MustCompile(`(?m)^\s*(import|package)\s+[\w.*\s]+;[ \t]*$`)
+	// javaPublicClass matches public top-level class declarations
+	javaPublicClass = regexp.MustCompile(`(?m)^(\s*)public\s+((?:final\s+|abstract\s+)*class\s)`)
+)
+
+// BuildHarness produces a single Main.java, since Piston launches the entry
+// file directly. The candidate's imports are hoisted above the harness and
+// their classes are made package-private so they can share the file.
+func (d *javaDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	var imports []string
+	for _, statement := range javaImportLine.FindAllString(spec.Code, -1) {
+		if statement = strings.TrimSpace(statement); strings.HasPrefix(statement, "import") {
+			imports = append(imports, statement)
+		}
+	}
+
+	code := javaImportLine.ReplaceAllString(spec.Code, "")
+	spec.Code = javaPublicClass.ReplaceAllString(code, "$1$2")
+
+	harness, err := renderHarness(javaHarnessTemplate, spec)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(imports, "\n") + "\n\n" + harness, nil
+}
+
+// javaHarnessTemplate puts each case in its own method so large inputs stay
+// clear of the JVM's per-method size limit. The methods are private so the
+// candidate's classes cannot call them. Each binds the arguments and returns
+// the call, or a design question's sequence of calls, so building them is not timed.
+var javaHarnessTemplate = template.Must(template.New("java").Funcs(harnessTemplateFuncs).Parse(`public class Main {
+{{- range $i, $args := .Cases}}
+    private static java.util.function.Supplier<Object> harnessCase{{$i}}() {
+{{- range $j, $arg := $args}}
+        var a{{$j}} = {{$arg}};
+{{- end}}
+        Solution solution = new Solution();
+        return () -> solution.{{$.FunctionName}}({{range $j, $arg := $args}}{{if $j}}, {{end}}a{{$j}}{{end}});
+    }
+{{- end}}
+{{- range $i, $calls := .Calls}}
+    private static java.util.function.Supplier<Object> harnessCase{{$i}}() {
+{{- range $c, $call := $calls}}{{range $j, $arg := $call.Args}}
+        var a{{$c}}_{{$j}} = {{$arg}};
+{{- end}}{{end}}
+        return () -> {
+            java.util.List<Object> results = new java.util.ArrayList<>();
+            {{$.ClassName}} instance = new {{$.ClassName}}({{range $j, $arg := (index $calls 0).Args}}{{if $j}}, {{end}}a0_{{$j}}{{end}});
+            results.add(null);
+{{- range $c, $call := $calls}}{{if $c}}
+{{- if $call.Void}}
+            instance.{{$call.Method}}({{range $j, $arg := $call.Args}}{{if $j}}, {{end}}a{{$c}}_{{$j}}{{end}});
+            results.add(null);
+{{- else}}
+            results.add(instance.{{$call.Method}}({{range $j, $arg := $call.Args}}{{if $j}}, {{end}}a{{$c}}_{{$j}}{{end}}));
+{{- end}}{{end}}{{end}}
+            return results;
+        };
+    }
+{{- end}}
+
+    public static void main(String[] args) {
+        java.util.List<java.util.function.Supplier<java.util.function.Supplier<Object>>> cases = new java.util.ArrayList<>();
+{{- range $i, $args := .Cases}}
+        cases.add(Main::harnessCase{{$i}});
+{{- end}}
+{{- range $i, $calls := .Calls}}
+        cases.add(Main::harnessCase{{$i}});
+{{- end}}
+
+        for (int i = 0; i < cases.size(); i++) {
+            System.out.println("@@{{.Nonce}} BEGIN " + i + "@@");
+            long start = System.nanoTime();
+            String record;
+            try {
+                // Arguments are built before timing starts so only the call itself is measured
+                java.util.function.Supplier<Object> call = cases.get(i).get();
+                start = System.nanoTime();
+                Object result = call.get();
+                double elapsed = (System.nanoTime() - start) / 1e6;
+                record = "{\"status\":\"ok\",\"time\":" + elapsed + ",\"result\":" + quote(toJson(result)) + "}";
+            } catch (Throwable e) {
+                double elapsed = (System.nanoTime() - start) / 1e6;
+                java.io.StringWriter trace = new java.io.StringWriter();
+                e.printStackTrace(new java.io.PrintWriter(trace));
+                record = "{\"status\":\"error\",\"time\":" + elapsed
+                    + ",\"error\":" + quote(trace.toString())
+                    + ",\"errorType\":" + quote(e.getClass().getSimpleName()) + "}";
+            }
+            System.out.println("\n@@{{.Nonce}} END " + i + " " + record + "@@");
+            System.out.flush();
+        }
+    }
+
+    static String toJson(Object value) {
+        if (value == null) {
+            return "null";
+        }
+{{- if .ListNode}}
+        if (value instanceof ListNode) {
+            java.util.List<Object> values = new java.util.ArrayList<>();
+            java.util.Set<ListNode> seen = java.util.Collections.newSetFromMap(new java.util.IdentityHashMap<>());
+            for (ListNode node = (ListNode) value; node != null; node = node.next) {
+                if (!seen.add(node)) {
+                    throw new IllegalStateException("linked list contains a cycle");
+                }
+                values.add(node.val);
+            }
+            return toJson(values);
+        }
+{{- end}}
+{{- if .TreeNode}}
+        if (value instanceof TreeNode) {
+            java.util.List<Object> values = new java.util.ArrayList<>();
+            java.util.List<TreeNode> queue = new java.util.ArrayList<>();
+            queue.add((TreeNode) value);
+            for (int head = 0; head < queue.size(); head++) {
+                TreeNode node = queue.get(head);
+                if (node == null) {
+                    values.add(null);
+                    continue;
+                }
+                values.add(node.val);
+                queue.add(node.left);
+                queue.add(node.right);
+            }
+            while (!values.isEmpty() && values.get(values.size() - 1) == null) {
+                values.remove(values.size() - 1);
+            }
+            return toJson(values);
+        }
+{{- end}}
+{{- if .GraphNode}}
+        if (value instanceof Node) {
+            return toJson(graphValues((Node) value));
+        }
+{{- end}}
+        if (value instanceof String || value instanceof Character) {
+            return quote(value.toString());
+        }
+        if (value instanceof Boolean || value instanceof Number) {
+            return value.toString();
+        }
+        if (value.getClass().isArray()) {
+            StringBuilder sb = new StringBuilder("[");
+            int length = java.lang.reflect.Array.getLength(value);
+            for (int i = 0; i < length; i++) {
+                if (i > 0) {
+                    sb.append(",");
+                }
+                sb.append(toJson(java.lang.reflect.Array.get(value, i)));
+            }
+            return sb.append("]").toString();
+        }
+        if (value instanceof Iterable) {
+            StringBuilder sb = new StringBuilder("[");
+            boolean first = true;
+            for (Object item : (Iterable<?>) value) {
+                if (!first) {
+                    sb.append(",");
+                }
+                first = false;
+                sb.append(toJson(item));
+            }
+            return sb.append("]").toString();
+        }
+        if (value instanceof java.util.Map) {
+            StringBuilder sb = new StringBuilder("{");
+            boolean first = true;
+            for (java.util.Map.Entry<?, ?> entry : ((java.util.Map<?, ?>) value).entrySet()) {
+                if (!first) {
+                    sb.append(",");
+                }
+                first = false;
+                sb.append(quote(String.valueOf(entry.getKey()))).append(":").append(toJson(entry.getValue()));
+            }
+            return sb.append("}").toString();
+        }
+        return quote(value.toString());
+    }
+
+{{- if .ListNode}}
+
+    static ListNode buildList(int[] values) {
+        ListNode head = null;
+        for (int i = values.length - 1; i >= 0; i--) {
+            head = new ListNode(values[i], head);
+        }
+        return head;
+    }
+{{- end}}
+{{- if .TreeNode}}
+
+    static TreeNode buildTree(Integer[] values) {
+        if (values.length == 0 || values[0] == null) {
+            return null;
+        }
+        TreeNode root = new TreeNode(values[0]);
+        java.util.List<TreeNode> queue = new java.util.ArrayList<>();
+        queue.add(root);
+        for (int head = 0, i = 1; head < queue.size() && i < values.length; head++, i += 2) {
+            TreeNode node = queue.get(head);
+            if (values[i] != null) {
+                node.left = new TreeNode(values[i]);
+                queue.add(node.left);
+            }
+            if (i + 1 < values.length && values[i + 1] != null) {
+                node.right = new TreeNode(values[i + 1]);
+                queue.add(node.right);
+            }
+        }
+        return root;
+    }
+{{- end}}
+{{- if .GraphNode}}
+
+    static Node buildGraph(int[][] adjacency) {
+        Node[] nodes = new Node[adjacency.length];
+        for (int i = 0; i < nodes.length; i++) {
+            nodes[i] = new Node(i + 1);
+        }
+        for (int i = 0; i < nodes.length; i++) {
+            for (int value : adjacency[i]) {
+                nodes[i].neighbors.add(nodes[value - 1]);
+            }
+        }
+        return nodes.length > 0 ? nodes[0] : null;
+    }
+
+    static java.util.List<java.util.List<Integer>> graphValues(Node start) {
+        java.util.List<Node> nodes = new java.util.ArrayList<>();
+        java.util.Set<Node> seen = java.util.Collections.newSetFromMap(new java.util.IdentityHashMap<>());
+        nodes.add(start);
+        seen.add(start);
+        for (int head = 0; head < nodes.size(); head++) {
+            for (Node neighbor : nodes.get(head).neighbors) {
+                if (seen.add(neighbor)) {
+                    nodes.add(neighbor);
+                }
+            }
+        }
+        java.util.Map<Integer, Node> byValue = new java.util.HashMap<>();
+        for (Node node : nodes) {
+            byValue.put(node.val, node);
+        }
+        java.util.List<java.util.List<Integer>> values = new java.util.ArrayList<>();
+        for (int value = 1; value <= nodes.size(); value++) {
+            Node node = byValue.get(value);
+            if (node == null) {
+                throw new IllegalStateException("graph node values must run from 1 to the number of nodes");
+            }
+            java.util.List<Integer> neighbors = new java.util.ArrayList<>();
+            for (Node neighbor : node.neighbors) {
+                neighbors.add(neighbor.val);
+            }
+            values.add(neighbors);
+        }
+        return values;
+    }
+{{- end}}
+
+    static String quote(String text) {
+        StringBuilder sb = new StringBuilder("\"");
+        for (char c : text.toCharArray()) {
+            switch (c) {
+                case '"': sb.append("\\\""); break;
+                case '\\': sb.append("\\\\"); break;
+                case '\n': sb.append("\\n"); break;
+                case '\r': sb.append("\\r"); break;
+                case '\t': sb.append("\\t"); break;
+                default:
+                    if (c < 0x20) {
+                        sb.append(String.format("\\u%04x", (int) c));
+                    } else {
+                        sb.append(c);
+                    }
+            }
+        }
+        return sb.append("\"").toString();
+    }
+}
+{{- if .ListNode}}
+
+class ListNode {
+    int val;
+    ListNode next;
+    ListNode() {}
+    ListNode(int val) { this.val = val; }
+    ListNode(int val, ListNode next) { this.val = val; this.next = next; }
+}
+{{- end}}
+{{- if .TreeNode}}
+
+class TreeNode {
+    int val;
+    TreeNode left;
+    TreeNode right;
+    TreeNode() {}
+    TreeNode(int val) { this.val = val; }
+    TreeNode(int val, TreeNode left, TreeNode right) { this.val = val; this.left = left; this.right = right; }
+}
+{{- end}}
+{{- if .GraphNode}}
+
+class Node {
+    public int val;
+    public java.util.List<Node> neighbors;
+    public Node() { this(0); }
+    public Node(int val) { this(val, new java.util.ArrayList<Node>()); }
+    public Node(int val, java.util.ArrayList<Node> neighbors) { this.val = val; this.neighbors = neighbors; }
+}
+{{- end}}
+
+{{.Code}}
+`))
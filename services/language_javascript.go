@@ -0,0 +1,317 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// javaScriptDriver runs Node.js solutions written as a free function,
+// or as a class for design questions
+type javaScriptDriver struct{}
+
+func (d *javaScriptDriver) Language() enums.CodingLanguage { return enums.CodingLanguageJavaScript }
+func (d *javaScriptDriver) RunnerLanguage() string         { return "javascript" }
+func (d *javaScriptDriver) Version() string                { return "18.15.0" }
+func (d *javaScriptDriver) SourceFile() string             { return "main.js" }
+func (d *javaScriptDriver) LocalCompileCommand() string    { return "" }
+func (d *javaScriptDriver) RequiresParameterTypes() bool   { return false }
+func (d *javaScriptDriver) LocalMemoryOverheadMB() int     { return 200 }
+
+// LocalRunCommand gives V8's heap the whole memory limit; the sandbox allows extra for node itself
+func (d *javaScriptDriver) LocalRunCommand(memoryLimitMB int) string {
+	return fmt.Sprintf("node --max-old-space-size=%d main.js", memoryLimitMB)
+}
+
+func (d *javaScriptDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	return javaScriptTypedLiteral(value, valueType)
+}
+
+// scanSource lets the code policy inspect JavaScript imports and names
+func (d *javaScriptDriver) scanSource(code string) sourceScan {
+	tokens := scanJavaScript(code, 1)
+	return sourceScan{tokens: tokens, imports: javaScriptImports(tokens)}
+}
+
+func (d *javaScriptDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	return renderHarness(javaScriptHarnessTemplate, spec)
+}
+
+// javaScriptNodeBuilders are the harness functions that build node-typed arguments
+var javaScriptNodeBuilders = map[string]string{listNodeType: "__buildList", treeNodeType: "__buildTree", graphNodeType: "__buildGraph"}
+
+// javaScriptTypedLiteral spells a value as a JavaScript literal; types are only
+// needed for node types, which the harness builds from their level-order lists
+func javaScriptTypedLiteral(value interface{}, valueType string) (string, error) {
+	if literal, isNode, err := nodeLiteral(value, valueType, javaScriptNodeBuilders, javaScriptLiteral); isNode {
+		return literal, err
+	}
+	return javaScriptLiteral(value)
+}
+
+// javaScriptLiteral spells a value as a JavaScript (and TypeScript) literal
+func javaScriptLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case json.Number:
+		if integer, ok := numberInteger(v); ok {
+			return integer.String(), nil
+		}
+		number, _ := numberFloat(v)
+		return javaScriptLiteral(number)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN", nil
+		case math.IsInf(v, 1):
+			return "Infinity", nil
+		case math.IsInf(v, -1):
+			return "-Infinity", nil
+		default:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		}
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			literal, err := javaScriptLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			literal, err := javaScriptLiteral(v[key])
+			if err != nil {
+				return "", err
+			}
+			items[i] = key + ": " + literal // keys are already canonical literals
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	default:
+		literal, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("cannot format %v as a JavaScript literal: %w", value, err)
+		}
+		return string(literal), nil
+	}
+}
+
+// javaScriptNodeTypes defines the node types ahead of the candidate's code, as
+// constructor functions so solutions that define their own still run. The
+// graph node is _Node, as on LeetCode, since TypeScript's DOM typings already
+// declare a Node.
+const javaScriptNodeTypes = `{{if .ListNode}}function ListNode(val, next) {
+  this.val = val === undefined ? 0 : val;
+  this.next = next === undefined ? null : next;
+}
+
+{{end}}{{if .TreeNode}}function TreeNode(val, left, right) {
+  this.val = val === undefined ? 0 : val;
+  this.left = left === undefined ? null : left;
+  this.right = right === undefined ? null : right;
+}
+
+{{end}}{{if .GraphNode}}function _Node(val, neighbors) {
+  this.val = val === undefined ? 0 : val;
+  this.neighbors = neighbors === undefined ? [] : neighbors;
+}
+
+{{end}}`
+
+// javaScriptHarnessBody is shared by the JavaScript and TypeScript harnesses.
+// It only relies on globalThis so it type-checks without Node typings.
+const javaScriptHarnessBody = `
+;(() => {
+  const __process: any = (globalThis as any).process;
+{{- if .ListNode}}
+
+  const __buildList = (values: any[]): any => {
+    let head = null;
+    for (let i = values.length - 1; i >= 0; i--) {
+      head = new ListNode(values[i], head);
+    }
+    return head;
+  };
+  const __listValues = (head: any): any[] => {
+    const values = [];
+    const seen = new Set();
+    for (let node = head; node != null; node = node.next) {
+      if (seen.has(node)) {
+        throw new Error("linked list contains a cycle");
+      }
+      seen.add(node);
+      values.push(node.val);
+    }
+    return values;
+  };
+{{- end}}
+{{- if .TreeNode}}
+
+  const __buildTree = (values: any[]): any => {
+    if (values.length === 0 || values[0] === null) {
+      return null;
+    }
+    const root = new TreeNode(values[0]);
+    const queue = [root];
+    for (let head = 0, i = 1; head < queue.length && i < values.length; head++, i += 2) {
+      const node = queue[head];
+      if (values[i] !== null) {
+        node.left = new TreeNode(values[i]);
+        queue.push(node.left);
+      }
+      if (i + 1 < values.length && values[i + 1] !== null) {
+        node.right = new TreeNode(values[i + 1]);
+        queue.push(node.right);
+      }
+    }
+    return root;
+  };
+  const __treeValues = (root: any): any[] => {
+    const values = [];
+    const queue = [root];
+    for (let head = 0; head < queue.length; head++) {
+      const node = queue[head];
+      if (node == null) {
+        values.push(null);
+        continue;
+      }
+      values.push(node.val);
+      queue.push(node.left, node.right);
+    }
+    while (values.length > 0 && values[values.length - 1] === null) {
+      values.pop();
+    }
+    return values;
+  };
+{{- end}}
+{{- if .GraphNode}}
+
+  const __buildGraph = (values: any[]): any => {
+    const nodes = values.map((_: any, i: number) => new _Node(i + 1));
+    nodes.forEach((node: any, i: number) => {
+      node.neighbors = values[i].map((value: number) => nodes[value - 1]);
+    });
+    return nodes.length > 0 ? nodes[0] : null;
+  };
+  const __graphValues = (start: any): any[] => {
+    const nodes = [start];
+    const seen = new Set([start]);
+    for (let head = 0; head < nodes.length; head++) {
+      for (const neighbor of nodes[head].neighbors) {
+        if (!seen.has(neighbor)) {
+          seen.add(neighbor);
+          nodes.push(neighbor);
+        }
+      }
+    }
+    const byValue = new Map();
+    for (const node of nodes) {
+      byValue.set(node.val, node);
+    }
+    const values = [];
+    for (let value = 1; value <= nodes.length; value++) {
+      if (!byValue.has(value)) {
+        throw new Error("graph node values must run from 1 to the number of nodes");
+      }
+      values.push(byValue.get(value).neighbors.map((neighbor: any) => neighbor.val));
+    }
+    return values;
+  };
+{{- end}}
+
+  const __jsonValue = (key: string, value: any): any => {
+{{- if .ListNode}}
+    if (value instanceof ListNode) {
+      return __listValues(value);
+    }
+{{- end}}
+{{- if .TreeNode}}
+    if (value instanceof TreeNode) {
+      return __treeValues(value);
+    }
+{{- end}}
+{{- if .GraphNode}}
+    if (value instanceof _Node) {
+      return __graphValues(value);
+    }
+{{- end}}
+    return value;
+  };
+
+  const __cases: (() => any[])[] = [
+{{- range .Cases}}
+    () => [{{join . ", "}}],
+{{- end}}
+{{- range .Calls}}
+    () => [{{range $i, $call := .}}{{if $i}}, {{end}}[{{printf "%q" $call.Method}}, [{{join $call.Args ", "}}]]{{end}}],
+{{- end}}
+  ];
+
+  for (let __i = 0; __i < __cases.length; __i++) {
+    console.log("@@{{.Nonce}} BEGIN " + __i + "@@");
+    let __start = __process.hrtime.bigint();
+    let __record;
+    try {
+      // Arguments are built before timing starts so only the call itself is measured
+      const __args = __cases[__i]();
+      __start = __process.hrtime.bigint();
+{{- if .ClassName}}
+      const __instance = new ({{.ClassName}} as any)(...__args[0][1]);
+      const __result = [null];
+      for (const [__method, __callArgs] of __args.slice(1)) {
+        const __value = __instance[__method](...__callArgs);
+        __result.push(__value === undefined ? null : __value);
+      }
+{{- else}}
+      const __result = ({{.FunctionName}} as any)(...__args);
+{{- end}}
+      __record = { status: "ok", time: Number(__process.hrtime.bigint() - __start) / 1e6 };
+      __record.result = __result === undefined ? "null" : JSON.stringify(__result, __jsonValue);
+    } catch (__e: any) {
+      __record = {
+        status: "error",
+        time: Number(__process.hrtime.bigint() - __start) / 1e6,
+        error: String((__e && __e.stack) || __e),
+        errorType: (__e && __e.name) || "Error",
+      };
+    }
+    console.log("\n@@{{.Nonce}} END " + __i + " " + JSON.stringify(__record) + "@@");
+  }
+})();
+`
+
+// stripTypeAnnotations turns the shared TypeScript-flavoured harness body into plain JavaScript
+var stripTypeAnnotations = strings.NewReplacer(
+	"const __process: any = (globalThis as any).process;", "const __process = globalThis.process;",
+	"const __cases: (() => any[])[] = [", "const __cases = [",
+	"(values: any[]): any =>", "(values) =>",
+	"(head: any): any[] =>", "(head) =>",
+	"(root: any): any[] =>", "(root) =>",
+	"(start: any): any[] =>", "(start) =>",
+	"(_: any, i: number) =>", "(_, i) =>",
+	"(node: any, i: number) =>", "(node, i) =>",
+	"(value: number) =>", "(value) =>",
+	"(neighbor: any) =>", "(neighbor) =>",
+	"(key: string, value: any): any =>", "(key, value) =>",
+	"catch (__e: any)", "catch (__e)",
+	"({{.FunctionName}} as any)", "{{.FunctionName}}",
+	"({{.ClassName}} as any)", "{{.ClassName}}",
+)
+
+var javaScriptHarnessTemplate = template.Must(template.New("javascript").Funcs(harnessTemplateFuncs).Parse(
+	javaScriptNodeTypes + "{{.Code}}\n" + stripTypeAnnotations.Replace(javaScriptHarnessBody)))
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// pythonDriver runs Python 3 solutions written as a free function,
+// or as a class for design questions
+type pythonDriver struct{}
+
+func (d *pythonDriver) Language() enums.CodingLanguage { return enums.CodingLanguagePython }
+func (d *pythonDriver) RunnerLanguage() string         { return "python" }
+func (d *pythonDriver) Version() string                { return "3.10.0" }
+func (d *pythonDriver) SourceFile() string             { return "main.py" }
+func (d *pythonDriver) LocalCompileCommand() string    { return "" }
+func (d *pythonDriver) RequiresParameterTypes() bool   { return false }
+func (d *pythonDriver) LocalMemoryOverheadMB() int     { return 0 }
+
+func (d *pythonDriver) LocalRunCommand(memoryLimitMB int) string {
+	return "python3 main.py"
+}
+
+// pythonNodeBuilders are the harness functions that build node-typed arguments
+var pythonNodeBuilders = map[string]string{listNodeType: "__build_list", treeNodeType: "__build_tree", graphNodeType: "__build_graph"}
+
+// FormatLiteral spells a value as a Python literal; types are only needed for
+// doubles and node types
+func (d *pythonDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	plain := func(value interface{}) (string, error) { return d.FormatLiteral(value, "") }
+	if literal, isNode, err := nodeLiteral(value, valueType, pythonNodeBuilders, plain); isNode {
+		return literal, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "None", nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	case json.Number:
+		// Python integers are unbounded, so they are written exactly as given
+		if integer, ok := numberInteger(v); ok && !strings.HasPrefix(valueType, "double") {
+			return integer.String(), nil
+		}
+		number, _ := numberFloat(v)
+		return d.FormatLiteral(number, valueType)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "float('nan')", nil
+		case math.IsInf(v, 1):
+			return "float('inf')", nil
+		case math.IsInf(v, -1):
+			return "float('-inf')", nil
+		case v == math.Trunc(v) && !strings.HasPrefix(valueType, "double"):
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		default:
+			return floatLiteral(v)
+		}
+	case string:
+		return strconv.Quote(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			literal, err := d.FormatLiteral(item, strings.TrimSuffix(valueType, "[]"))
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			literal, err := d.FormatLiteral(v[key], "")
+			if err != nil {
+				return "", err
+			}
+			items[i] = key + ": " + literal // keys are already canonical literals
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("cannot format %v as a Python literal", value)
+	}
+}
+
+// scanSource lets the code policy inspect Python imports and names
+func (d *pythonDriver) scanSource(code string) sourceScan {
+	tokens := scanPython(code, 1)
+	return sourceScan{tokens: tokens, imports: pythonImports(tokens)}
+}
+
+func (d *pythonDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	return renderHarness(pythonHarnessTemplate, spec)
+}
+
+// pythonHarnessTemplate defines the node types ahead of the candidate's code,
+// since solutions name them in type hints that run when the function is defined
+var pythonHarnessTemplate = template.Must(template.New("python").Funcs(harnessTemplateFuncs).Parse(`
+{{- if or .ListNode .TreeNode .GraphNode}}from typing import List, Optional
+{{- end}}
+{{- if .ListNode}}
+
+
+class ListNode:
+    def __init__(self, val=0, next=None):
+        self.val = val
+        self.next = next
+{{- end}}
+{{- if .TreeNode}}
+
+
+class TreeNode:
+    def __init__(self, val=0, left=None, right=None):
+        self.val = val
+        self.left = left
+        self.right = right
+{{- end}}
+{{- if .GraphNode}}
+
+
+class Node:
+    def __init__(self, val=0, neighbors=None):
+        self.val = val
+        self.neighbors = neighbors if neighbors is not None else []
+{{- end}}
+{{- if or .ListNode .TreeNode .GraphNode}}
+
+
+{{end}}
+{{- .Code}}
+
+import json as __json, time as __time, traceback as __traceback
+{{- if .ListNode}}
+
+
+def __build_list(values):
+    head = None
+    for value in reversed(values):
+        head = ListNode(value, head)
+    return head
+
+
+def __list_values(head):
+    values, seen = [], set()
+    while head is not None:
+        if id(head) in seen:
+            raise ValueError("linked list contains a cycle")
+        seen.add(id(head))
+        values.append(head.val)
+        head = head.next
+    return values
+{{- end}}
+{{- if .TreeNode}}
+
+
+def __build_tree(values):
+    if not values or values[0] is None:
+        return None
+    root = TreeNode(values[0])
+    queue, i = [root], 1
+    for node in queue:
+        if i >= len(values):
+            break
+        if values[i] is not None:
+            node.left = TreeNode(values[i])
+            queue.append(node.left)
+        i += 1
+        if i < len(values) and values[i] is not None:
+            node.right = TreeNode(values[i])
+            queue.append(node.right)
+        i += 1
+    return root
+
+
+def __tree_values(root):
+    values, queue = [], [root]
+    for node in queue:
+        if node is None:
+            values.append(None)
+            continue
+        values.append(node.val)
+        queue.append(node.left)
+        queue.append(node.right)
+    while values and values[-1] is None:
+        values.pop()
+    return values
+{{- end}}
+{{- if .GraphNode}}
+
+
+def __build_graph(adjacency):
+    nodes = [Node(i + 1) for i in range(len(adjacency))]
+    for node, neighbors in zip(nodes, adjacency):
+        node.neighbors = [nodes[value - 1] for value in neighbors]
+    return nodes[0] if nodes else None
+
+
+def __graph_values(start):
+    nodes, seen = [start], {id(start)}
+    for node in nodes:
+        for neighbor in node.neighbors:
+            if id(neighbor) not in seen:
+                seen.add(id(neighbor))
+                nodes.append(neighbor)
+    by_value = {node.val: node for node in nodes}
+    if sorted(by_value) != list(range(1, len(nodes) + 1)):
+        raise ValueError("graph node values must run from 1 to the number of nodes")
+    return [[neighbor.val for neighbor in by_value[value].neighbors] for value in range(1, len(nodes) + 1)]
+{{- end}}
+
+
+def __json_default(o):
+{{- if .ListNode}}
+    if isinstance(o, ListNode):
+        return __list_values(o)
+{{- end}}
+{{- if .TreeNode}}
+    if isinstance(o, TreeNode):
+        return __tree_values(o)
+{{- end}}
+{{- if .GraphNode}}
+    if isinstance(o, Node):
+        return __graph_values(o)
+{{- end}}
+    if isinstance(o, (set, frozenset, tuple)):
+        return list(o)
+    return str(o)
+
+
+def __serialize(value):
+    return __json.dumps(value, default=__json_default)
+
+
+def __run_cases():
+    # The cases are local so the candidate's code cannot look them up in globals()
+    __cases = [
+{{- range .Cases}}
+        lambda: [{{join . ", "}}],
+{{- end}}
+{{- range .Calls}}
+        lambda: [{{range $i, $call := .}}{{if $i}}, {{end}}({{printf "%q" $call.Method}}, [{{join $call.Args ", "}}]){{end}}],
+{{- end}}
+    ]
+
+    for __i, __case in enumerate(__cases):
+        print("@@{{.Nonce}} BEGIN %d@@" % __i, flush=True)
+        __start = __time.perf_counter()
+        try:
+            # Arguments are built before timing starts so only the call itself is measured
+            __args = __case()
+            __start = __time.perf_counter()
+{{- if .ClassName}}
+            __instance = {{.ClassName}}(*__args[0][1])
+            __result = [None]
+            for __method, __call_args in __args[1:]:
+                __result.append(getattr(__instance, __method)(*__call_args))
+{{- else}}
+            __result = {{.FunctionName}}(*__args)
+{{- end}}
+            __record = {"status": "ok", "time": (__time.perf_counter() - __start) * 1000}
+            __record["result"] = __serialize(__result)
+        except Exception as __e:
+            __record = {"status": "error", "time": (__time.perf_counter() - __start) * 1000,
+                        "error": __traceback.format_exc(), "errorType": type(__e).__name__}
+        print("\n@@{{.Nonce}} END %d %s@@" % (__i, __json.dumps(__record)), flush=True)
+
+
+__run_cases()
+`))
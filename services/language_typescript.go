@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"text/template"
+
+	"stormhacks-be/types/enums"
+)
+
+// typeScriptDriver runs TypeScript solutions written as a free function,
+// or as a class for design questions
+type typeScriptDriver struct{}
+
+func (d *typeScriptDriver) Language() enums.CodingLanguage { return enums.CodingLanguageTypeScript }
+func (d *typeScriptDriver) RunnerLanguage() string         { return "typescript" }
+func (d *typeScriptDriver) Version() string                { return "5.0.3" }
+func (d *typeScriptDriver) SourceFile() string             { return "main.ts" }
+func (d *typeScriptDriver) RequiresParameterTypes() bool   { return false }
+func (d *typeScriptDriver) LocalMemoryOverheadMB() int     { return 200 }
+
+func (d *typeScriptDriver) LocalCompileCommand() string {
+	return "tsc --target es2020 --module commonjs --strict false --skipLibCheck main.ts"
+}
+
+// LocalRunCommand gives V8's heap the whole memory limit; the sandbox allows extra for node itself
+func (d *typeScriptDriver) LocalRunCommand(memoryLimitMB int) string {
+	return fmt.Sprintf("node --max-old-space-size=%d main.js", memoryLimitMB)
+}
+
+// FormatLiteral uses JavaScript literals, which TypeScript infers types from
+func (d *typeScriptDriver) FormatLiteral(value interface{}, valueType string) (string, error) {
+	return javaScriptTypedLiteral(value, valueType)
+}
+
+// scanSource scans TypeScript like JavaScript, whose imports and names it shares
+func (d *typeScriptDriver) scanSource(code string) sourceScan {
+	tokens := scanJavaScript(code, 1)
+	return sourceScan{tokens: tokens, imports: javaScriptImports(tokens)}
+}
+
+func (d *typeScriptDriver) BuildHarness(spec HarnessSpec) (string, error) {
+	return renderHarness(typeScriptHarnessTemplate, spec)
+}
+
+// typeScriptNodeTypes defines the node types ahead of the candidate's code,
+// naming the graph node _Node like the JavaScript harness
+const typeScriptNodeTypes = `{{if .ListNode}}class ListNode {
+  val: number;
+  next: ListNode | null;
+  constructor(val?: number, next?: ListNode | null) {
+    this.val = val === undefined ? 0 : val;
+    this.next = next === undefined ? null : next;
+  }
+}
+
+{{end}}{{if .TreeNode}}class TreeNode {
+  val: number;
+  left: TreeNode | null;
+  right: TreeNode | null;
+  constructor(val?: number, left?: TreeNode | null, right?: TreeNode | null) {
+    this.val = val === undefined ? 0 : val;
+    this.left = left === undefined ? null : left;
+    this.right = right === undefined ? null : right;
+  }
+}
+
+{{end}}{{if .GraphNode}}class _Node {
+  val: number;
+  neighbors: _Node[];
+  constructor(val?: number, neighbors?: _Node[]) {
+    this.val = val === undefined ? 0 : val;
+    this.neighbors = neighbors === undefined ? [] : neighbors;
+  }
+}
+
+{{end}}`
+
+var typeScriptHarnessTemplate = template.Must(template.New("typescript").Funcs(harnessTemplateFuncs).Parse(
+	typeScriptNodeTypes + "{{.Code}}\n" + javaScriptHarnessBody))
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrAIUnavailable is returned by AI features when the language model or prompts could not be set up
+var ErrAIUnavailable = errors.New("AI features are unavailable: the language model or prompts could not be set up")
+
+// LLMClient sends prompts to a language model
+type LLMClient interface {
+	// Generate returns the model's complete reply to a request
+	Generate(ctx context.Context, req LLMRequest) (string, error)
+
+	// Stream passes each piece of the reply to onChunk as the model produces
+	// it, then returns the complete reply
+	Stream(ctx context.Context, req LLMRequest, onChunk func(string)) (string, error)
+}
+
+// LLMRequest describes a prompt to send to a language model
+type LLMRequest struct {
+	Prompt string
+	Schema *JSONSchema // when set, the reply must be JSON matching it; providers enforce it where they can
+}
+
+// Available LLM backends
+const (
+	LLMProviderGemini = "gemini"
+	LLMProviderOpenAI = "openai" // any OpenAI-compatible chat completions endpoint
+	LLMProviderOllama = "ollama"
+	LLMProviderFake   = "fake" // scripted replies, for tests
+)
+
+// LLMConfig holds language model configuration. Model and BaseURL default per provider.
+type LLMConfig struct {
+	Provider   string
+	Model      string
+	BaseURL    string
+	APIKey     string
+	Timeout    time.Duration
+	FakeScript string // path to the fake provider's script
+
+	// RepairAttempts is how many times a reply that breaks its schema is sent back to be fixed
+	RepairAttempts int
+}
+
+// DefaultLLMConfig returns a default language model configuration from environment variables
+func DefaultLLMConfig() LLMConfig {
+	provider := os.Getenv("LLM_PROVIDER")
+	if provider == "" {
+		provider = LLMProviderGemini
+	}
+
+	timeoutSeconds, err := strconv.Atoi(os.Getenv("LLM_TIMEOUT_SECONDS"))
+	if err != nil || timeoutSeconds <= 0 {
+		timeoutSeconds = 60
+	}
+
+	repairAttempts, err := strconv.Atoi(os.Getenv("LLM_REPAIR_ATTEMPTS"))
+	if err != nil || repairAttempts < 0 {
+		repairAttempts = 2
+	}
+
+	return LLMConfig{
+		Provider:   provider,
+		Model:      os.Getenv("LLM_MODEL"),
+		BaseURL:    os.Getenv("LLM_BASE_URL"),
+		APIKey:     os.Getenv("LLM_API_KEY"),
+		Timeout:    time.Duration(timeoutSeconds) * time.Second,
+		FakeScript: os.Getenv("LLM_FAKE_SCRIPT"),
+
+		RepairAttempts: repairAttempts,
+	}
+}
+
+// NewLLMClient creates the language model client selected by the configuration
+func NewLLMClient(config LLMConfig) (LLMClient, error) {
+	switch config.Provider {
+	case LLMProviderGemini:
+		client, err := NewGeminiClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case LLMProviderOpenAI:
+		return NewOpenAIClient(config), nil
+	case LLMProviderOllama:
+		return NewOllamaClient(config), nil
+	case LLMProviderFake:
+		if config.FakeScript == "" {
+			return nil, fmt.Errorf("the %s LLM provider needs a script (LLM_FAKE_SCRIPT)", LLMProviderFake)
+		}
+		client, err := LoadFakeLLMClient(config.FakeScript)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s. Allowed providers: %s, %s, %s, %s", config.Provider, LLMProviderGemini, LLMProviderOpenAI, LLMProviderOllama, LLMProviderFake)
+	}
+}
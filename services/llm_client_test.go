@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newLLMTestServer serves every request with the given status and body,
+// returning a configuration that points a provider's client at it
+func newLLMTestServer(t *testing.T, status int, body string) LLMConfig {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return LLMConfig{BaseURL: server.URL, Timeout: 5 * time.Second}
+}
+
+func TestNewLLMClient(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(script, []byte(`[{"match": "", "reply": "{}"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  LLMConfig
+		want    LLMClient
+		wantErr bool
+	}{
+		{name: "openai", config: LLMConfig{Provider: LLMProviderOpenAI}, want: &OpenAIClient{}},
+		{name: "ollama", config: LLMConfig{Provider: LLMProviderOllama}, want: &OllamaClient{}},
+		{name: "fake", config: LLMConfig{Provider: LLMProviderFake, FakeScript: script}, want: &FakeLLMClient{}},
+		{name: "fake without a script", config: LLMConfig{Provider: LLMProviderFake}, wantErr: true},
+		{name: "fake with a missing script", config: LLMConfig{Provider: LLMProviderFake, FakeScript: script + ".missing"}, wantErr: true},
+		{name: "unknown provider", config: LLMConfig{Provider: "nope"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewLLMClient(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewLLMClient() = %T, want an error", client)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewLLMClient() error = %v", err)
+			}
+			if got, want := fmt.Sprintf("%T", client), fmt.Sprintf("%T", tt.want); got != want {
+				t.Errorf("NewLLMClient() = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FakeLLMRule scripts one reply of the fake client. The first rule whose Match
+// appears in the prompt answers it; a rule without Match answers any prompt.
+type FakeLLMRule struct {
+	Match string `json:"match"`
+	Reply string `json:"reply"`
+}
+
+// FakeLLMClient answers prompts from a script instead of a model, so the API
+// can be run and tested deterministically without any model credentials
+type FakeLLMClient struct {
+	rules []FakeLLMRule
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// NewFakeLLMClient creates a fake client that answers with the given rules
+func NewFakeLLMClient(rules ...FakeLLMRule) *FakeLLMClient {
+	return &FakeLLMClient{rules: rules}
+}
+
+// LoadFakeLLMClient creates a fake client from a script file holding a JSON array of rules
+func LoadFakeLLMClient(path string) (*FakeLLMClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake LLM script: %w", err)
+	}
+
+	var rules []FakeLLMRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse fake LLM script %s: %w", path, err)
+	}
+	return NewFakeLLMClient(rules...), nil
+}
+
+// Generate answers with the first matching rule's reply
+func (c *FakeLLMClient) Generate(ctx context.Context, req LLMRequest) (string, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, req.Prompt)
+	c.mu.Unlock()
+
+	for _, rule := range c.rules {
+		if strings.Contains(req.Prompt, rule.Match) {
+			return rule.Reply, nil
+		}
+	}
+	return "", fmt.Errorf("no scripted reply matches the prompt")
+}
+
+// fakeStreamChunkSize is how many bytes of a scripted reply each streamed chunk holds
+const fakeStreamChunkSize = 8
+
+// Stream answers like Generate, passing the reply on in small chunks
+func (c *FakeLLMClient) Stream(ctx context.Context, req LLMRequest, onChunk func(string)) (string, error) {
+	reply, err := c.Generate(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	for start := 0; start < len(reply); start += fakeStreamChunkSize {
+		end := start + fakeStreamChunkSize
+		if end > len(reply) {
+			end = len(reply)
+		}
+		onChunk(reply[start:end])
+	}
+	return reply, nil
+}
+
+// Prompts returns every prompt the fake client was sent, oldest first
+func (c *FakeLLMClient) Prompts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.prompts...)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFakeLLMClientGenerate(t *testing.T) {
+	client := NewFakeLLMClient(
+		FakeLLMRule{Match: "hint", Reply: "a hint"},
+		FakeLLMRule{Match: "feedback", Reply: "some feedback"},
+		FakeLLMRule{Match: "hint and feedback", Reply: "never reached"},
+	)
+
+	tests := []struct {
+		prompt  string
+		want    string
+		wantErr bool
+	}{
+		{prompt: "give me a hint", want: "a hint"},
+		{prompt: "evaluate the feedback", want: "some feedback"},
+		{prompt: "hint and feedback", want: "a hint"}, // the first matching rule answers
+		{prompt: "customize the questions", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prompt, func(t *testing.T) {
+			got, err := client.Generate(context.Background(), LLMRequest{Prompt: tt.prompt})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Generate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Every prompt is recorded, including the ones nothing matched
+	want := []string{"give me a hint", "evaluate the feedback", "hint and feedback", "customize the questions"}
+	if got := client.Prompts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Prompts() = %q, want %q", got, want)
+	}
+}
+
+func TestFakeLLMClientStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		reply  string
+		chunks []string
+	}{
+		{name: "empty", reply: "", chunks: nil},
+		{name: "one chunk", reply: "short", chunks: []string{"short"}},
+		{name: "exact chunks", reply: "0123456789abcdef", chunks: []string{"01234567", "89abcdef"}},
+		{name: "partial last chunk", reply: `{"hint": "x"}`, chunks: []string{`{"hint":`, ` "x"}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewFakeLLMClient(FakeLLMRule{Reply: tt.reply})
+
+			var chunks []string
+			got, err := client.Stream(context.Background(), LLMRequest{Prompt: "anything"}, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if got != tt.reply {
+				t.Errorf("Stream() = %q, want %q", got, tt.reply)
+			}
+			if !reflect.DeepEqual(chunks, tt.chunks) {
+				t.Errorf("Stream() chunks = %q, want %q", chunks, tt.chunks)
+			}
+		})
+	}
+}
+
+func TestLoadFakeLLMClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{name: "rules", script: `[{"match": "hint", "reply": "a hint"}]`},
+		{name: "not json", script: `match: hint`, wantErr: "failed to parse fake LLM script"},
+		{name: "missing", wantErr: "failed to read fake LLM script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "script.json")
+			if tt.script != "" {
+				if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			client, err := LoadFakeLLMClient(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFakeLLMClient() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFakeLLMClient() error = %v", err)
+			}
+			if got, err := client.Generate(context.Background(), LLMRequest{Prompt: "a hint please"}); err != nil || got != "a hint" {
+				t.Errorf("Generate() = %q, %v, want %q", got, err, "a hint")
+			}
+		})
+	}
+}
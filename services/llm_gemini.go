@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// defaultGeminiModel is used unless LLM_MODEL names another
+const defaultGeminiModel = "gemini-2.0-flash-exp"
+
+// GeminiClient sends prompts to Google Gemini
+type GeminiClient struct {
+	client  *genai.Client
+	model   string
+	timeout time.Duration
+}
+
+// NewGeminiClient creates a Gemini client. Without an API key in the
+// configuration, the key is read from GEMINI_API_KEY or GOOGLE_API_KEY.
+func NewGeminiClient(config LLMConfig) (*GeminiClient, error) {
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{APIKey: config.APIKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	model := config.Model
+	if model == "" {
+		model = defaultGeminiModel
+	}
+
+	return &GeminiClient{
+		client:  client,
+		model:   model,
+		timeout: config.Timeout,
+	}, nil
+}
+
+// Generate sends the prompt to Gemini
+func (c *GeminiClient) Generate(ctx context.Context, req LLMRequest) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	result, err := c.client.Models.GenerateContent(ctx, c.model, genai.Text(req.Prompt), geminiConfig(req))
+	if err != nil {
+		return "", fmt.Errorf("gemini request failed: %w", err)
+	}
+
+	text := result.Text()
+	if text == "" {
+		return "", fmt.Errorf("no response from Gemini")
+	}
+	return text, nil
+}
+
+// Stream sends the prompt to Gemini and passes on the reply as it arrives
+func (c *GeminiClient) Stream(ctx context.Context, req LLMRequest, onChunk func(string)) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reply strings.Builder
+	for result, err := range c.client.Models.GenerateContentStream(ctx, c.model, genai.Text(req.Prompt), geminiConfig(req)) {
+		if err != nil {
+			return "", fmt.Errorf("gemini request failed: %w", err)
+		}
+		if text := result.Text(); text != "" {
+			reply.WriteString(text)
+			onChunk(text)
+		}
+	}
+
+	if reply.Len() == 0 {
+		return "", fmt.Errorf("no response from Gemini")
+	}
+	return reply.String(), nil
+}
+
+// geminiConfig asks Gemini for JSON matching the request's schema, if it has one
+func geminiConfig(req LLMRequest) *genai.GenerateContentConfig {
+	if req.Schema == nil {
+		return nil
+	}
+	return &genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   geminiSchema(req.Schema),
+	}
+}
+
+// geminiSchema converts a response schema to Gemini's form
+func geminiSchema(schema *JSONSchema) *genai.Schema {
+	converted := &genai.Schema{
+		Type:     genai.Type(strings.ToUpper(schema.Type)),
+		Required: schema.Required,
+		Minimum:  schema.Minimum,
+		Maximum:  schema.Maximum,
+		// Keep properties in a fixed order so streamed replies can be read as they arrive
+		PropertyOrdering: schema.Required,
+	}
+	if schema.Items != nil {
+		converted.Items = geminiSchema(schema.Items)
+	}
+	if schema.MinItems != nil {
+		converted.MinItems = genai.Ptr(int64(*schema.MinItems))
+	}
+	if schema.MaxItems != nil {
+		converted.MaxItems = genai.Ptr(int64(*schema.MaxItems))
+	}
+	if schema.MinLength != nil {
+		converted.MinLength = genai.Ptr(int64(*schema.MinLength))
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = make(map[string]*genai.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			converted.Properties[name] = geminiSchema(property)
+		}
+	}
+	return converted
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Defaults for the Ollama provider, used unless LLM_BASE_URL or LLM_MODEL are set
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// OllamaClient sends prompts to a local Ollama server
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// ollamaGenerateRequest is the body of an Ollama generate call
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	Format string `json:"format,omitempty"` // "json" keeps the reply to JSON
+}
+
+// ollamaGenerateResponse is the body Ollama returns from a generate call, or
+// one line of it when streaming
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"` // set instead of the response when the request fails
+}
+
+// NewOllamaClient creates a client for the Ollama server at the configured base URL
+func NewOllamaClient(config LLMConfig) *OllamaClient {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	model := config.Model
+	if model == "" {
+		model = defaultOllamaModel
+	}
+
+	return &OllamaClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		model:      model,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Generate sends the prompt to Ollama and waits for the whole reply
+func (c *OllamaClient) Generate(ctx context.Context, req LLMRequest) (string, error) {
+	resp, err := c.send(ctx, req, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode ollama response (status %d): %w", resp.StatusCode, err)
+	}
+	if result.Response == "" {
+		return "", fmt.Errorf("no response from ollama")
+	}
+
+	return result.Response, nil
+}
+
+// Stream sends the prompt to Ollama and reads the reply line by line as it is generated
+func (c *OllamaClient) Stream(ctx context.Context, req LLMRequest, onChunk func(string)) (string, error) {
+	resp, err := c.send(ctx, req, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var reply strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var result ollamaGenerateResponse
+		if err := decoder.Decode(&result); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to decode ollama stream: %w", err)
+		}
+		if result.Error != "" {
+			return "", fmt.Errorf("ollama stream failed: %s", result.Error)
+		}
+		if result.Response != "" {
+			reply.WriteString(result.Response)
+			onChunk(result.Response)
+		}
+		if result.Done {
+			break
+		}
+	}
+	if reply.Len() == 0 {
+		return "", fmt.Errorf("no response from ollama")
+	}
+
+	return reply.String(), nil
+}
+
+// send posts the generate request, returning the response only if Ollama accepted it
+func (c *OllamaClient) send(ctx context.Context, req LLMRequest, stream bool) (*http.Response, error) {
+	generateReq := ollamaGenerateRequest{
+		Model:  c.model,
+		Prompt: req.Prompt,
+		Stream: stream,
+	}
+	if req.Schema != nil {
+		generateReq.Format = "json"
+	}
+
+	body, err := json.Marshal(generateReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ollama request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ollama request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var result ollamaGenerateResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	return resp, nil
+}
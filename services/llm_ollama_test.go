@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOllamaClientStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		chunks  []string
+		wantErr string
+	}{
+		{
+			name:   "lines until done",
+			status: http.StatusOK,
+			body: `{"response":"{\"hint\":","done":false}` + "\n" +
+				`{"response":"","done":false}` + "\n" +
+				`{"response":" \"x\"}","done":false}` + "\n" +
+				`{"response":"","done":true}` + "\n" +
+				`{"response":"after done","done":false}` + "\n",
+			want:   `{"hint": "x"}`,
+			chunks: []string{`{"hint":`, ` "x"}`},
+		},
+		{
+			name:   "stream closed without done",
+			status: http.StatusOK,
+			body:   `{"response":"partial","done":false}`,
+			want:   "partial",
+			chunks: []string{"partial"},
+		},
+		{
+			name:    "error line",
+			status:  http.StatusOK,
+			body:    `{"response":"partial","done":false}` + "\n" + `{"error":"model crashed"}` + "\n",
+			wantErr: "ollama stream failed: model crashed",
+		},
+		{
+			name:    "malformed line",
+			status:  http.StatusOK,
+			body:    `{"response":`,
+			wantErr: "failed to decode ollama stream",
+		},
+		{
+			name:    "no content",
+			status:  http.StatusOK,
+			body:    `{"response":"","done":true}`,
+			wantErr: "no response from ollama",
+		},
+		{
+			name:    "rejected request",
+			status:  http.StatusNotFound,
+			body:    `{"error":"model not found"}`,
+			wantErr: "status 404: model not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewOllamaClient(newLLMTestServer(t, tt.status, tt.body))
+
+			var chunks []string
+			got, err := client.Stream(context.Background(), LLMRequest{Prompt: "hint"}, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Stream() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(chunks, tt.chunks) {
+				t.Errorf("Stream() chunks = %q, want %q", chunks, tt.chunks)
+			}
+		})
+	}
+}
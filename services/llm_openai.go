@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Defaults for the OpenAI-compatible provider, used unless LLM_BASE_URL or LLM_MODEL are set
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAIClient sends prompts to an OpenAI-compatible chat completions endpoint
+type OpenAIClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// openAIMessage is one message of a chat completion
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIChatRequest is the body of a chat completions call
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+}
+
+// openAIResponseFormat selects JSON mode, which endpoints support more widely than JSON schemas
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// openAIChatResponse is the body a chat completions call returns
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"` // set instead of the choices when the request is rejected
+}
+
+// openAIChatChunk is one server-sent event of a streamed chat completion
+type openAIChatChunk struct {
+	Choices []struct {
+		Delta openAIMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+// NewOpenAIClient creates a client for the chat completions endpoint under the configured base URL
+func NewOpenAIClient(config LLMConfig) *OpenAIClient {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	model := config.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	return &OpenAIClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     config.APIKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Generate sends the prompt as a single user message
+func (c *OpenAIClient) Generate(ctx context.Context, req LLMRequest) (string, error) {
+	resp, err := c.send(ctx, req, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode chat response (status %d): %w", resp.StatusCode, err)
+	}
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("no response from the chat endpoint")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// Stream sends the prompt as a single user message and reads the reply's
+// server-sent events as they arrive
+func (c *OpenAIClient) Stream(ctx context.Context, req LLMRequest, onChunk func(string)) (string, error) {
+	resp, err := c.send(ctx, req, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var reply strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to decode chat stream event: %w", err)
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			reply.WriteString(chunk.Choices[0].Delta.Content)
+			onChunk(chunk.Choices[0].Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("chat stream failed: %w", err)
+	}
+	if reply.Len() == 0 {
+		return "", fmt.Errorf("no response from the chat endpoint")
+	}
+
+	return reply.String(), nil
+}
+
+// send posts the chat completions request, returning the response only if the endpoint accepted it
+func (c *OpenAIClient) send(ctx context.Context, req LLMRequest, stream bool) (*http.Response, error) {
+	chatReq := openAIChatRequest{
+		Model:    c.model,
+		Messages: []openAIMessage{{Role: "user", Content: req.Prompt}},
+		Stream:   stream,
+	}
+	if req.Schema != nil {
+		chatReq.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chat request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("chat request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var result openAIChatResponse
+		message := ""
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Error != nil {
+			message = result.Error.Message
+		}
+		return nil, fmt.Errorf("chat endpoint returned status %d: %s", resp.StatusCode, message)
+	}
+
+	return resp, nil
+}
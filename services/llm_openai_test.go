@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClientStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		chunks  []string
+		wantErr string
+	}{
+		{
+			name:   "events until done",
+			status: http.StatusOK,
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"{\\\"hint\\\":\"}}]}\n\n" +
+				": keep-alive comment\n\n" +
+				"data:{\"choices\":[{\"delta\":{\"content\":\" \\\"x\\\"}\"}}]}\n\n" +
+				"data: [DONE]\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"after done\"}}]}\n\n",
+			want:   `{"hint": "x"}`,
+			chunks: []string{`{"hint":`, ` "x"}`},
+		},
+		{
+			name:   "stream closed without done",
+			status: http.StatusOK,
+			body:   "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n",
+			want:   "partial",
+			chunks: []string{"partial"},
+		},
+		{
+			name:    "malformed event",
+			status:  http.StatusOK,
+			body:    "data: {\"choices\":\n\n",
+			wantErr: "failed to decode chat stream event",
+		},
+		{
+			name:    "no content",
+			status:  http.StatusOK,
+			body:    "data: {\"choices\":[]}\n\ndata: [DONE]\n\n",
+			wantErr: "no response from the chat endpoint",
+		},
+		{
+			name:    "rejected request",
+			status:  http.StatusUnauthorized,
+			body:    `{"error": {"message": "invalid api key"}}`,
+			wantErr: "status 401: invalid api key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewOpenAIClient(newLLMTestServer(t, tt.status, tt.body))
+
+			var chunks []string
+			got, err := client.Stream(context.Background(), LLMRequest{Prompt: "hint"}, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Stream() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(chunks, tt.chunks) {
+				t.Errorf("Stream() chunks = %q, want %q", chunks, tt.chunks)
+			}
+		})
+	}
+}
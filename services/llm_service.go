@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"stormhacks-be/models"
+	"stormhacks-be/prompts"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+	"strings"
+)
+
+// LLMService handles AI interactions, through the language model selected by LLM_PROVIDER (Gemini by default)
+type LLMService struct {
+	llm            LLMClient
+	promptLibrary  *prompts.Library
+	repairAttempts int // times a reply that breaks its schema is sent back to be fixed
+}
+
+// NewLLMService creates a new AI service
+func NewLLMService(llm LLMClient, promptLibrary *prompts.Library, repairAttempts int) *LLMService {
+	return &LLMService{
+		llm:            llm,
+		promptLibrary:  promptLibrary,
+		repairAttempts: repairAttempts,
+	}
+}
+
+// CustomizeInterviewQuestions tailors questions based on job description and
+// resume, returning them with the version of the prompt used
+func (s *LLMService) CustomizeInterviewQuestions(ctx context.Context, session *models.InterviewSession, questions []models.QuestionBank) ([]CustomizedQuestion, string, error) {
+	// Build questions text
+	var questionsText strings.Builder
+	for i, q := range questions {
+		questionsText.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, q.BehavioralTopic, q.Question))
+	}
+	
+	// Render the session's version of the prompt
+	prompt, version, err := s.promptLibrary.Render(prompts.TaskQuestionCustomization, session.SessionID, prompts.QuestionCustomizationData{
+		JobContext: jobContext(session),
+		Questions:  questionsText.String(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	
+	// Call the model, one customized question per original question
+	var response customizedQuestionsReply
+	if err := s.generateStructured(ctx, prompt, customizedQuestionsSchema(len(questions)), &response); err != nil {
+		return nil, "", fmt.Errorf("failed to generate customized questions: %w", err)
+	}
+	
+	return customizedQuestionsFromReply(response, questions), version, nil
+}
+
+// GenerateInterviewFeedback evaluates interview responses using Gemini
+func (s *LLMService) GenerateInterviewFeedback(ctx context.Context, session *models.InterviewSession, interviewQuestionsWithAnswers []requests.QuestionWithAnswer) (*responses.InterviewFeedbackResponse, error) {
+	// Build questions with answers text
+	var questionsWithAnswersText strings.Builder
+	for i, qa := range interviewQuestionsWithAnswers {
+		questionsWithAnswersText.WriteString(fmt.Sprintf("%d. Question: %s\n   Answer: %s\n\n", i+1, qa.Question, qa.Answer))
+	}
+	
+	// Render the session's version of the prompt
+	prompt, version, err := s.promptLibrary.Render(prompts.TaskFeedbackEvaluation, session.SessionID, prompts.FeedbackEvaluationData{
+		JobContext:           jobContext(session),
+		QuestionsWithAnswers: questionsWithAnswersText.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	// Call the model, with feedback for every answered question
+	var feedbackResponse responses.InterviewFeedbackResponse
+	if err := s.generateStructured(ctx, prompt, interviewFeedbackSchema(len(interviewQuestionsWithAnswers)), &feedbackResponse); err != nil {
+		return nil, fmt.Errorf("failed to generate interview feedback: %w", err)
+	}
+	
+	// Ensure sessionID is set correctly
+	feedbackResponse.SessionID = session.SessionID
+	feedbackResponse.PromptVersion = version
+	
+	return &feedbackResponse, nil
+}
+
+// GenerateHint generates hints for interview responses using Gemini
+func (s *LLMService) GenerateHint(ctx context.Context, sessionID string, question string, userCode string, userSpeech string, previousHints []string) (*responses.HintResponse, error) {
+	// Render the session's version of the prompt
+	prompt, version, err := s.renderHintPrompt(sessionID, question, userCode, userSpeech, previousHints)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Call the model
+	var response hintReply
+	if err := s.generateStructured(ctx, prompt, hintSchema(), &response); err != nil {
+		return nil, fmt.Errorf("failed to generate hints: %w", err)
+	}
+	
+	return &responses.HintResponse{
+		ConversationalHint: response.ConversationalHint,
+		HintSummary:       response.HintSummary,
+		PromptVersion:     version,
+	}, nil
+}
+
+// renderHintPrompt renders the session's version of the hint prompt
+func (s *LLMService) renderHintPrompt(sessionID string, question string, userCode string, userSpeech string, previousHints []string) (string, string, error) {
+	return s.promptLibrary.Render(prompts.TaskHintGeneration, sessionID, prompts.HintGenerationData{
+		Question:      question,
+		UserCode:      userCode,
+		UserSpeech:    userSpeech,
+		PreviousHints: previousHints,
+	})
+}
+
+// hintReply is the model's reply to the hint prompt
+type hintReply struct {
+	ConversationalHint string `json:"conversationalHint"`
+	HintSummary       string `json:"hintSummary"`
+}
+
+// cleanJsonResponse removes markdown formatting from JSON response
+func (s *LLMService) cleanJsonResponse(responseText string) string {
+	// Remove markdown code blocks
+	cleaned := responseText
+	
+	// Remove ```json and ``` markers
+	cleaned = strings.ReplaceAll(cleaned, "```json", "")
+	cleaned = strings.ReplaceAll(cleaned, "```", "")
+	
+	// Remove any leading/trailing whitespace
+	cleaned = strings.TrimSpace(cleaned)
+	
+	// Find the first { and last } to extract just the JSON
+	start := strings.Index(cleaned, "{")
+	end := strings.LastIndex(cleaned, "}")
+	
+	if start != -1 && end != -1 && end > start {
+		cleaned = cleaned[start : end+1]
+	}
+	
+	return cleaned
+}
+
+// customizedQuestionsReply is the model's reply to the question customization prompt
+type customizedQuestionsReply struct {
+	Questions []struct {
+		BehavioralTopic string   `json:"behavioralTopic"`
+		Question        string   `json:"question"`
+		Hints           []string `json:"hints"`
+	} `json:"questions"`
+}
+
+// customizedQuestionsFromReply converts the model's reply to CustomizedQuestion models, preserving original IDs
+func customizedQuestionsFromReply(response customizedQuestionsReply, originalQuestions []models.QuestionBank) []CustomizedQuestion {
+	var customizedQuestions []CustomizedQuestion
+	for i, q := range response.Questions {
+		if i < len(originalQuestions) {
+			customizedQuestions = append(customizedQuestions, CustomizedQuestion{
+				ID:              originalQuestions[i].ID.Hex(), // Convert ObjectID to string
+				Question:        q.Question,
+				BehavioralTopic: enums.BehaviouralTopic(q.BehavioralTopic),
+				Hints:           q.Hints,
+			})
+		}
+	}
+	
+	return customizedQuestions
+}
+
+// jobContext is the job and candidate of a session, as prompts describe them
+func jobContext(session *models.InterviewSession) prompts.JobContext {
+	return prompts.JobContext{
+		JobTitle:       session.JobTitle,
+		JobInfo:        session.JobInfo,
+		CompanyName:    getStringValue(session.CompanyName),
+		AdditionalInfo: getStringValue(session.AdditionalInfo),
+		ResumeText:     session.ParsedResumeText,
+	}
+}
+
+// getStringValue safely gets string value from pointer
+func getStringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// GenerateTechnicalFeedback generates technical feedback using Gemini
+func (s *LLMService) GenerateTechnicalFeedback(ctx context.Context, sessionID string, questionInfo map[string]string, userCode string, hintsUsed int, isCompleted bool, timeTaken int, measuredComplexity string, submissionHistory string) (*responses.TechnicalFeedbackResponse, error) {
+	prompt, version, err := s.promptLibrary.Render(prompts.TaskTechnicalFeedback, sessionID, prompts.TechnicalFeedbackData{
+		JobTitle:           questionInfo["jobTitle"],
+		CompanyName:        questionInfo["companyName"],
+		Question:           questionInfo["question"],
+		Description:        questionInfo["description"],
+		Difficulty:         questionInfo["difficulty"],
+		UserCode:           userCode,
+		HintsUsed:          hintsUsed,
+		IsCompleted:        isCompleted,
+		TimeTaken:          timeTaken,
+		MeasuredComplexity: measuredComplexity,
+		SubmissionHistory:  submissionHistory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	var feedbackResponse responses.TechnicalFeedbackResponse
+	if err := s.generateStructured(ctx, prompt, technicalFeedbackSchema(), &feedbackResponse); err != nil {
+		return nil, fmt.Errorf("failed to generate technical feedback: %w", err)
+	}
+	feedbackResponse.PromptVersion = version
+
+	return &feedbackResponse, nil
+}
\ No newline at end of file
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLocalOutputBytes caps how much stdout/stderr is kept from a single run
+const maxLocalOutputBytes = 1 << 20
+
+// sandboxLimits are the rlimits applied to one sandboxed command
+type sandboxLimits struct {
+	cpuTime       time.Duration
+	memoryLimitMB int
+	fileSizeMB    int
+}
+
+// localCompileLimits are looser than the candidate program's limits; compilers are
+// trusted but still run inside the sandbox because they read untrusted source.
+// The file size limit must fit the toolchain's build artifacts.
+var localCompileLimits = sandboxLimits{cpuTime: 30 * time.Second, memoryLimitMB: 2048, fileSizeMB: 512}
+
+// localRunFileSizeMB caps the size of any file the candidate's program writes
+const localRunFileSizeMB = 5
+
+// localSystemPaths are the host paths every sandboxed command can read: the
+// system's programs and libraries, which include the language toolchains
+var localSystemPaths = []string{"/bin", "/sbin", "/lib", "/lib32", "/lib64", "/libx32", "/usr", "/etc"}
+
+// LocalRunner executes code in a sandboxed subprocess on this machine.
+// Each run gets a fresh temporary working directory, a scrubbed environment,
+// CPU/memory/file-size rlimits and (on Linux) its own namespaces: no network,
+// no view of the host's processes, and a root filesystem holding only the
+// system paths (read-only), extra read-only paths and the working directory.
+type LocalRunner struct {
+	workDir       string
+	cpuTime       time.Duration
+	memoryLimitMB int
+	readOnlyPaths []string
+}
+
+// NewLocalRunner creates a runner that executes code in local subprocesses.
+// extraReadOnlyPaths are made visible besides the system paths, for
+// toolchains installed elsewhere (e.g. ~/.pyenv).
+func NewLocalRunner(workDir string, cpuTime time.Duration, memoryLimitMB int, extraReadOnlyPaths []string) *LocalRunner {
+	return &LocalRunner{
+		workDir:       workDir,
+		cpuTime:       cpuTime,
+		memoryLimitMB: memoryLimitMB,
+		readOnlyPaths: append(append([]string(nil), localSystemPaths...), extraReadOnlyPaths...),
+	}
+}
+
+// Run compiles the program if its language needs it, then executes it in a sandboxed subprocess
+func (r *LocalRunner) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	driver, err := GetLanguageDriver(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jail the program in its own temporary directory, the only one it can write to
+	jailDir, err := os.MkdirTemp(r.workDir, "run-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	defer os.RemoveAll(jailDir)
+
+	sourcePath := filepath.Join(jailDir, driver.SourceFile())
+	if err := os.WriteFile(sourcePath, []byte(req.Code), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write source file: %w", err)
+	}
+
+	if compileCommand := driver.LocalCompileCommand(); compileCommand != "" {
+		// Share the Go build cache between runs so the standard library is only
+		// compiled once. Only the trusted compiler sees it, never the program.
+		goCache := filepath.Join(r.workDir, "go-build-cache")
+		if err := os.MkdirAll(goCache, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create build cache: %w", err)
+		}
+		compiled, err := r.runSandboxed(ctx, jailDir, compileCommand, "", localCompileLimits, []string{"GOCACHE=" + goCache}, []string{goCache})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile code: %w", err)
+		}
+		if compiled.ExitCode != 0 || compiled.Signal != "" {
+			compiled.CompileFailed = true
+			compiled.CompileOutput = compiled.Output
+			return compiled, nil
+		}
+	}
+
+	limits := sandboxLimits{cpuTime: r.cpuTime, memoryLimitMB: r.memoryLimitMB, fileSizeMB: localRunFileSizeMB}
+	if req.TimeLimit > 0 {
+		limits.cpuTime = req.TimeLimit
+	}
+	if req.MemoryLimitMB > 0 {
+		limits.memoryLimitMB = req.MemoryLimitMB
+	}
+	command := driver.LocalRunCommand(limits.memoryLimitMB)
+	limits.memoryLimitMB += driver.LocalMemoryOverheadMB()
+	return r.runSandboxed(ctx, jailDir, command, req.Stdin, limits, nil, nil)
+}
+
+// runSandboxed runs a shell command in the jail directory under the given
+// limits; of the host's writable directories it only sees the jail and extraWritable
+func (r *LocalRunner) runSandboxed(ctx context.Context, jailDir, command, stdin string, limits sandboxLimits, extraEnv []string, extraWritable []string) (*RunResult, error) {
+	// Wall clock limit leaves headroom over the CPU limit for I/O and startup
+	ctx, cancel := context.WithTimeout(ctx, 2*limits.cpuTime+time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh")
+	cmd.Env = append([]string{
+		"PATH=" + sandboxPath(),
+		"HOME=" + jailDir,
+		"TMPDIR=" + jailDir,
+		"LANG=C.UTF-8",
+	}, extraEnv...)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var output, stdout, stderr cappedBuffer
+	var outputMu sync.Mutex
+	cmd.Stdout = io.MultiWriter(&stdout, &lockedWriter{w: &output, mu: &outputMu})
+	cmd.Stderr = io.MultiWriter(&stderr, &lockedWriter{w: &output, mu: &outputMu})
+
+	status, err := runInSandbox(cmd, sandboxSpec{
+		ReadOnly: r.readOnlyPaths,
+		Writable: append([]string{jailDir}, extraWritable...),
+		Dir:      jailDir,
+		Command:  buildShellCommand(command, limits),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run code: %w", err)
+	}
+	result := &RunResult{
+		Stdout:       stdout.String(),
+		Stderr:       stderr.String(),
+		Output:       output.String(),
+		ExitCode:     status.ExitCode,
+		Signal:       status.Signal,
+		PeakMemoryKB: status.PeakMemoryKB,
+	}
+
+	result.TimedOut, result.MemoryExceeded = runLimitsHit(status, limits, errors.Is(ctx.Err(), context.DeadlineExceeded))
+	return result, nil
+}
+
+// runLimitsHit works out whether a run ended by hitting its time or memory limit
+func runLimitsHit(status *sandboxStatus, limits sandboxLimits, wallClockExceeded bool) (bool, bool) {
+	// SIGXCPU is the soft CPU rlimit; hitting the wall clock deadline means the
+	// program was blocked or sleeping
+	if status.Signal == "SIGXCPU" || wallClockExceeded {
+		return true, false
+	}
+
+	// The hard CPU rlimit sends SIGKILL to programs that ignore SIGXCPU, but so
+	// does the kernel when memory runs out, so a SIGKILL is only a time limit
+	// if the program used up its CPU time
+	if status.Signal == "SIGKILL" {
+		usedAllCPU := status.CPUTimeMs >= int64(cpuLimitSeconds(limits))*1000
+		return usedAllCPU, !usedAllCPU
+	}
+
+	// Allocations fail once the data rlimit is reached, so a program that failed
+	// while within 10% of the limit is taken to have run out of memory
+	failed := status.ExitCode != 0 || status.Signal != ""
+	return false, failed && status.PeakMemoryKB >= int64(limits.memoryLimitMB)*1024*9/10
+}
+
+// sandboxPath is the PATH sandboxed commands see, so toolchains installed
+// outside the system directories (e.g. /usr/local/go/bin) can be found
+func sandboxPath() string {
+	if path := os.Getenv("PATH"); path != "" {
+		return path
+	}
+	return "/usr/local/bin:/usr/bin:/bin"
+}
+
+// cpuLimitSeconds is the soft CPU rlimit for the limits; the rlimit is in
+// whole seconds, so the time limit is rounded up
+func cpuLimitSeconds(limits sandboxLimits) int {
+	cpuSeconds := int((limits.cpuTime + time.Second - 1) / time.Second)
+	if cpuSeconds < 1 {
+		cpuSeconds = 1
+	}
+	return cpuSeconds
+}
+
+// buildShellCommand applies the rlimits and then replaces the shell with the command
+func buildShellCommand(command string, limits sandboxLimits) string {
+	cpuSeconds := cpuLimitSeconds(limits)
+
+	// RLIMIT_DATA rather than RLIMIT_AS: V8 reserves far more address space than it uses
+	// The soft CPU limit sends SIGXCPU; the hard limit a second later is SIGKILL
+	ulimits := []string{
+		fmt.Sprintf("ulimit -t %d", cpuSeconds+1),
+		fmt.Sprintf("ulimit -S -t %d", cpuSeconds),
+		fmt.Sprintf("ulimit -d %d", limits.memoryLimitMB*1024),
+		fmt.Sprintf("ulimit -f %d", limits.fileSizeMB*2048), // 512-byte blocks in POSIX sh
+		"ulimit -c 0",
+	}
+
+	return strings.Join(ulimits, " && ") + " && exec " + command
+}
+
+// cappedBuffer is a bytes.Buffer that silently discards writes past maxLocalOutputBytes
+type cappedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := maxLocalOutputBytes - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// lockedWriter serializes writes from stdout and stderr into one buffer
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
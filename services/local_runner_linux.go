@@ -0,0 +1,433 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// sandboxInitEnv carries the sandbox description to the re-executed binary
+// that sets the sandbox up; its presence is what makes the binary do that
+const sandboxInitEnv = "STORMHACKS_SANDBOX_INIT"
+
+// sandboxSetupExitCode is the exit code of a sandbox that could not be set up
+const sandboxSetupExitCode = 125
+
+// prctl options not exported by the syscall package
+const (
+	prCapBSetDrop   = 24
+	prSetNoNewPrivs = 38
+)
+
+// sandboxDevices are the device nodes sandboxed programs can open
+var sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/random", "/dev/urandom"}
+
+// sandboxSpec is what the sandbox init needs to set up one run
+type sandboxSpec struct {
+	Root     string   // empty directory the sandbox's root filesystem is mounted on
+	ReadOnly []string // host paths visible read-only at the same path
+	Writable []string // host directories visible read-write at the same path
+	Dir      string   // working directory of the command
+	Command  string   // shell command to run
+}
+
+// sandboxStatus is how the sandboxed command ended, as the sandbox init reports it
+type sandboxStatus struct {
+	ExitCode     int    `json:"exitCode"`
+	Signal       string `json:"signal"`
+	PeakMemoryKB int64  `json:"peakMemoryKB"`
+	CPUTimeMs    int64  `json:"cpuTimeMs"`       // user and system CPU time the command used
+	Error        string `json:"error,omitempty"` // set instead when the sandbox could not be set up
+}
+
+func init() {
+	// When the runner re-executes this binary to set up a sandbox, do that instead of starting up
+	if spec := os.Getenv(sandboxInitEnv); spec != "" {
+		// Capabilities are dropped per thread, so keep the thread that starts the command
+		runtime.LockOSThread()
+		os.Exit(sandboxInit(spec))
+	}
+}
+
+// runInSandbox runs the shell command with only the given paths visible. It
+// starts this binary again in new user, mount, PID and network namespaces;
+// that process builds a root filesystem from read-only binds of readOnly and
+// read-write binds of writable, pivots into it, drops every capability and
+// runs the command. The sandbox has no network access and cannot see the
+// host's processes or any other file.
+func runInSandbox(cmd *exec.Cmd, spec sandboxSpec) (*sandboxStatus, error) {
+	root, err := os.MkdirTemp(filepath.Dir(spec.Dir), "root-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox root: %w", err)
+	}
+	defer os.Remove(root)
+	spec.Root = root
+
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sandbox: %w", err)
+	}
+
+	statusReader, statusWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox status pipe: %w", err)
+	}
+	defer statusReader.Close()
+
+	cmd.Path = "/proc/self/exe"
+	cmd.Args = []string{"sandbox-init"}
+	cmd.Env = append(cmd.Env, sandboxInitEnv+"="+string(encoded))
+	cmd.ExtraFiles = []*os.File{statusWriter}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	err = cmd.Start()
+	statusWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	waitErr := cmd.Wait()
+
+	var status sandboxStatus
+	data, _ := io.ReadAll(statusReader)
+	if len(data) == 0 {
+		// Killed before it could report, e.g. at the wall clock limit
+		var exitErr *exec.ExitError
+		if waitErr != nil && !errors.As(waitErr, &exitErr) {
+			return nil, waitErr
+		}
+		return &sandboxStatus{
+			ExitCode:  cmd.ProcessState.ExitCode(),
+			Signal:    exitSignal(cmd.ProcessState),
+			CPUTimeMs: cmd.ProcessState.UserTime().Milliseconds() + cmd.ProcessState.SystemTime().Milliseconds(),
+		}, nil
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode sandbox status: %w", err)
+	}
+	if status.Error != "" {
+		return nil, fmt.Errorf("failed to set up sandbox: %s", status.Error)
+	}
+	return &status, nil
+}
+
+// sandboxInit runs inside the new namespaces: it sets up the sandbox, runs the
+// command and reports how it ended on file descriptor 3
+func sandboxInit(encoded string) int {
+	statusFile := os.NewFile(3, "sandbox-status")
+	syscall.CloseOnExec(3)
+	report := func(status sandboxStatus) {
+		json.NewEncoder(statusFile).Encode(status)
+	}
+
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(encoded), &spec); err != nil {
+		report(sandboxStatus{Error: err.Error()})
+		return sandboxSetupExitCode
+	}
+	if err := enterSandbox(spec); err != nil {
+		report(sandboxStatus{Error: err.Error()})
+		return sandboxSetupExitCode
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", spec.Command)
+	cmd.Dir = spec.Dir
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, sandboxInitEnv+"=") {
+			cmd.Env = append(cmd.Env, variable)
+		}
+	}
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		report(sandboxStatus{Error: err.Error()})
+		return sandboxSetupExitCode
+	}
+
+	report(sandboxStatus{
+		ExitCode:     cmd.ProcessState.ExitCode(),
+		Signal:       exitSignal(cmd.ProcessState),
+		PeakMemoryKB: peakMemoryKB(cmd.ProcessState),
+		CPUTimeMs:    cmd.ProcessState.UserTime().Milliseconds() + cmd.ProcessState.SystemTime().Milliseconds(),
+	})
+	return 0
+}
+
+// enterSandbox builds the sandbox's root filesystem, pivots into it and drops
+// the capabilities the command would otherwise get as root of the user namespace
+func enterSandbox(spec sandboxSpec) error {
+	// Keep every mount below private to this namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	if err := syscall.Mount("tmpfs", spec.Root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=0755,size=16m"); err != nil {
+		return fmt.Errorf("failed to mount sandbox root: %w", err)
+	}
+
+	// A private /tmp first, so binds of directories below the host's /tmp land on top of it
+	tmp := filepath.Join(spec.Root, "tmp")
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", tmp, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777,size=64m"); err != nil {
+		return fmt.Errorf("failed to mount /tmp: %w", err)
+	}
+
+	for _, path := range spec.ReadOnly {
+		if err := bindIntoSandbox(spec.Root, path, true); err != nil {
+			return err
+		}
+	}
+	for _, path := range spec.Writable {
+		if err := bindIntoSandbox(spec.Root, path, false); err != nil {
+			return err
+		}
+	}
+	if err := mountSandboxDevices(spec.Root); err != nil {
+		return err
+	}
+
+	// A procfs for the new PID namespace, so the host's processes stay hidden.
+	// Container runtimes that mask parts of their own /proc refuse this; the
+	// command then runs without /proc rather than with the host's.
+	proc := filepath.Join(spec.Root, "proc")
+	if err := os.MkdirAll(proc, 0o555); err != nil {
+		return err
+	}
+	syscall.Mount("proc", proc, "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+
+	// Swap the host's root for the sandbox's and detach the host's entirely
+	if err := os.Chdir(spec.Root); err != nil {
+		return err
+	}
+	if err := os.Mkdir(".old-root", 0o700); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(".", ".old-root"); err != nil {
+		return fmt.Errorf("failed to pivot into sandbox root: %w", err)
+	}
+	if err := os.Chdir("/"); err != nil {
+		return err
+	}
+	if err := syscall.Unmount("/.old-root", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to detach host root: %w", err)
+	}
+	os.Remove("/.old-root")
+	if err := syscall.Mount("", "/", "", syscall.MS_REMOUNT|syscall.MS_BIND|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("failed to make sandbox root read-only: %w", err)
+	}
+
+	// Root of a user namespace has every capability in it; with an empty
+	// bounding set the command gets none when it is executed
+	for capability := 0; ; capability++ {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prCapBSetDrop, uintptr(capability), 0); errno != 0 {
+			if errno == syscall.EINVAL {
+				break
+			}
+			return fmt.Errorf("failed to drop capability %d: %w", capability, errno)
+		}
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+
+	return nil
+}
+
+// bindIntoSandbox makes a host path visible at the same path under root.
+// Symbolic links (such as /bin on merged-/usr systems) are recreated as links.
+func bindIntoSandbox(root string, path string, readOnly bool) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(root, path)
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	}
+
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	if err := syscall.Mount(path, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("failed to bind %s into sandbox: %w", path, err)
+	}
+	if !readOnly {
+		return nil
+	}
+
+	// A recursive bind keeps each submount's own flags, so each is remounted read-only
+	mountPoints, err := mountPointsUnder(target)
+	if err != nil {
+		return err
+	}
+	for _, mountPoint := range mountPoints {
+		if err := remountReadOnly(mountPoint); err != nil {
+			return fmt.Errorf("failed to make %s read-only in sandbox: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// remountReadOnly makes a bind mount read-only. Flags the mount already has
+// are kept, since a user namespace may not clear them.
+func remountReadOnly(mountPoint string) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(mountPoint, &stat); err != nil {
+		return err
+	}
+
+	flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND | syscall.MS_RDONLY)
+	for statFlag, mountFlag := range map[int64]uintptr{
+		0x2:    syscall.MS_NOSUID,
+		0x4:    syscall.MS_NODEV,
+		0x8:    syscall.MS_NOEXEC,
+		0x400:  syscall.MS_NOATIME,
+		0x800:  syscall.MS_NODIRATIME,
+		0x1000: syscall.MS_RELATIME,
+	} {
+		if int64(stat.Flags)&statFlag != 0 {
+			flags |= mountFlag
+		}
+	}
+	return syscall.Mount("", mountPoint, "", flags, "")
+}
+
+// mountPointsUnder lists the mount points at or below path, outermost first
+func mountPointsUnder(path string) ([]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mountPoints []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		// Spaces and other special characters in paths are octal escapes
+		mountPoint := unescapeMountInfo(fields[4])
+		if mountPoint == path || strings.HasPrefix(mountPoint, path+"/") {
+			mountPoints = append(mountPoints, mountPoint)
+		}
+	}
+	return mountPoints, scanner.Err()
+}
+
+// unescapeMountInfo decodes the \ooo escapes of a mountinfo path
+func unescapeMountInfo(field string) string {
+	var path strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			var value byte
+			if _, err := fmt.Sscanf(field[i+1:i+4], "%03o", &value); err == nil {
+				path.WriteByte(value)
+				i += 3
+				continue
+			}
+		}
+		path.WriteByte(field[i])
+	}
+	return path.String()
+}
+
+// mountSandboxDevices gives the sandbox a /dev holding only harmless devices
+func mountSandboxDevices(root string) error {
+	dev := filepath.Join(root, "dev")
+	if err := os.MkdirAll(dev, 0o755); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", dev, "tmpfs", syscall.MS_NOSUID|syscall.MS_NOEXEC, "mode=0755,size=64k"); err != nil {
+		return fmt.Errorf("failed to mount /dev: %w", err)
+	}
+
+	for _, device := range sandboxDevices {
+		target := filepath.Join(root, device)
+		if err := os.WriteFile(target, nil, 0o666); err != nil {
+			return err
+		}
+		if err := syscall.Mount(device, target, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("failed to bind %s into sandbox: %w", device, err)
+		}
+	}
+	for name, link := range map[string]string{"fd": "/proc/self/fd", "stdin": "/proc/self/fd/0", "stdout": "/proc/self/fd/1", "stderr": "/proc/self/fd/2"} {
+		if err := os.Symlink(link, filepath.Join(dev, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitSignal returns the name of the signal that terminated the process, if any
+func exitSignal(state *os.ProcessState) string {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalName(status.Signal())
+	}
+	return ""
+}
+
+// peakMemoryKB returns the peak resident memory of the process in kilobytes
+func peakMemoryKB(state *os.ProcessState) int64 {
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return usage.Maxrss
+	}
+	return 0
+}
+
+// signalName returns the conventional SIGXXX name Piston also reports
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	case syscall.SIGXCPU:
+		return "SIGXCPU"
+	case syscall.SIGXFSZ:
+		return "SIGXFSZ"
+	case syscall.SIGSEGV:
+		return "SIGSEGV"
+	case syscall.SIGABRT:
+		return "SIGABRT"
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	default:
+		return sig.String()
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package services
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// sandboxSpec is what a sandboxed run can see; unused off Linux
+type sandboxSpec struct {
+	ReadOnly []string
+	Writable []string
+	Dir      string
+	Command  string
+}
+
+// sandboxStatus is how the sandboxed command ended; unused off Linux
+type sandboxStatus struct {
+	ExitCode     int
+	Signal       string
+	PeakMemoryKB int64
+	CPUTimeMs    int64
+}
+
+// runInSandbox refuses to run: the sandbox needs Linux namespaces
+func runInSandbox(cmd *exec.Cmd, spec sandboxSpec) (*sandboxStatus, error) {
+	return nil, errors.New("the local code runner is only supported on linux")
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLimitsHit(t *testing.T) {
+	limits := sandboxLimits{cpuTime: 1500 * time.Millisecond, memoryLimitMB: 100}
+
+	tests := []struct {
+		name               string
+		status             sandboxStatus
+		wallClockExceeded  bool
+		wantTimedOut       bool
+		wantMemoryExceeded bool
+	}{
+		{name: "clean exit", status: sandboxStatus{PeakMemoryKB: 99 * 1024}},
+		{name: "soft cpu limit", status: sandboxStatus{Signal: "SIGXCPU", CPUTimeMs: 2000}, wantTimedOut: true},
+		{name: "wall clock", status: sandboxStatus{Signal: "SIGKILL", CPUTimeMs: 10}, wallClockExceeded: true, wantTimedOut: true},
+		{name: "hard cpu limit", status: sandboxStatus{Signal: "SIGKILL", CPUTimeMs: 3000}, wantTimedOut: true},
+		{name: "killed for memory", status: sandboxStatus{Signal: "SIGKILL", CPUTimeMs: 400, PeakMemoryKB: 40 * 1024}, wantMemoryExceeded: true},
+		{name: "failed near the memory limit", status: sandboxStatus{ExitCode: 1, PeakMemoryKB: 95 * 1024}, wantMemoryExceeded: true},
+		{name: "failed well below the memory limit", status: sandboxStatus{ExitCode: 1, PeakMemoryKB: 10 * 1024}},
+		{name: "crashed", status: sandboxStatus{Signal: "SIGSEGV", CPUTimeMs: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timedOut, memoryExceeded := runLimitsHit(&tt.status, limits, tt.wallClockExceeded)
+			if timedOut != tt.wantTimedOut || memoryExceeded != tt.wantMemoryExceeded {
+				t.Errorf("runLimitsHit() = %v, %v, want %v, %v", timedOut, memoryExceeded, tt.wantTimedOut, tt.wantMemoryExceeded)
+			}
+		})
+	}
+}
+
+func TestBuildShellCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits sandboxLimits
+		want   []string
+	}{
+		{
+			name:   "whole seconds",
+			limits: sandboxLimits{cpuTime: 2 * time.Second, memoryLimitMB: 256, fileSizeMB: 5},
+			want:   []string{"ulimit -t 3", "ulimit -S -t 2", "ulimit -d 262144", "ulimit -f 10240", "exec ./main"},
+		},
+		{
+			name:   "rounds up",
+			limits: sandboxLimits{cpuTime: 1500 * time.Millisecond, memoryLimitMB: 64, fileSizeMB: 1},
+			want:   []string{"ulimit -t 3", "ulimit -S -t 2"},
+		},
+		{
+			name:   "at least a second",
+			limits: sandboxLimits{cpuTime: 0, memoryLimitMB: 64, fileSizeMB: 1},
+			want:   []string{"ulimit -t 2", "ulimit -S -t 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildShellCommand("./main", tt.limits)
+			for _, part := range tt.want {
+				if !strings.Contains(got, part) {
+					t.Errorf("buildShellCommand() = %q, want it to contain %q", got, part)
+				}
+			}
+		})
+	}
+}
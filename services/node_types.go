@@ -0,0 +1,232 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"stormhacks-be/models"
+)
+
+// Linked lists and binary trees are written in test cases as level-order lists:
+// a ListNode as its values in order, e.g. [1,2,3], and a TreeNode as its values
+// level by level with null for missing children, e.g. [1,null,2,3]. Graphs are
+// written as adjacency lists, as in LeetCode's Clone Graph: the i-th list holds
+// the values of the neighbors of the Node whose value is i (counting from 1),
+// e.g. [[2,4],[1,3],[2,4],[1,3]], and the argument is the Node with value 1.
+// Harnesses define the node types, build them from these lists and serialize
+// returned nodes back the same way. Node values are integers.
+const (
+	listNodeType  = "ListNode"
+	treeNodeType  = "TreeNode"
+	graphNodeType = "Node"
+)
+
+// isNodeType reports whether a value type is a single ListNode, TreeNode or Node
+func isNodeType(valueType string) bool {
+	return valueType == listNodeType || valueType == treeNodeType || valueType == graphNodeType
+}
+
+// nodeTypesUsed reports whether a question's parameters or return types use ListNode, TreeNode and Node
+func nodeTypesUsed(question models.TechnicalQuestion) (bool, bool, bool) {
+	valueTypes := []string{question.ReturnType}
+	for _, parameter := range question.Parameters {
+		valueTypes = append(valueTypes, parameter.Type)
+	}
+	if question.Class != nil {
+		for _, parameter := range question.Class.Constructor {
+			valueTypes = append(valueTypes, parameter.Type)
+		}
+		for _, method := range question.Class.Methods {
+			valueTypes = append(valueTypes, method.ReturnType)
+			for _, parameter := range method.Parameters {
+				valueTypes = append(valueTypes, parameter.Type)
+			}
+		}
+	}
+
+	var usesList, usesTree, usesGraph bool
+	for _, valueType := range valueTypes {
+		baseType, _ := splitValueType(valueType)
+		usesList = usesList || baseType == listNodeType
+		usesTree = usesTree || baseType == treeNodeType
+		usesGraph = usesGraph || baseType == graphNodeType
+	}
+	return usesList, usesTree, usesGraph
+}
+
+// nodeLiteral spells a node-typed value, or an array of them, for the
+// dynamically typed languages, whose harnesses build each node from its
+// level-order or adjacency list. builders names the harness function for each node type.
+// It reports false if valueType does not involve a node type.
+func nodeLiteral(value interface{}, valueType string, builders map[string]string, literal func(interface{}) (string, error)) (string, bool, error) {
+	baseType, depth := splitValueType(valueType)
+	if !isNodeType(baseType) {
+		return "", false, nil
+	}
+
+	if depth == 0 {
+		values, err := nodeValues(value, baseType)
+		if err != nil {
+			return "", true, err
+		}
+		if values == nil {
+			values = []interface{}{}
+		}
+		valuesLiteral, err := literal(values)
+		if err != nil {
+			return "", true, err
+		}
+		return builders[baseType] + "(" + valuesLiteral + ")", true, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return "", true, fmt.Errorf("expected a list for type %s, got %v", valueType, value)
+	}
+	items := make([]string, len(list))
+	for i, item := range list {
+		itemLiteral, _, err := nodeLiteral(item, strings.TrimSuffix(valueType, "[]"), builders, literal)
+		if err != nil {
+			return "", true, err
+		}
+		items[i] = itemLiteral
+	}
+	return "[" + strings.Join(items, ", ") + "]", true, nil
+}
+
+// nodeValues checks that a node's level-order list holds integers, and null
+// only where a tree may have a missing child, or that a graph's adjacency list
+// only names the graph's nodes
+func nodeValues(value interface{}, nodeType string) ([]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a level-order list for type %s, got %v", nodeType, value)
+	}
+	if nodeType == graphNodeType {
+		if _, err := graphNeighbors(values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	for i, item := range values {
+		if item == nil && nodeType == treeNodeType && i > 0 {
+			continue
+		}
+		if _, err := integerLiteral(item); err != nil {
+			return nil, fmt.Errorf("%s value %d: %w", nodeType, i+1, err)
+		}
+	}
+	return values, nil
+}
+
+// nodeValueLiterals spells a node's level-order values for the statically typed
+// languages, with missing tree children spelled as null
+func nodeValueLiterals(value interface{}, nodeType string, null string) ([]string, error) {
+	values, err := nodeValues(value, nodeType)
+	if err != nil {
+		return nil, err
+	}
+	literals := make([]string, len(values))
+	for i, item := range values {
+		if item == nil {
+			literals[i] = null
+			continue
+		}
+		literals[i], _ = integerLiteral(item)
+	}
+	return literals, nil
+}
+
+// graphNeighbors reads an adjacency list, checking every neighbor is the value
+// of one of the graph's nodes
+func graphNeighbors(adjacency []interface{}) ([][]int, error) {
+	neighbors := make([][]int, len(adjacency))
+	for i, item := range adjacency {
+		list, ok := item.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s %d: expected a list of neighbors, got %v", graphNodeType, i+1, item)
+		}
+		neighbors[i] = make([]int, len(list))
+		for j, neighbor := range list {
+			literal, err := integerLiteral(neighbor)
+			if err != nil {
+				return nil, fmt.Errorf("%s %d neighbor %d: %w", graphNodeType, i+1, j+1, err)
+			}
+			value, _ := strconv.Atoi(literal)
+			if value < 1 || value > len(adjacency) {
+				return nil, fmt.Errorf("%s %d neighbor %d: no node has value %d", graphNodeType, i+1, j+1, value)
+			}
+			neighbors[i][j] = value
+		}
+	}
+	return neighbors, nil
+}
+
+// graphNeighborLiterals spells a graph's adjacency list for the statically typed
+// languages, as one list of neighbor values per node
+func graphNeighborLiterals(value interface{}) ([]string, error) {
+	values, err := nodeValues(value, graphNodeType)
+	if err != nil {
+		return nil, err
+	}
+	neighbors, _ := graphNeighbors(values)
+	lists := make([]string, len(neighbors))
+	for i, list := range neighbors {
+		items := make([]string, len(list))
+		for j, neighbor := range list {
+			items[j] = strconv.Itoa(neighbor)
+		}
+		lists[i] = "{" + strings.Join(items, ", ") + "}"
+	}
+	return lists, nil
+}
+
+// normalizeNodeResult spells a null result of a node return type as [], the
+// level-order list of an empty linked list or tree and the adjacency list of
+// an empty graph, so expected outputs can always be written as lists
+func normalizeNodeResult(question models.TechnicalQuestion, input string, result string) string {
+	if !question.IsDesign() {
+		if isNodeType(question.ReturnType) && strings.TrimSpace(result) == "null" {
+			return "[]"
+		}
+		return result
+	}
+
+	if question.Class == nil {
+		return result
+	}
+	operations, _, err := parseDesignInput(input)
+	if err != nil {
+		return result
+	}
+	results, ok := parseOutputValue(result).([]interface{})
+	if !ok {
+		return result
+	}
+
+	returnTypes := make(map[string]string, len(question.Class.Methods))
+	for _, method := range question.Class.Methods {
+		returnTypes[method.Name] = method.ReturnType
+	}
+	changed := false
+	for i := 1; i < len(results) && i < len(operations); i++ {
+		if results[i] == nil && isNodeType(returnTypes[operations[i]]) {
+			results[i] = []interface{}{}
+			changed = true
+		}
+	}
+	if !changed {
+		return result
+	}
+
+	normalized, err := json.Marshal(results)
+	if err != nil {
+		return result
+	}
+	return string(normalized)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestNodeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		nodeType string
+		wantErr  bool
+	}{
+		{name: "list", value: "[1, 2, 3]", nodeType: listNodeType},
+		{name: "empty list", value: "null", nodeType: listNodeType},
+		{name: "null in a list", value: "[1, null]", nodeType: listNodeType, wantErr: true},
+		{name: "tree with missing children", value: "[1, null, 2, 3]", nodeType: treeNodeType},
+		{name: "null root", value: "[null, 1]", nodeType: treeNodeType, wantErr: true},
+		{name: "fractional value", value: "[1.5]", nodeType: treeNodeType, wantErr: true},
+		{name: "not a list", value: `"1,2"`, nodeType: listNodeType, wantErr: true},
+		{name: "graph", value: "[[2, 4], [1, 3], [2, 4], [1, 3]]", nodeType: graphNodeType},
+		{name: "neighbor outside the graph", value: "[[2], [3]]", nodeType: graphNodeType, wantErr: true},
+		{name: "neighbor zero", value: "[[0]]", nodeType: graphNodeType, wantErr: true},
+		{name: "neighbors not a list", value: "[[2], 1]", nodeType: graphNodeType, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := nodeValues(parseOutputValue(tt.value), tt.nodeType); (err != nil) != tt.wantErr {
+				t.Errorf("nodeValues(%s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGraphNeighbors(t *testing.T) {
+	adjacency := parseOutputValue("[[2, 3], [1], [1]]").([]interface{})
+	got, err := graphNeighbors(adjacency)
+	if err != nil {
+		t.Fatalf("graphNeighbors() error = %v", err)
+	}
+	if want := [][]int{{2, 3}, {1}, {1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("graphNeighbors() = %v, want %v", got, want)
+	}
+}
+
+func TestNodeLiteral(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		valueType string
+		want      string
+		wantNode  bool
+		wantErr   bool
+	}{
+		{name: "not a node type", value: "[1, 2]", valueType: "int[]"},
+		{name: "list", value: "[1, 2]", valueType: "ListNode", want: "__buildList([1, 2])", wantNode: true},
+		{name: "empty tree", value: "null", valueType: "TreeNode", want: "__buildTree([])", wantNode: true},
+		{name: "tree", value: "[1, null, 2]", valueType: "TreeNode", want: "__buildTree([1, null, 2])", wantNode: true},
+		{name: "graph", value: "[[2], [1]]", valueType: "Node", want: "__buildGraph([[2], [1]])", wantNode: true},
+		{name: "array of lists", value: "[[1], []]", valueType: "ListNode[]", want: "[__buildList([1]), __buildList([])]", wantNode: true},
+		{name: "array expected", value: "[1]", valueType: "ListNode[][]", wantNode: true, wantErr: true},
+		{name: "invalid node", value: `["a"]`, valueType: "ListNode", wantNode: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isNode, err := nodeLiteral(parseOutputValue(tt.value), tt.valueType, javaScriptNodeBuilders, javaScriptLiteral)
+			if isNode != tt.wantNode || (err != nil) != tt.wantErr {
+				t.Fatalf("nodeLiteral() node = %v, error = %v, want node %v, wantErr %v", isNode, err, tt.wantNode, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("nodeLiteral() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNodeResult(t *testing.T) {
+	function := models.TechnicalQuestion{ReturnType: "ListNode"}
+	design := models.TechnicalQuestion{
+		Kind: enums.QuestionKindDesign,
+		Class: &models.ClassSpec{
+			ClassName: "Codec",
+			Methods:   []models.MethodSpec{{Name: "decode", ReturnType: "TreeNode"}, {Name: "size", ReturnType: "int"}},
+		},
+	}
+	const calls = `["Codec", "decode", "size"], [[], ["x"], []]`
+
+	tests := []struct {
+		name     string
+		question models.TechnicalQuestion
+		input    string
+		result   string
+		want     string
+	}{
+		{name: "null node", question: function, result: "null", want: "[]"},
+		{name: "node", question: function, result: "[1, 2]", want: "[1, 2]"},
+		{name: "null of another type", question: models.TechnicalQuestion{ReturnType: "int[]"}, result: "null", want: "null"},
+		{name: "design null node", question: design, input: calls, result: "[null, null, null]", want: "[null,[],null]"},
+		{name: "design without nulls", question: design, input: calls, result: "[null, [1], 0]", want: "[null, [1], 0]"},
+		{name: "design error", question: design, input: calls, result: "Error", want: "Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeNodeResult(tt.question, tt.input, tt.result); got != tt.want {
+				t.Errorf("normalizeNodeResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
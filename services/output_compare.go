@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+// defaultFloatTolerance is used when a float comparison does not set one
+const defaultFloatTolerance = 1e-6
+
+// OutputComparator decides whether a program's output answers a test case
+type OutputComparator struct {
+	spec       models.ComparisonSpec
+	design     bool // outputs are lists of call results, compared call by call
+	stdio      bool // outputs are whole programs' stdout, compared line by line
+	codeRunner CodeRunner
+	limits     models.ResourceLimits // the checker runs under the question's limits for its language
+}
+
+// CheckerFailedError reports a checker that crashed, ran out of time or memory,
+// or printed neither true nor false. The output it was judging counts as wrong.
+type CheckerFailedError struct {
+	Reason string
+}
+
+func (e *CheckerFailedError) Error() string {
+	return "checker failed: " + e.Reason
+}
+
+// NewOutputComparator creates a comparator for a question's comparison settings.
+// A question without comparison settings compares values exactly.
+func NewOutputComparator(question models.TechnicalQuestion, codeRunner CodeRunner) *OutputComparator {
+	comparator := &OutputComparator{
+		spec:       models.ComparisonSpec{Mode: enums.ComparisonModeExact},
+		design:     question.IsDesign(),
+		stdio:      question.IsStdio(),
+		codeRunner: codeRunner,
+	}
+	if question.Comparison != nil {
+		comparator.spec = *question.Comparison
+	}
+	if comparator.spec.Mode == "" {
+		comparator.spec.Mode = enums.ComparisonModeExact
+	}
+	if comparator.spec.Checker != nil {
+		comparator.limits = question.LimitsFor(comparator.spec.Checker.Language)
+	}
+	return comparator
+}
+
+// Compare reports whether actual is a correct output for the test case
+func (c *OutputComparator) Compare(testCase models.TestCase, actual string) (bool, error) {
+	if c.stdio {
+		return c.compareStdio(testCase, actual)
+	}
+
+	switch c.spec.Mode {
+	case enums.ComparisonModeExact, enums.ComparisonModeUnordered, enums.ComparisonModeFloat:
+		return c.valuesMatch(parseOutputValue(actual), parseOutputValue(testCase.ExpectedOutput)), nil
+
+	case enums.ComparisonModeAnyOf:
+		actualValue := parseOutputValue(actual)
+		for _, expected := range append([]string{testCase.ExpectedOutput}, testCase.AcceptedOutputs...) {
+			if valuesEqual(actualValue, parseOutputValue(expected), 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case enums.ComparisonModeChecker:
+		return c.runChecker(testCase, actual)
+
+	default:
+		return false, fmt.Errorf("unknown comparison mode: %s", c.spec.Mode)
+	}
+}
+
+// CompareCalls judges a design question's list of call results. Exact, unordered
+// and float modes apply to each call's result on its own; any_of and checker
+// modes judge the whole list. It also returns the 1-based positions of the
+// calls whose results differ from the expected output.
+func (c *OutputComparator) CompareCalls(testCase models.TestCase, actual string) (bool, []int, error) {
+	expected, ok := parseOutputValue(testCase.ExpectedOutput).([]interface{})
+	if !ok {
+		return false, nil, fmt.Errorf("expected output of a design question is not a list of call results: %s", testCase.ExpectedOutput)
+	}
+	results, _ := parseOutputValue(actual).([]interface{})
+
+	var mismatched []int
+	for i := 0; i < len(expected) || i < len(results); i++ {
+		if i >= len(expected) || i >= len(results) || !c.valuesMatch(results[i], expected[i]) {
+			mismatched = append(mismatched, i+1)
+		}
+	}
+
+	switch c.spec.Mode {
+	case enums.ComparisonModeAnyOf, enums.ComparisonModeChecker:
+		correct, err := c.Compare(testCase, actual)
+		if err != nil || correct {
+			return correct, nil, err
+		}
+		return false, mismatched, nil
+	default:
+		return len(mismatched) == 0, mismatched, nil
+	}
+}
+
+// valuesMatch compares two parsed values under the exact, unordered or float
+// mode; other modes compare them exactly
+func (c *OutputComparator) valuesMatch(actual, expected interface{}) bool {
+	switch c.spec.Mode {
+	case enums.ComparisonModeUnordered:
+		depth := c.spec.UnorderedDepth
+		if depth <= 0 {
+			depth = 1
+		}
+		return valuesEqual(normalizeListOrder(actual, depth), normalizeListOrder(expected, depth), 0)
+
+	case enums.ComparisonModeFloat:
+		tolerance := c.spec.Tolerance
+		if tolerance <= 0 {
+			tolerance = defaultFloatTolerance
+		}
+		return valuesEqual(actual, expected, tolerance)
+
+	default:
+		return valuesEqual(actual, expected, 0)
+	}
+}
+
+// runChecker asks the question's checker script to judge the output
+func (c *OutputComparator) runChecker(testCase models.TestCase, actual string) (bool, error) {
+	if c.spec.Checker == nil || c.spec.Checker.Code == "" {
+		return false, fmt.Errorf("comparison mode checker requires a checker script")
+	}
+
+	stdin, err := json.Marshal(map[string]string{
+		"input":    testCase.Input,
+		"expected": testCase.ExpectedOutput,
+		"actual":   actual,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	runRequest := RunRequest{
+		Language:      string(c.spec.Checker.Language),
+		Code:          c.spec.Checker.Code,
+		Stdin:         string(stdin),
+		MemoryLimitMB: c.limits.MemoryLimitMB,
+	}
+	if c.limits.TimeLimitMs > 0 {
+		runRequest.TimeLimit = time.Duration(c.limits.TimeLimitMs)*time.Millisecond + harnessStartupAllowance
+	}
+
+	result, err := c.codeRunner.Run(context.Background(), runRequest)
+	if err != nil {
+		return false, fmt.Errorf("failed to run checker: %w", err)
+	}
+	switch {
+	case result.TimedOut:
+		return false, &CheckerFailedError{Reason: "time limit exceeded"}
+	case result.MemoryExceeded:
+		return false, &CheckerFailedError{Reason: "memory limit exceeded"}
+	case result.CompileFailed:
+		return false, &CheckerFailedError{Reason: "compile error: " + strings.TrimSpace(result.CompileOutput)}
+	case result.ExitCode != 0 || result.Signal != "":
+		return false, &CheckerFailedError{Reason: fmt.Sprintf("exited with code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))}
+	}
+
+	switch verdict := parseOutputValue(lastOutputLine(result.Stdout)); verdict {
+	case true:
+		return true, nil
+	case false:
+		return false, nil
+	default:
+		return false, &CheckerFailedError{Reason: fmt.Sprintf("must print true or false, got %q", strings.TrimSpace(result.Stdout))}
+	}
+}
+
+// valuesEqual compares parsed values structurally, allowing numbers to differ by tolerance
+func valuesEqual(a, b interface{}, tolerance float64) bool {
+	switch av := a.(type) {
+	case float64, json.Number:
+		return numbersEqual(av, b, tolerance)
+
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i], tolerance) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, exists := bv[key]
+			if !exists || !valuesEqual(value, other, tolerance) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return a == b
+	}
+}
+
+// numbersEqual compares two numbers. Integers compare exactly however large
+// they are, unless a tolerance allows them to differ; other numbers compare as
+// float64, within the tolerance.
+func numbersEqual(a, b interface{}, tolerance float64) bool {
+	aInteger, aIsInteger := numberInteger(a)
+	bInteger, bIsInteger := numberInteger(b)
+	if aIsInteger && bIsInteger && (tolerance == 0 || aInteger.Cmp(bInteger) == 0) {
+		return aInteger.Cmp(bInteger) == 0
+	}
+
+	av, aOk := numberFloat(a)
+	bv, bOk := numberFloat(b)
+	if !aOk || !bOk {
+		return false
+	}
+	if av == bv || (math.IsNaN(av) && math.IsNaN(bv)) {
+		return true
+	}
+	if tolerance == 0 || math.IsInf(av, 0) || math.IsInf(bv, 0) {
+		return false
+	}
+	diff := math.Abs(av - bv)
+	return diff <= tolerance || diff <= tolerance*math.Max(math.Abs(av), math.Abs(bv))
+}
+
+// normalizeListOrder sorts lists by their canonical form down to the given depth
+func normalizeListOrder(value interface{}, depth int) interface{} {
+	list, ok := value.([]interface{})
+	if !ok || depth <= 0 {
+		return value
+	}
+
+	sorted := make([]interface{}, len(list))
+	for i, item := range list {
+		sorted[i] = normalizeListOrder(item, depth-1)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return canonicalValue(sorted[i]) < canonicalValue(sorted[j])
+	})
+	return sorted
+}
+
+// lastOutputLine returns the last non-empty line a program printed
+func lastOutputLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestOutputComparatorCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparison *models.ComparisonSpec
+		expected   string
+		accepted   []string
+		actual     string
+		want       bool
+	}{
+		{name: "exact", expected: "[1, 2, 3]", actual: "[1,2,3]", want: true},
+		{name: "exact python output", expected: `[true, null, "a"]`, actual: "[True, None, 'a']", want: true},
+		{name: "exact order matters", expected: "[1, 2, 3]", actual: "[3, 2, 1]"},
+		{name: "exact large integers", expected: "9007199254740993", actual: "9007199254740992"},
+		{name: "exact large integers equal", expected: "[12345678901234567890]", actual: "[12345678901234567890]", want: true},
+		{name: "exact whole float", expected: "3", actual: "3.0", want: true},
+		{name: "exact no tolerance", expected: "0.3", actual: "0.30000000000000004"},
+		{name: "exact strings are not numbers", expected: `"1"`, actual: "1"},
+		{
+			name:       "unordered",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeUnordered},
+			expected:   "[[1, 2], [3, 4]]",
+			actual:     "[[3, 4], [1, 2]]",
+			want:       true,
+		},
+		{
+			name:       "unordered only at the top level",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeUnordered},
+			expected:   "[[1, 2], [3, 4]]",
+			actual:     "[[4, 3], [1, 2]]",
+		},
+		{
+			name:       "unordered nested",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeUnordered, UnorderedDepth: 2},
+			expected:   "[[1, 2], [3, 4]]",
+			actual:     "[[4, 3], [2, 1]]",
+			want:       true,
+		},
+		{
+			name:       "unordered keeps duplicates",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeUnordered},
+			expected:   "[1, 1, 2]",
+			actual:     "[1, 2, 2]",
+		},
+		{
+			name:       "float within the default tolerance",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeFloat},
+			expected:   "[0.3, 2]",
+			actual:     "[0.30000000000000004, 2.0000001]",
+			want:       true,
+		},
+		{
+			name:       "float outside the tolerance",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeFloat, Tolerance: 1e-9},
+			expected:   "0.5",
+			actual:     "0.5001",
+		},
+		{
+			name:       "float relative tolerance",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeFloat},
+			expected:   "1000000000",
+			actual:     "1000000000.5",
+			want:       true,
+		},
+		{
+			name:       "float infinities",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeFloat},
+			expected:   "Infinity",
+			actual:     "inf",
+			want:       true,
+		},
+		{
+			name:       "any of the accepted outputs",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeAnyOf},
+			expected:   "[0, 1]",
+			accepted:   []string{"[1, 0]"},
+			actual:     "[1, 0]",
+			want:       true,
+		},
+		{
+			name:       "none of the accepted outputs",
+			comparison: &models.ComparisonSpec{Mode: enums.ComparisonModeAnyOf},
+			expected:   "[0, 1]",
+			accepted:   []string{"[1, 0]"},
+			actual:     "[1, 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparator := NewOutputComparator(models.TechnicalQuestion{Comparison: tt.comparison}, nil)
+			testCase := models.TestCase{ExpectedOutput: tt.expected, AcceptedOutputs: tt.accepted}
+			got, err := comparator.Compare(testCase, tt.actual)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+// stubRunner replies to every run with the same result, recording the requests
+type stubRunner struct {
+	result   *RunResult
+	requests []RunRequest
+}
+
+func (r *stubRunner) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	r.requests = append(r.requests, req)
+	return r.result, nil
+}
+
+func TestOutputComparatorChecker(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      RunResult
+		want        bool
+		wantFailure string
+	}{
+		{name: "accepted", result: RunResult{Stdout: "checking\ntrue\n"}, want: true},
+		{name: "rejected", result: RunResult{Stdout: "False"}},
+		{name: "crashed", result: RunResult{ExitCode: 1, Stderr: "KeyError: 'actual'"}, wantFailure: "exited with code 1: KeyError: 'actual'"},
+		{name: "timed out", result: RunResult{TimedOut: true, Signal: "SIGKILL"}, wantFailure: "time limit exceeded"},
+		{name: "out of memory", result: RunResult{MemoryExceeded: true, ExitCode: 1}, wantFailure: "memory limit exceeded"},
+		{name: "no verdict", result: RunResult{Stdout: "maybe"}, wantFailure: `must print true or false, got "maybe"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &stubRunner{result: &tt.result}
+			question := models.TechnicalQuestion{
+				Comparison: &models.ComparisonSpec{
+					Mode:    enums.ComparisonModeChecker,
+					Checker: &models.CheckerScript{Language: enums.CodingLanguagePython, Code: "print(True)"},
+				},
+				Limits: &models.ResourceLimits{TimeLimitMs: 500, MemoryLimitMB: 64},
+			}
+			comparator := NewOutputComparator(question, runner)
+			testCase := models.TestCase{Input: "1", ExpectedOutput: "1"}
+			got, err := comparator.Compare(testCase, "1")
+
+			var failure *CheckerFailedError
+			if tt.wantFailure != "" {
+				if !errors.As(err, &failure) || failure.Reason != tt.wantFailure {
+					t.Fatalf("Compare() error = %v, want checker failure %q", err, tt.wantFailure)
+				}
+			} else if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare() = %v, want %v", got, tt.want)
+			}
+			request := runner.requests[0]
+			if request.TimeLimit != 500*time.Millisecond+harnessStartupAllowance || request.MemoryLimitMB != 64 {
+				t.Errorf("checker ran with limits %v, %d MB, want the question's", request.TimeLimit, request.MemoryLimitMB)
+			}
+
+			// A failed checker rejects the case instead of failing the run
+			result, err := evaluateTestCase(testCase, batchCaseRun{Output: harnessCaseOutput{Status: "ok", Result: "1"}}, comparator)
+			if err != nil {
+				t.Fatalf("evaluateTestCase() error = %v", err)
+			}
+			wantVerdict := enums.VerdictWrongAnswer
+			if tt.want {
+				wantVerdict = enums.VerdictAccepted
+			}
+			if result.Verdict != wantVerdict {
+				t.Errorf("evaluateTestCase() verdict = %q, want %q", result.Verdict, wantVerdict)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseOutputValue parses a printed value into a comparable Go value.
+// It accepts JSON plus the literal forms Python and JavaScript print:
+// True/False/None, undefined, single-quoted strings, tuples and trailing commas.
+// Results are nil, bool, json.Number, float64, string, []interface{} or map[string]interface{}.
+// Numbers are json.Number, keeping the text they were printed as so integers of
+// any size survive; only infinities and NaN are float64.
+// Text that is not a literal at all is returned as a plain string.
+func parseOutputValue(text string) interface{} {
+	text = strings.TrimSpace(text)
+	p := &valueParser{input: text}
+	value, err := p.parseValue()
+	if err == nil {
+		p.skipWhitespace()
+		if p.pos == len(p.input) {
+			return value
+		}
+	}
+	return text
+}
+
+// valueParser is a small recursive descent parser for printed literals
+type valueParser struct {
+	input string
+	pos   int
+}
+
+func (p *valueParser) parseValue() (interface{}, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '[':
+		return p.parseList('[', ']')
+	case c == '(':
+		return p.parseList('(', ')')
+	case c == '{':
+		return p.parseObject()
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	default:
+		return p.parseLiteral()
+	}
+}
+
+func (p *valueParser) parseList(open, close byte) (interface{}, error) {
+	p.pos++ // consume opening bracket
+	list := []interface{}{}
+	for {
+		p.skipWhitespace()
+		if p.pos < len(p.input) && p.input[p.pos] == close {
+			p.pos++
+			return list, nil
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("unterminated list")
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case close:
+			p.pos++
+			return list, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q in list", p.input[p.pos])
+		}
+	}
+}
+
+func (p *valueParser) parseObject() (interface{}, error) {
+	p.pos++ // consume {
+	object := map[string]interface{}{}
+	for {
+		p.skipWhitespace()
+		if p.pos < len(p.input) && p.input[p.pos] == '}' {
+			p.pos++
+			return object, nil
+		}
+
+		key, err := p.parseObjectKey()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+			return nil, fmt.Errorf("expected ':' in object")
+		}
+		p.pos++
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		object[canonicalValue(key)] = value
+
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("unterminated object")
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return object, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q in object", p.input[p.pos])
+		}
+	}
+}
+
+// parseObjectKey also accepts the bare identifier keys console.log prints
+func (p *valueParser) parseObjectKey() (interface{}, error) {
+	p.skipWhitespace()
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos > start && (p.input[start] < '0' || p.input[start] > '9') {
+		rest := p.pos
+		p.skipWhitespace()
+		if p.pos < len(p.input) && p.input[p.pos] == ':' {
+			return p.input[start:rest], nil
+		}
+	}
+	p.pos = start
+	return p.parseValue()
+}
+
+func (p *valueParser) parseString() (interface{}, error) {
+	quote := p.input[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			switch esc := p.input[p.pos]; esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'u':
+				if p.pos+4 >= len(p.input) {
+					return nil, fmt.Errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.input[p.pos+1:p.pos+5], 16, 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid unicode escape: %w", err)
+				}
+				sb.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				sb.WriteByte(esc)
+			}
+			p.pos++
+		default:
+			r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+func (p *valueParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.input[p.pos] == '-' || p.input[p.pos] == '+' {
+		p.pos++
+	}
+	// Python prints float('inf') as inf, JavaScript as Infinity
+	for _, word := range []string{"Infinity", "inf"} {
+		if strings.HasPrefix(p.input[p.pos:], word) {
+			p.pos += len(word)
+			if p.input[start] == '-' {
+				return math.Inf(-1), nil
+			}
+			return math.Inf(1), nil
+		}
+	}
+	for p.pos < len(p.input) && strings.IndexByte("0123456789.eE+-", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+
+	text := p.input[start:p.pos]
+	if _, err := strconv.ParseFloat(text, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("invalid number %q", text)
+	}
+	return json.Number(text), nil
+}
+
+func (p *valueParser) parseLiteral() (interface{}, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierByte(p.input[p.pos]) {
+		p.pos++
+	}
+
+	switch word := p.input[start:p.pos]; word {
+	case "true", "True":
+		return true, nil
+	case "false", "False":
+		return false, nil
+	case "null", "None", "undefined", "nil":
+		return nil, nil
+	case "NaN", "nan":
+		return math.NaN(), nil
+	case "Infinity", "inf":
+		return math.Inf(1), nil
+	default:
+		return nil, fmt.Errorf("unexpected token %q", word)
+	}
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *valueParser) skipWhitespace() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// canonicalValue renders a parsed value as a deterministic string, used for
+// object keys and for sorting list elements in unordered comparisons
+func canonicalValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64, json.Number:
+		if integer, ok := numberInteger(v); ok {
+			return integer.String()
+		}
+		number, _ := numberFloat(v)
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = canonicalValue(item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key + ":" + canonicalValue(v[key])
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// numberFloat returns a parsed or generated number as a float64
+func numberFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		number, err := strconv.ParseFloat(string(v), 64)
+		return number, err == nil || errors.Is(err, strconv.ErrRange)
+	}
+	return 0, false
+}
+
+// numberInteger returns a number as an exact integer if it is one. Numbers
+// written with a fraction or exponent are not integers, even when whole.
+func numberInteger(value interface{}) (*big.Int, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		integer, _ := big.NewFloat(v).Int(nil)
+		return integer, true
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return nil, false
+		}
+		return new(big.Int).SetString(string(v), 10)
+	}
+	return nil, false
+}
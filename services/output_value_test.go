@@ -0,0 +1,42 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseOutputValue(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want interface{}
+	}{
+		{name: "json", text: `{"a": [1, 2.5, "x"], "b": null}`, want: map[string]interface{}{`"a"`: []interface{}{json.Number("1"), json.Number("2.5"), "x"}, `"b"`: nil}},
+		{name: "keeps large integers", text: "9007199254740993", want: json.Number("9007199254740993")},
+		{name: "keeps the number text", text: "[1.50, -0, 1e3]", want: []interface{}{json.Number("1.50"), json.Number("-0"), json.Number("1e3")}},
+		{name: "python tuple", text: "(True, None, 'a',)", want: []interface{}{true, nil, "a"}},
+		{name: "javascript object", text: "{ a: 1, b: undefined }", want: map[string]interface{}{`"a"`: json.Number("1"), `"b"`: nil}},
+		{name: "numeric keys", text: "{1: 'x', 1.0: 'y'}", want: map[string]interface{}{"1": "y"}},
+		{name: "escapes", text: `"a\né\"b"`, want: "a\né\"b"},
+		{name: "infinity", text: "[-Infinity, inf]", want: []interface{}{math.Inf(-1), math.Inf(1)}},
+		{name: "not a literal", text: "  hello world  ", want: "hello world"},
+		{name: "trailing text", text: "[1] extra", want: "[1] extra"},
+		{name: "invalid number", text: "1.2.3", want: "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOutputValue(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOutputValue(%q) = %#v, want %#v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputValueNaN(t *testing.T) {
+	got, ok := parseOutputValue("NaN").(float64)
+	if !ok || !math.IsNaN(got) {
+		t.Errorf("parseOutputValue(NaN) = %#v, want NaN", got)
+	}
+}
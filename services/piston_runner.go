@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PistonRunner executes code on a Piston API instance
+type PistonRunner struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// pistonFile is a source file sent to Piston
+type pistonFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// pistonExecuteRequest is the body of a Piston execute call
+type pistonExecuteRequest struct {
+	Language       string       `json:"language"`
+	Version        string       `json:"version"`
+	Files          []pistonFile `json:"files"`
+	Stdin          string       `json:"stdin"`
+	RunTimeout     int64        `json:"run_timeout,omitempty"`      // milliseconds
+	CompileTimeout int64        `json:"compile_timeout,omitempty"`  // milliseconds
+	RunMemoryLimit int64        `json:"run_memory_limit,omitempty"` // bytes
+}
+
+// pistonStage is the outcome of the compile or run stage
+type pistonStage struct {
+	Stdout string  `json:"stdout"`
+	Stderr string  `json:"stderr"`
+	Output string  `json:"output"`
+	Code   *int    `json:"code"`   // null when the stage was killed by a signal
+	Signal *string `json:"signal"` // null when the stage exited normally
+
+	// Reported by newer Piston versions only
+	Status *string `json:"status"` // e.g. "TO" timed out, "SG" killed by signal, "RE" runtime error
+	Memory *int64  `json:"memory"` // peak memory in bytes
+}
+
+// Piston compiles with its own generous limits; only the run stage gets the question's limits
+const pistonCompileTimeout = 10 * time.Second
+
+// pistonExecuteResponse is the body Piston returns from an execute call
+type pistonExecuteResponse struct {
+	Message string       `json:"message"` // set instead of the stages when the request is rejected
+	Compile *pistonStage `json:"compile"` // only present for compiled languages
+	Run     pistonStage  `json:"run"`
+}
+
+// NewPistonRunner creates a runner backed by the Piston API at baseURL
+func NewPistonRunner(baseURL string, apiKey string) *PistonRunner {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	return &PistonRunner{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run executes the program on Piston
+func (r *PistonRunner) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	driver, err := GetLanguageDriver(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	executeReq := pistonExecuteRequest{
+		Language: driver.RunnerLanguage(),
+		Version:  driver.Version(),
+		Files:    []pistonFile{{Name: driver.SourceFile(), Content: req.Code}},
+		Stdin:    req.Stdin,
+	}
+	if req.TimeLimit > 0 {
+		executeReq.RunTimeout = req.TimeLimit.Milliseconds()
+		executeReq.CompileTimeout = pistonCompileTimeout.Milliseconds()
+	}
+	if req.MemoryLimitMB > 0 {
+		executeReq.RunMemoryLimit = int64(req.MemoryLimitMB) << 20
+	}
+
+	body, err := json.Marshal(executeReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode piston request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"execute", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create piston request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		httpReq.Header.Set("Authorization", r.apiKey)
+	}
+
+	resp, err := r.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("piston request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result pistonExecuteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode piston response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("piston returned status %d: %s", resp.StatusCode, result.Message)
+	}
+
+	if result.Compile != nil && (result.Compile.Code == nil || *result.Compile.Code != 0) {
+		return &RunResult{
+			Stderr:        result.Compile.Stderr,
+			Output:        result.Compile.Output,
+			ExitCode:      stageExitCode(result.Compile),
+			Signal:        stageSignal(result.Compile),
+			CompileFailed: true,
+			CompileOutput: result.Compile.Output,
+		}, nil
+	}
+
+	runResult := &RunResult{
+		Stdout:   result.Run.Stdout,
+		Stderr:   result.Run.Stderr,
+		Output:   result.Run.Output,
+		ExitCode: stageExitCode(&result.Run),
+		Signal:   stageSignal(&result.Run),
+	}
+	if result.Compile != nil {
+		runResult.CompileOutput = result.Compile.Output
+	}
+	if result.Run.Memory != nil {
+		runResult.PeakMemoryKB = *result.Run.Memory / 1024
+	}
+
+	// Newer Piston versions say why a stage stopped; older ones SIGKILL a stage
+	// that runs past its timeout and report nothing else
+	if result.Run.Status != nil {
+		runResult.TimedOut = *result.Run.Status == "TO"
+	} else {
+		runResult.TimedOut = runResult.Signal == "SIGKILL"
+	}
+	failed := runResult.ExitCode != 0 || runResult.Signal != ""
+	runResult.MemoryExceeded = failed && !runResult.TimedOut &&
+		req.MemoryLimitMB > 0 && runResult.PeakMemoryKB >= int64(req.MemoryLimitMB)*1024*9/10
+
+	return runResult, nil
+}
+
+// stageExitCode returns the stage's exit code, or -1 if it was killed by a signal
+func stageExitCode(stage *pistonStage) int {
+	if stage.Code == nil {
+		return -1
+	}
+	return *stage.Code
+}
+
+// stageSignal returns the signal that killed the stage, if any
+func stageSignal(stage *pistonStage) string {
+	if stage.Signal == nil {
+		return ""
+	}
+	return *stage.Signal
+}
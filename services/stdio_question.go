@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/responses"
+)
+
+// runStdioTestCases runs a stdin/stdout program once per test case, with the
+// case's input on stdin. The program's stdout is its result and its stderr its
+// logs. Each run's time limit includes the startup allowance, so only the runner
+// judges time limits: the reported runtime is the whole program's wall time.
+// A compile error is charged to every remaining case without running them.
+func runStdioTestCases(codeRunner CodeRunner, driver LanguageDriver, code string, question models.TechnicalQuestion, testCases []models.TestCase) ([]batchCaseRun, int64, error) {
+	runs := make([]batchCaseRun, len(testCases))
+	var totalExecutionTime int64
+	limits := question.LimitsFor(driver.Language())
+
+	for i, testCase := range testCases {
+		runRequest := RunRequest{
+			Language:      string(driver.Language()),
+			Code:          code,
+			Stdin:         stdioInput(testCase.Input),
+			MemoryLimitMB: limits.MemoryLimitMB,
+		}
+		if limits.TimeLimitMs > 0 {
+			runRequest.TimeLimit = time.Duration(limits.TimeLimitMs)*time.Millisecond + harnessStartupAllowance
+		}
+
+		startTime := time.Now()
+		runResult, runErr := codeRunner.Run(context.Background(), runRequest)
+		elapsed := time.Since(startTime)
+		totalExecutionTime += elapsed.Milliseconds()
+
+		output := harnessCaseOutput{Index: i, Time: float64(elapsed.Microseconds()) / 1000}
+		if runErr == nil {
+			output.Logs = runResult.Stderr
+			if _, failed := classifyRunFailure(runResult); !failed {
+				output.Status = "ok"
+				output.Result = runResult.Stdout
+			}
+		}
+		runs[i] = batchCaseRun{Output: output, Run: runResult, RunErr: runErr}
+
+		if runErr == nil && runResult.CompileFailed {
+			for j := i + 1; j < len(testCases); j++ {
+				runs[j] = batchCaseRun{Output: harnessCaseOutput{Index: j}, Run: runResult}
+			}
+			break
+		}
+	}
+
+	return runs, totalExecutionTime, nil
+}
+
+// stdioInput ends a test case input with a newline, so programs reading whole lines see the last one
+func stdioInput(input string) string {
+	if input == "" || strings.HasSuffix(input, "\n") {
+		return input
+	}
+	return input + "\n"
+}
+
+// outputLines splits program output into lines of whitespace separated tokens,
+// dropping blank lines at the end
+func outputLines(output string) [][]string {
+	rawLines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	lines := make([][]string, len(rawLines))
+	for i, line := range rawLines {
+		lines[i] = strings.Fields(line)
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compareStdio judges a stdin/stdout program's output. Lines match if they
+// hold the same whitespace separated tokens. Float mode lets numeric tokens
+// differ by the tolerance, unordered mode ignores the order of lines, and
+// any_of mode accepts any of the test case's outputs under the exact rules.
+func (c *OutputComparator) compareStdio(testCase models.TestCase, actual string) (bool, error) {
+	switch c.spec.Mode {
+	case enums.ComparisonModeExact, enums.ComparisonModeFloat:
+		return c.MismatchedLine(testCase.ExpectedOutput, actual) == 0, nil
+
+	case enums.ComparisonModeUnordered:
+		actualLines, expectedLines := outputLines(actual), outputLines(testCase.ExpectedOutput)
+		if len(actualLines) != len(expectedLines) {
+			return false, nil
+		}
+		actualSorted, expectedSorted := sortedLines(actualLines), sortedLines(expectedLines)
+		for i := range actualSorted {
+			if actualSorted[i] != expectedSorted[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case enums.ComparisonModeAnyOf:
+		for _, expected := range append([]string{testCase.ExpectedOutput}, testCase.AcceptedOutputs...) {
+			if firstMismatchedLine(outputLines(expected), outputLines(actual), 0) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case enums.ComparisonModeChecker:
+		return c.runChecker(testCase, actual)
+
+	default:
+		return false, fmt.Errorf("unknown comparison mode: %s", c.spec.Mode)
+	}
+}
+
+// MismatchedLine returns the 1-based number of the first line of a program's
+// output that differs from the expected output, or 0 if every line matches.
+// Numeric tokens may differ by the tolerance in float mode. Unordered outputs
+// have no line to point at, so it returns 0 for them.
+func (c *OutputComparator) MismatchedLine(expected, actual string) int {
+	if c.spec.Mode == enums.ComparisonModeUnordered {
+		return 0
+	}
+	tolerance := 0.0
+	if c.spec.Mode == enums.ComparisonModeFloat {
+		tolerance = c.spec.Tolerance
+		if tolerance <= 0 {
+			tolerance = defaultFloatTolerance
+		}
+	}
+	return firstMismatchedLine(outputLines(expected), outputLines(actual), tolerance)
+}
+
+// firstMismatchedLine compares two outputs' lines token by token
+func firstMismatchedLine(expected, actual [][]string, tolerance float64) int {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		if i >= len(expected) || i >= len(actual) || !tokensMatch(expected[i], actual[i], tolerance) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// tokensMatch compares one line's tokens, allowing numbers to differ by tolerance
+func tokensMatch(expected, actual []string, tolerance float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if expected[i] == actual[i] {
+			continue
+		}
+		if tolerance == 0 {
+			return false
+		}
+		expectedNumber, expectedErr := strconv.ParseFloat(expected[i], 64)
+		actualNumber, actualErr := strconv.ParseFloat(actual[i], 64)
+		if expectedErr != nil || actualErr != nil || !valuesEqual(actualNumber, expectedNumber, tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedLines joins each line's tokens with single spaces and sorts the lines
+func sortedLines(lines [][]string) []string {
+	joined := make([]string, len(lines))
+	for i, line := range lines {
+		joined[i] = strings.Join(line, " ")
+	}
+	sort.Strings(joined)
+	return joined
+}
+
+// describeLineMismatch explains the first line of a program's output that was wrong
+func describeLineMismatch(result responses.TestCaseResult, line int) string {
+	return fmt.Sprintf("Line %d: Expected '%s', got '%s'", line,
+		outputLine(result.Expected, line), outputLine(result.Actual, line))
+}
+
+// outputLine picks one line out of a program's output, with its tokens joined by single spaces
+func outputLine(output string, line int) string {
+	lines := outputLines(output)
+	if line > len(lines) {
+		return "(missing)"
+	}
+	return strings.Join(lines[line-1], " ")
+}
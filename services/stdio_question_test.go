@@ -0,0 +1,142 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestCompareStdio(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     enums.ComparisonMode
+		expected string
+		accepted []string
+		actual   string
+		want     bool
+	}{
+		{name: "exact", mode: enums.ComparisonModeExact, expected: "1 2\n3\n", actual: "1  2 \r\n3", want: true},
+		{name: "exact trailing blank lines", mode: enums.ComparisonModeExact, expected: "yes", actual: "yes\n\n\n", want: true},
+		{name: "exact blank line in between", mode: enums.ComparisonModeExact, expected: "a\nb", actual: "a\n\nb"},
+		{name: "exact tokens are text", mode: enums.ComparisonModeExact, expected: "3", actual: "3.0"},
+		{name: "exact missing line", mode: enums.ComparisonModeExact, expected: "1\n2", actual: "1"},
+		{name: "float", mode: enums.ComparisonModeFloat, expected: "0.3 x", actual: "0.30000000000000004 x", want: true},
+		{name: "float words must match", mode: enums.ComparisonModeFloat, expected: "0.3 x", actual: "0.3 y"},
+		{name: "unordered", mode: enums.ComparisonModeUnordered, expected: "1 2\n3 4", actual: "3  4\n1 2\n", want: true},
+		{name: "unordered within a line", mode: enums.ComparisonModeUnordered, expected: "1 2\n3 4", actual: "2 1\n3 4"},
+		{name: "unordered line count", mode: enums.ComparisonModeUnordered, expected: "1\n1", actual: "1"},
+		{name: "any of", mode: enums.ComparisonModeAnyOf, expected: "0 1", accepted: []string{"1 0"}, actual: "1 0\n", want: true},
+		{name: "none of", mode: enums.ComparisonModeAnyOf, expected: "0 1", accepted: []string{"1 0"}, actual: "1 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.TechnicalQuestion{Kind: enums.QuestionKindStdio, Comparison: &models.ComparisonSpec{Mode: tt.mode}}
+			testCase := models.TestCase{ExpectedOutput: tt.expected, AcceptedOutputs: tt.accepted}
+			got, err := NewOutputComparator(question, nil).Compare(testCase, tt.actual)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMismatchedLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparison models.ComparisonSpec
+		expected   string
+		actual     string
+		want       int
+	}{
+		{name: "every line matches", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeExact}, expected: "a\nb", actual: "a\nb\n"},
+		{name: "second line differs", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeExact}, expected: "a\nb\nc", actual: "a\nx\ny", want: 2},
+		{name: "extra line", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeExact}, expected: "a", actual: "a\nb", want: 2},
+		{name: "within the tolerance", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeFloat, Tolerance: 0.01}, expected: "1.5\n2", actual: "1.505\n2"},
+		{name: "outside the tolerance", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeFloat, Tolerance: 0.01}, expected: "1.5\n2", actual: "1.5\n2.1", want: 2},
+		{name: "unordered has no line", comparison: models.ComparisonSpec{Mode: enums.ComparisonModeUnordered}, expected: "a", actual: "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.TechnicalQuestion{Kind: enums.QuestionKindStdio, Comparison: &tt.comparison}
+			if got := NewOutputComparator(question, nil).MismatchedLine(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("MismatchedLine(%q, %q) = %d, want %d", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokensMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  []string
+		actual    []string
+		tolerance float64
+		want      bool
+	}{
+		{name: "same tokens", expected: []string{"1", "a"}, actual: []string{"1", "a"}, want: true},
+		{name: "different count", expected: []string{"1"}, actual: []string{"1", "1"}, tolerance: 1},
+		{name: "numbers without tolerance", expected: []string{"1"}, actual: []string{"1.0"}},
+		{name: "numbers within tolerance", expected: []string{"1"}, actual: []string{"1.0"}, tolerance: 1e-9, want: true},
+		{name: "words with tolerance", expected: []string{"yes"}, actual: []string{"YES"}, tolerance: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokensMatch(tt.expected, tt.actual, tt.tolerance); got != tt.want {
+				t.Errorf("tokensMatch(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputLines(t *testing.T) {
+	got := outputLines("1  2\r\n\n\tx \n\n\n")
+	if want := [][]string{{"1", "2"}, {}, {"x"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("outputLines() = %q, want %q", got, want)
+	}
+	if got := outputLines("\n"); len(got) != 0 {
+		t.Errorf("outputLines() of a blank output = %q, want no lines", got)
+	}
+}
+
+func TestStdioInput(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{input: "", want: ""},
+		{input: "3\n1 2 3", want: "3\n1 2 3\n"},
+		{input: "3\n", want: "3\n"},
+	}
+	for _, tt := range tests {
+		if got := stdioInput(tt.input); got != tt.want {
+			t.Errorf("stdioInput(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRunStdioTestCases(t *testing.T) {
+	driver, _ := GetLanguageDriver(string(enums.CodingLanguagePython))
+	question := models.TechnicalQuestion{Kind: enums.QuestionKindStdio}
+	testCases := []models.TestCase{{Input: "1 2"}, {Input: "3 4"}}
+
+	runner := &stubRunner{result: &RunResult{Stdout: "3\n", Stderr: "debug\n"}}
+	runs, _, err := runStdioTestCases(runner, driver, "print(3)", question, testCases)
+	if err != nil {
+		t.Fatalf("runStdioTestCases() error = %v", err)
+	}
+	if len(runner.requests) != 2 || runner.requests[1].Stdin != "3 4\n" {
+		t.Errorf("runStdioTestCases() ran %+v, want one run per case with its input on stdin", runner.requests)
+	}
+	if output := runs[0].Output; output.Status != "ok" || output.Result != "3\n" || output.Logs != "debug\n" {
+		t.Errorf("runStdioTestCases() case output = %+v, want stdout as the result and stderr as the logs", output)
+	}
+
+	// A compile error is charged to every case from one run
+	runner = &stubRunner{result: &RunResult{CompileFailed: true, ExitCode: 1, CompileOutput: "SyntaxError"}}
+	runs, _, _ = runStdioTestCases(runner, driver, "print(", question, testCases)
+	if len(runner.requests) != 1 || runs[1].Run != runner.result {
+		t.Errorf("runStdioTestCases() ran %d times after a compile error, want the error charged to every case", len(runner.requests))
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrMalformedAIResponse is returned when the model's replies still break their
+// schema after every repair attempt
+var ErrMalformedAIResponse = errors.New("the AI model did not return a valid response")
+
+// JSONSchema describes the JSON an AI task must reply with. It is the subset of
+// JSON Schema the tasks need: enough to pass to providers that enforce a
+// response schema, and to validate replies from every provider.
+type JSONSchema struct {
+	Type       string                 `json:"type"` // object, array, string or integer
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+	Items      *JSONSchema            `json:"items,omitempty"`
+	MinItems   *int                   `json:"minItems,omitempty"`
+	MaxItems   *int                   `json:"maxItems,omitempty"`
+	MinLength  *int                   `json:"minLength,omitempty"`
+	Minimum    *float64               `json:"minimum,omitempty"`
+	Maximum    *float64               `json:"maximum,omitempty"`
+}
+
+// objectSchema is an object whose properties are all required
+func objectSchema(properties map[string]*JSONSchema) *JSONSchema {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return &JSONSchema{Type: "object", Properties: properties, Required: required}
+}
+
+// arraySchema is an array of minItems to maxItems items
+func arraySchema(items *JSONSchema, minItems int, maxItems int) *JSONSchema {
+	return &JSONSchema{Type: "array", Items: items, MinItems: &minItems, MaxItems: &maxItems}
+}
+
+// textSchema is a string that is not empty
+func textSchema() *JSONSchema {
+	minLength := 1
+	return &JSONSchema{Type: "string", MinLength: &minLength}
+}
+
+// integerSchema is an integer from minimum to maximum
+func integerSchema(minimum float64, maximum float64) *JSONSchema {
+	return &JSONSchema{Type: "integer", Minimum: &minimum, Maximum: &maximum}
+}
+
+// Validate returns everything about a decoded JSON value that breaks the
+// schema, each prefixed with where it is in the value
+func (schema *JSONSchema) Validate(value interface{}) []string {
+	return schema.validate(value, "reply")
+}
+
+func (schema *JSONSchema) validate(value interface{}, path string) []string {
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + " must be an object"}
+		}
+		var problems []string
+		for _, name := range schema.Required {
+			if object[name] == nil {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := object[name]; ok && property != nil {
+				problems = append(problems, schema.Properties[name].validate(property, path+"."+name)...)
+			}
+		}
+		return problems
+
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{path + " must be an array"}
+		}
+		var problems []string
+		if schema.MinItems != nil && len(array) < *schema.MinItems || schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s must have %s, not %d", path, describeCount(schema.MinItems, schema.MaxItems), len(array)))
+		}
+		if schema.Items != nil {
+			for i, item := range array {
+				problems = append(problems, schema.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+		return problems
+
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []string{path + " must be a string"}
+		}
+		if schema.MinLength != nil && utf8.RuneCountInString(strings.TrimSpace(text)) < *schema.MinLength {
+			return []string{path + " must not be empty"}
+		}
+		return nil
+
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return []string{path + " must be an integer"}
+		}
+		if schema.Minimum != nil && number < *schema.Minimum || schema.Maximum != nil && number > *schema.Maximum {
+			return []string{fmt.Sprintf("%s must be from %g to %g, not %g", path, *schema.Minimum, *schema.Maximum, number)}
+		}
+		return nil
+	}
+	return nil
+}
+
+// describeCount spells out how many items an array needs
+func describeCount(minItems *int, maxItems *int) string {
+	switch {
+	case minItems != nil && maxItems != nil && *minItems == *maxItems:
+		return fmt.Sprintf("exactly %d items", *minItems)
+	case minItems != nil && maxItems != nil:
+		return fmt.Sprintf("%d to %d items", *minItems, *maxItems)
+	case minItems != nil:
+		return fmt.Sprintf("at least %d items", *minItems)
+	default:
+		return fmt.Sprintf("at most %d items", *maxItems)
+	}
+}
+
+// customizedQuestionsSchema is the reply to the question customization prompt
+func customizedQuestionsSchema(count int) *JSONSchema {
+	return objectSchema(map[string]*JSONSchema{
+		"questions": arraySchema(objectSchema(map[string]*JSONSchema{
+			"behavioralTopic": textSchema(),
+			"question":        textSchema(),
+			"hints":           arraySchema(textSchema(), 3, 3),
+		}), count, count),
+	})
+}
+
+// interviewFeedbackSchema is the reply to the interview feedback prompt
+func interviewFeedbackSchema(count int) *JSONSchema {
+	return objectSchema(map[string]*JSONSchema{
+		"interviewQuestionFeedback": arraySchema(objectSchema(map[string]*JSONSchema{
+			"question":            textSchema(),
+			"score":               integerSchema(1, 10),
+			"strengths":           arraySchema(textSchema(), 3, 3),
+			"areasForImprovement": arraySchema(textSchema(), 3, 3),
+		}), count, count),
+		"hireAbilityScore": integerSchema(0, 100),
+		"overallFeedback":  arraySchema(textSchema(), 3, 3),
+	})
+}
+
+// hintSchema is the reply to the hint prompt
+func hintSchema() *JSONSchema {
+	return objectSchema(map[string]*JSONSchema{
+		"conversationalHint": textSchema(),
+		"hintSummary":        textSchema(),
+	})
+}
+
+// technicalFeedbackSchema is the reply to the technical feedback prompt
+func technicalFeedbackSchema() *JSONSchema {
+	return objectSchema(map[string]*JSONSchema{
+		"hireAbilityScore": integerSchema(0, 100),
+		"suggestions":      arraySchema(textSchema(), 3, 3),
+		"strengths":        arraySchema(textSchema(), 3, 3),
+	})
+}
+
+// generateStructured asks the model for JSON matching the schema and decodes it
+// into out. A reply that is not JSON or breaks the schema is sent back to the
+// model along with what was wrong with it, up to repairAttempts more times.
+func (s *LLMService) generateStructured(ctx context.Context, prompt string, schema *JSONSchema, out interface{}) error {
+	reply, err := s.llm.Generate(ctx, LLMRequest{Prompt: prompt, Schema: schema})
+	if err != nil {
+		return err
+	}
+	return s.repairStructured(ctx, prompt, reply, schema, out)
+}
+
+// repairStructured decodes a reply to the prompt into out, asking the model to
+// repair it for as long as it breaks the schema and repair attempts remain
+func (s *LLMService) repairStructured(ctx context.Context, prompt string, reply string, schema *JSONSchema, out interface{}) error {
+	problems := s.decodeStructured(reply, schema, out)
+	for attempt := 1; len(problems) > 0; attempt++ {
+		log.Printf("Warning: AI reply broke its schema (attempt %d of %d): %s", attempt, s.repairAttempts+1, strings.Join(problems, "; "))
+		if attempt > s.repairAttempts {
+			return fmt.Errorf("%w: %s", ErrMalformedAIResponse, strings.Join(problems, "; "))
+		}
+
+		var err error
+		reply, err = s.llm.Generate(ctx, LLMRequest{Prompt: repairPrompt(prompt, reply, problems), Schema: schema})
+		if err != nil {
+			return err
+		}
+		problems = s.decodeStructured(reply, schema, out)
+	}
+	return nil
+}
+
+// decodeStructured decodes a reply into out, returning what kept it from matching the schema
+func (s *LLMService) decodeStructured(reply string, schema *JSONSchema, out interface{}) []string {
+	cleanedText := s.cleanJsonResponse(reply)
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(cleanedText), &value); err != nil {
+		return []string{fmt.Sprintf("reply is not valid JSON: %v", err)}
+	}
+	if problems := schema.Validate(value); len(problems) > 0 {
+		return problems
+	}
+	if err := json.Unmarshal([]byte(cleanedText), out); err != nil {
+		return []string{fmt.Sprintf("reply does not decode: %v", err)}
+	}
+	return nil
+}
+
+// repairPrompt asks the model to correct a reply that broke its schema
+func repairPrompt(prompt string, reply string, problems []string) string {
+	return prompt + `
+
+YOUR PREVIOUS REPLY WAS REJECTED:
+` + reply + `
+
+It had these problems:
+- ` + strings.Join(problems, "\n- ") + `
+
+Reply again with the corrected JSON only, fixing every problem listed.`
+}
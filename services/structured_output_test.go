@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema := objectSchema(map[string]*JSONSchema{
+		"score": integerSchema(0, 100),
+		"tips":  arraySchema(textSchema(), 1, 2),
+	})
+
+	tests := []struct {
+		name  string
+		reply string
+		want  []string
+	}{
+		{name: "valid", reply: `{"score": 80, "tips": ["a", "b"], "extra": true}`},
+		{name: "not an object", reply: `[80]`, want: []string{"reply must be an object"}},
+		{name: "missing fields", reply: `{"score": null}`, want: []string{"reply.score is required", "reply.tips is required"}},
+		{name: "not an integer", reply: `{"score": 80.5, "tips": ["a"]}`, want: []string{"reply.score must be an integer"}},
+		{name: "integer as a string", reply: `{"score": "80", "tips": ["a"]}`, want: []string{"reply.score must be an integer"}},
+		{name: "out of range", reply: `{"score": 101, "tips": ["a"]}`, want: []string{"reply.score must be from 0 to 100, not 101"}},
+		{name: "too few items", reply: `{"score": 0, "tips": []}`, want: []string{"reply.tips must have 1 to 2 items, not 0"}},
+		{name: "too many items", reply: `{"score": 0, "tips": ["a", "b", "c"]}`, want: []string{"reply.tips must have 1 to 2 items, not 3"}},
+		{name: "blank item", reply: `{"score": 0, "tips": ["a", "  "]}`, want: []string{"reply.tips[1] must not be empty"}},
+		{name: "wrong item type", reply: `{"score": 0, "tips": [1]}`, want: []string{"reply.tips[0] must be a string"}},
+		{name: "not an array", reply: `{"score": 0, "tips": "a"}`, want: []string{"reply.tips must be an array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.reply), &value); err != nil {
+				t.Fatal(err)
+			}
+			if got := schema.Validate(value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeCount(t *testing.T) {
+	one, three := 1, 3
+	tests := []struct {
+		minItems, maxItems *int
+		want               string
+	}{
+		{minItems: &three, maxItems: &three, want: "exactly 3 items"},
+		{minItems: &one, maxItems: &three, want: "1 to 3 items"},
+		{minItems: &one, want: "at least 1 items"},
+		{maxItems: &three, want: "at most 3 items"},
+	}
+	for _, tt := range tests {
+		if got := describeCount(tt.minItems, tt.maxItems); got != tt.want {
+			t.Errorf("describeCount() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRepairStructured(t *testing.T) {
+	const prompt = "Give a hint."
+	const valid = `{"conversationalHint": "Try a hash map.", "hintSummary": "hash map"}`
+
+	tests := []struct {
+		name           string
+		reply          string
+		repairs        []FakeLLMRule // replies to repair prompts
+		repairAttempts int
+		want           hintReply
+		wantRepairs    int
+		wantErr        error
+	}{
+		{
+			name:           "valid reply",
+			reply:          valid,
+			repairAttempts: 2,
+			want:           hintReply{ConversationalHint: "Try a hash map.", HintSummary: "hash map"},
+		},
+		{
+			name:           "reply wrapped in markdown",
+			reply:          "Here you go:\n```json\n" + valid + "\n```",
+			repairAttempts: 2,
+			want:           hintReply{ConversationalHint: "Try a hash map.", HintSummary: "hash map"},
+		},
+		{
+			name:           "repaired on the first attempt",
+			reply:          `{"conversationalHint": "Try a hash map."}`,
+			repairs:        []FakeLLMRule{{Match: "reply.hintSummary is required", Reply: valid}},
+			repairAttempts: 2,
+			want:           hintReply{ConversationalHint: "Try a hash map.", HintSummary: "hash map"},
+			wantRepairs:    1,
+		},
+		{
+			name:           "repaired after a reply that is not JSON",
+			reply:          "Try a hash map.",
+			repairs:        []FakeLLMRule{{Match: "reply is not valid JSON", Reply: `{"conversationalHint": ""}`}, {Match: "must not be empty", Reply: valid}},
+			repairAttempts: 2,
+			want:           hintReply{ConversationalHint: "Try a hash map.", HintSummary: "hash map"},
+			wantRepairs:    2,
+		},
+		{
+			name:           "still broken after every attempt",
+			reply:          `{}`,
+			repairs:        []FakeLLMRule{{Reply: `{"hintSummary": "hash map"}`}},
+			repairAttempts: 2,
+			wantRepairs:    2,
+			wantErr:        ErrMalformedAIResponse,
+		},
+		{
+			name:           "no repair attempts",
+			reply:          `{}`,
+			repairAttempts: 0,
+			wantErr:        ErrMalformedAIResponse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := NewFakeLLMClient(tt.repairs...)
+			service := NewLLMService(llm, nil, tt.repairAttempts)
+
+			var got hintReply
+			err := service.repairStructured(context.Background(), prompt, tt.reply, hintSchema(), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("repairStructured() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("repairStructured() decoded %+v, want %+v", got, tt.want)
+			}
+
+			repairPrompts := llm.Prompts()
+			if len(repairPrompts) != tt.wantRepairs {
+				t.Fatalf("repairStructured() sent %d repair prompts, want %d", len(repairPrompts), tt.wantRepairs)
+			}
+			for _, repair := range repairPrompts {
+				if !strings.HasPrefix(repair, prompt) || !strings.Contains(repair, "YOUR PREVIOUS REPLY WAS REJECTED") {
+					t.Errorf("repair prompt does not repeat the prompt and the rejected reply: %q", repair)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+)
+
+// maxDescribedSubmissions caps how many submissions the technical feedback prompt lists
+const maxDescribedSubmissions = 20
+
+// newSubmission turns an execution's response into a submission to keep in the session's history
+func newSubmission(input requests.ExecuteTechnicalInput, response *responses.ExecuteTechnicalResponse) *models.Submission {
+	results := response.Results
+	if response.Counterexample != nil {
+		results = append(results, *response.Counterexample)
+	}
+
+	submission := &models.Submission{
+		SessionID:     input.SessionID,
+		QuestionID:    input.QuestionID,
+		Language:      response.Language,
+		Mode:          response.Mode,
+		Code:          input.Code,
+		Verdict:       response.Verdict,
+		Passed:        response.Passed,
+		Total:         response.Total,
+		Error:         response.Error,
+		Results:       make([]models.SubmissionCaseResult, len(results)),
+		ExecutionTime: response.ExecutionTime,
+	}
+	for i, result := range results {
+		submission.Results[i] = models.SubmissionCaseResult{
+			Input:    result.Input,
+			Expected: result.Expected,
+			Actual:   result.Actual,
+			Stderr:   result.Stderr,
+			Runtime:  result.Runtime,
+			Verdict:  result.Verdict,
+			Hidden:   result.Hidden,
+		}
+	}
+	return submission
+}
+
+// solvedInHistory reports whether any submission in a history was accepted in submit mode
+func solvedInHistory(history []models.Submission) bool {
+	for _, submission := range history {
+		if submission.Mode == enums.ExecutionModeSubmit && submission.Verdict == enums.VerdictAccepted {
+			return true
+		}
+	}
+	return false
+}
+
+// countHintUsages counts the hints a session was given for a question
+func countHintUsages(usages []models.HintUsage, questionID string) int {
+	count := 0
+	for _, usage := range usages {
+		if usage.QuestionID == questionID {
+			count++
+		}
+	}
+	return count
+}
+
+// describeSubmissionHistory lists a question's submissions for the technical
+// feedback prompt, oldest first, timed from the first one
+func describeSubmissionHistory(history []models.Submission) string {
+	if len(history) == 0 {
+		return "No recorded submissions"
+	}
+
+	skipped := 0
+	if len(history) > maxDescribedSubmissions {
+		skipped = len(history) - maxDescribedSubmissions
+	}
+
+	lines := make([]string, 0, maxDescribedSubmissions+1)
+	if skipped > 0 {
+		lines = append(lines, fmt.Sprintf("  (%d earlier submissions omitted)", skipped))
+	}
+	start := history[0].CreatedAt
+	for i := skipped; i < len(history); i++ {
+		submission := history[i]
+		line := fmt.Sprintf("  %d. +%s %s (%s): %s, %d/%d test cases passed", i+1,
+			submission.CreatedAt.Sub(start).Round(time.Second), submission.Mode, submission.Language,
+			submission.Verdict, submission.Passed, submission.Total)
+		if submission.Verdict == enums.VerdictPolicyViolation {
+			line += " - " + submission.Error
+		}
+		lines = append(lines, line)
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
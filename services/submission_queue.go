@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"stormhacks-be/repositories"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+	"stormhacks-be/types/responses"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSubmissionQueueFull is returned when no more submissions can be queued
+	ErrSubmissionQueueFull = errors.New("submission queue is full, try again shortly")
+
+	// ErrSubmissionNotFound is returned for unknown or expired submission IDs
+	ErrSubmissionNotFound = errors.New("submission not found")
+)
+
+// SubmissionQueueConfig holds submission queue configuration
+type SubmissionQueueConfig struct {
+	Workers   int           // code executions that may run at once
+	Capacity  int           // submissions that may wait for a worker
+	Retention time.Duration // how long finished submissions can still be fetched
+}
+
+// DefaultSubmissionQueueConfig returns a default submission queue configuration from environment variables
+func DefaultSubmissionQueueConfig() SubmissionQueueConfig {
+	workers, err := strconv.Atoi(os.Getenv("SUBMISSION_WORKERS"))
+	if err != nil || workers <= 0 {
+		workers = 4
+	}
+
+	capacity, err := strconv.Atoi(os.Getenv("SUBMISSION_QUEUE_SIZE"))
+	if err != nil || capacity <= 0 {
+		capacity = 500
+	}
+
+	retentionMinutes, err := strconv.Atoi(os.Getenv("SUBMISSION_RETENTION_MINUTES"))
+	if err != nil || retentionMinutes <= 0 {
+		retentionMinutes = 30
+	}
+
+	return SubmissionQueueConfig{
+		Workers:   workers,
+		Capacity:  capacity,
+		Retention: time.Duration(retentionMinutes) * time.Minute,
+	}
+}
+
+// submissionJob is a queued code execution and everyone waiting on it
+type submissionJob struct {
+	input       requests.ExecuteTechnicalInput
+	sequence    int64 // order the job was queued in
+	state       responses.SubmissionResponse
+	err         error // why the job failed, kept so callers waiting on it can check for known errors
+	subscribers []chan responses.SubmissionResponse
+	done        chan struct{} // closed once the job has finished
+}
+
+// customRun is a queued run against a candidate-supplied input
+type customRun struct {
+	input    requests.ExecuteCustomInput
+	response *responses.ExecuteCustomResponse
+	err      error
+	done     chan struct{} // closed once the run has finished
+}
+
+// SubmissionQueue executes code submissions, and runs against custom inputs,
+// on a bounded pool of workers. Follow-up work of a finished submission, such
+// as measuring its complexity, runs on the same workers when nothing else waits.
+type SubmissionQueue struct {
+	interviewRepo  *repositories.InterviewRepository
+	codeRunner     CodeRunner
+	codePolicy     *CodePolicy
+	executionCache *ExecutionCache
+	retention      time.Duration
+
+	pending    chan *submissionJob
+	customRuns chan *customRun
+	followUps  chan func()
+
+	mu       sync.Mutex
+	jobs     map[string]*submissionJob
+	queued   int64 // submissions ever queued
+	dequeued int64 // submissions ever picked up by a worker
+}
+
+// NewSubmissionQueue creates a submission queue and starts its workers
+func NewSubmissionQueue(config SubmissionQueueConfig, interviewRepo *repositories.InterviewRepository, codeRunner CodeRunner, codePolicy *CodePolicy, executionCache *ExecutionCache) *SubmissionQueue {
+	q := &SubmissionQueue{
+		interviewRepo:  interviewRepo,
+		codeRunner:     codeRunner,
+		codePolicy:     codePolicy,
+		executionCache: executionCache,
+		retention:      config.Retention,
+		pending:        make(chan *submissionJob, config.Capacity),
+		customRuns:     make(chan *customRun, config.Capacity),
+		followUps:      make(chan func(), config.Capacity),
+		jobs:           make(map[string]*submissionJob),
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		go q.work()
+	}
+	go q.expireFinished()
+
+	return q
+}
+
+// Enqueue queues code for execution and returns without waiting for it to run
+func (q *SubmissionQueue) Enqueue(input requests.ExecuteTechnicalInput) (*responses.SubmissionResponse, error) {
+	job, err := q.enqueue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	snapshot := q.snapshot(job)
+	return &snapshot, nil
+}
+
+// Execute queues code for execution and waits for its result
+func (q *SubmissionQueue) Execute(input requests.ExecuteTechnicalInput) (*responses.ExecuteTechnicalResponse, error) {
+	job, err := q.enqueue(input)
+	if err != nil {
+		return nil, err
+	}
+	<-job.done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job.state.Status == enums.SubmissionStatusFailed {
+		return nil, job.err
+	}
+	return job.state.Result, nil
+}
+
+// ExecuteCustom queues a run against a custom input and waits for its result.
+// Custom runs share the workers with submissions but are not tracked as submissions.
+func (q *SubmissionQueue) ExecuteCustom(input requests.ExecuteCustomInput) (*responses.ExecuteCustomResponse, error) {
+	run := &customRun{input: input, done: make(chan struct{})}
+
+	select {
+	case q.customRuns <- run:
+	default:
+		return nil, ErrSubmissionQueueFull
+	}
+	<-run.done
+
+	return run.response, run.err
+}
+
+// Get returns the current state of a submission
+func (q *SubmissionQueue) Get(submissionID string) (*responses.SubmissionResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, exists := q.jobs[submissionID]
+	if !exists {
+		return nil, ErrSubmissionNotFound
+	}
+
+	snapshot := q.snapshot(job)
+	return &snapshot, nil
+}
+
+// Subscribe returns a channel that receives the submission's current state and
+// then every change to it. The channel is closed once the submission finishes;
+// call the returned function to stop listening early.
+func (q *SubmissionQueue) Subscribe(submissionID string) (<-chan responses.SubmissionResponse, func(), error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, exists := q.jobs[submissionID]
+	if !exists {
+		return nil, nil, ErrSubmissionNotFound
+	}
+
+	// Room for every state a job can still pass through, so notifying never blocks
+	updates := make(chan responses.SubmissionResponse, 3)
+	updates <- q.snapshot(job)
+	if job.state.Status.IsFinished() {
+		close(updates)
+		return updates, func() {}, nil
+	}
+	job.subscribers = append(job.subscribers, updates)
+
+	unsubscribe := func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for i, subscriber := range job.subscribers {
+			if subscriber == updates {
+				job.subscribers = append(job.subscribers[:i], job.subscribers[i+1:]...)
+				close(updates)
+				break
+			}
+		}
+	}
+	return updates, unsubscribe, nil
+}
+
+// enqueue adds a job to the queue, failing if the queue is full
+func (q *SubmissionQueue) enqueue(input requests.ExecuteTechnicalInput) (*submissionJob, error) {
+	job := &submissionJob{
+		input: input,
+		state: responses.SubmissionResponse{
+			SubmissionID: uuid.New().String(),
+			Status:       enums.SubmissionStatusQueued,
+			CreatedAt:    time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+
+	// Holding the lock while queuing keeps sequence numbers in channel order
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.pending <- job:
+	default:
+		return nil, ErrSubmissionQueueFull
+	}
+
+	job.sequence = q.queued
+	q.queued++
+	q.jobs[job.state.SubmissionID] = job
+	return job, nil
+}
+
+// work executes queued submissions and custom runs one at a time until the
+// process exits, turning to follow-up work only when neither is waiting
+func (q *SubmissionQueue) work() {
+	for {
+		select {
+		case job := <-q.pending:
+			q.workJob(job)
+			continue
+		case run := <-q.customRuns:
+			q.workCustom(run)
+			continue
+		default:
+		}
+
+		select {
+		case job := <-q.pending:
+			q.workJob(job)
+		case run := <-q.customRuns:
+			q.workCustom(run)
+		case followUp := <-q.followUps:
+			q.runFollowUp(followUp)
+		}
+	}
+}
+
+// workCustom executes one custom run and hands its result back to the caller
+func (q *SubmissionQueue) workCustom(run *customRun) {
+	run.response, run.err = q.runCustom(run.input)
+	close(run.done)
+}
+
+// scheduleFollowUp queues work to run after a submission has finished,
+// reporting false if the queue is too full to take it
+func (q *SubmissionQueue) scheduleFollowUp(followUp func()) bool {
+	select {
+	case q.followUps <- followUp:
+		return true
+	default:
+		log.Printf("Warning: Dropped follow-up work, the submission queue is full")
+		return false
+	}
+}
+
+// workJob executes one submission, keeping its state up to date for anyone watching
+func (q *SubmissionQueue) workJob(job *submissionJob) {
+	q.mu.Lock()
+	q.dequeued++
+	startedAt := time.Now()
+	job.state.Status = enums.SubmissionStatusRunning
+	job.state.StartedAt = &startedAt
+	q.notify(job)
+	q.mu.Unlock()
+
+	result, err := q.run(job.input)
+
+	q.mu.Lock()
+	finishedAt := time.Now()
+	job.state.FinishedAt = &finishedAt
+	if err != nil {
+		job.state.Status = enums.SubmissionStatusFailed
+		job.state.Error = err.Error()
+		job.err = err
+	} else {
+		job.state.Status = enums.SubmissionStatusCompleted
+		job.state.Result = result
+	}
+	q.notify(job)
+	for _, subscriber := range job.subscribers {
+		close(subscriber)
+	}
+	job.subscribers = nil
+	close(job.done)
+	q.mu.Unlock()
+}
+
+// run executes one submission, turning a panic into a failed submission rather than a dead worker
+func (q *SubmissionQueue) run(input requests.ExecuteTechnicalInput) (result *responses.ExecuteTechnicalResponse, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Submission execution panicked: %v", recovered)
+			err = errors.New("code execution failed unexpectedly")
+		}
+	}()
+	return ExecuteCode(input, q.interviewRepo, q.codeRunner, q.codePolicy, q.executionCache, q.scheduleFollowUp)
+}
+
+// runFollowUp runs follow-up work, turning a panic into a log line rather than a dead worker
+func (q *SubmissionQueue) runFollowUp(followUp func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Follow-up work panicked: %v", recovered)
+		}
+	}()
+	followUp()
+}
+
+// runCustom executes one custom run, turning a panic into an error rather than a dead worker
+func (q *SubmissionQueue) runCustom(input requests.ExecuteCustomInput) (response *responses.ExecuteCustomResponse, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Custom run panicked: %v", recovered)
+			err = errors.New("code execution failed unexpectedly")
+		}
+	}()
+	return ExecuteCustom(input, q.interviewRepo, q.codeRunner, q.codePolicy)
+}
+
+// expireFinished periodically forgets submissions that finished longer than the retention period ago
+func (q *SubmissionQueue) expireFinished() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-q.retention)
+		q.mu.Lock()
+		for id, job := range q.jobs {
+			if job.state.FinishedAt != nil && job.state.FinishedAt.Before(cutoff) {
+				delete(q.jobs, id)
+			}
+		}
+		q.mu.Unlock()
+	}
+}
+
+// notify sends the job's state to its subscribers. Callers must hold q.mu.
+func (q *SubmissionQueue) notify(job *submissionJob) {
+	snapshot := q.snapshot(job)
+	for _, subscriber := range job.subscribers {
+		subscriber <- snapshot
+	}
+}
+
+// snapshot copies the job's state with its current queue position. Callers must hold q.mu.
+func (q *SubmissionQueue) snapshot(job *submissionJob) responses.SubmissionResponse {
+	snapshot := job.state
+	if snapshot.Status == enums.SubmissionStatusQueued {
+		snapshot.Position = int(job.sequence-q.dequeued) + 1
+	}
+	return snapshot
+}
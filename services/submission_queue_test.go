@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/requests"
+)
+
+func TestSubmissionQueueEnqueue(t *testing.T) {
+	// No workers, so every submission stays queued
+	queue := NewSubmissionQueue(SubmissionQueueConfig{Capacity: 2, Retention: time.Minute}, nil, nil, nil, nil)
+
+	var ids []string
+	for want := 1; want <= 2; want++ {
+		submission, err := queue.Enqueue(requests.ExecuteTechnicalInput{Language: enums.CodingLanguagePython})
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		if submission.Status != enums.SubmissionStatusQueued || submission.Position != want {
+			t.Errorf("Enqueue() = %s at position %d, want queued at %d", submission.Status, submission.Position, want)
+		}
+		ids = append(ids, submission.SubmissionID)
+	}
+
+	if _, err := queue.Enqueue(requests.ExecuteTechnicalInput{}); !errors.Is(err, ErrSubmissionQueueFull) {
+		t.Errorf("Enqueue() on a full queue error = %v, want %v", err, ErrSubmissionQueueFull)
+	}
+	if _, err := queue.Get("unknown"); !errors.Is(err, ErrSubmissionNotFound) {
+		t.Errorf("Get() of an unknown submission error = %v, want %v", err, ErrSubmissionNotFound)
+	}
+
+	submission, err := queue.Get(ids[1])
+	if err != nil || submission.Position != 2 {
+		t.Errorf("Get() = %+v, %v, want the second in line", submission, err)
+	}
+
+	updates, unsubscribe, err := queue.Subscribe(ids[0])
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if update := <-updates; update.Status != enums.SubmissionStatusQueued || update.Position != 1 {
+		t.Errorf("Subscribe() first update = %+v, want the queued state", update)
+	}
+	unsubscribe()
+	if _, open := <-updates; open {
+		t.Error("updates are still open after unsubscribing")
+	}
+}
+
+func TestSubmissionQueueWorkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   requests.ExecuteTechnicalInput
+		wantErr string
+	}{
+		{
+			name:    "failed submission",
+			input:   requests.ExecuteTechnicalInput{Language: "cobol"},
+			wantErr: "unsupported language: cobol",
+		},
+		{
+			// Without a repository the run panics, which must not take the worker down
+			name:    "panicking submission",
+			input:   requests.ExecuteTechnicalInput{Language: enums.CodingLanguagePython, Mode: enums.ExecutionModeSubmit, SessionID: "s"},
+			wantErr: "code execution failed unexpectedly",
+		},
+	}
+
+	queue := NewSubmissionQueue(SubmissionQueueConfig{Workers: 1, Capacity: 4, Retention: time.Minute}, nil, nil, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := queue.Execute(tt.input); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+			}
+
+			submission, err := queue.Enqueue(tt.input)
+			if err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			updates, _, err := queue.Subscribe(submission.SubmissionID)
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			var last enums.SubmissionStatus
+			for update := range updates {
+				last = update.Status
+			}
+			if last != enums.SubmissionStatusFailed {
+				t.Errorf("last update status = %s, want %s", last, enums.SubmissionStatusFailed)
+			}
+
+			finished, err := queue.Get(submission.SubmissionID)
+			if err != nil || !strings.Contains(finished.Error, tt.wantErr) || finished.FinishedAt == nil {
+				t.Errorf("Get() = %+v, %v, want a finished submission failing with %q", finished, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+	"stormhacks-be/types/responses"
+)
+
+// ErrNoReferenceSolutions is returned for questions that cannot be validated
+var ErrNoReferenceSolutions = errors.New("question has no reference solutions")
+
+// ReferenceCheck is the outcome of judging one reference solution against its question's test cases
+type ReferenceCheck struct {
+	Language   enums.CodingLanguage
+	Total      int
+	Mismatches []ReferenceMismatch
+}
+
+// ReferenceMismatch is a test case the reference solution did not pass
+type ReferenceMismatch struct {
+	Index  int // zero-based position in the question's test cases
+	Hidden bool
+	Result responses.TestCaseResult
+}
+
+// ValidateTechnicalQuestion runs every reference solution of a question
+// through the same harness and comparison used for candidates, against every
+// test case including hidden ones. A reference that does not pass a case
+// usually means the case's expected output is wrong.
+func ValidateTechnicalQuestion(question models.TechnicalQuestion, codeRunner CodeRunner) ([]ReferenceCheck, error) {
+	if len(question.ReferenceSolutions) == 0 {
+		return nil, ErrNoReferenceSolutions
+	}
+
+	comparator := NewOutputComparator(question, codeRunner)
+	var checks []ReferenceCheck
+
+	for _, reference := range question.ReferenceSolutions {
+		driver, err := GetLanguageDriver(string(reference.Language))
+		if err != nil {
+			return checks, err
+		}
+
+		caseRuns, _, err := runTestCasesBatched(codeRunner, driver, reference.Code, question, question.TestCases)
+		if err != nil {
+			return checks, err
+		}
+
+		check := ReferenceCheck{Language: reference.Language, Total: len(question.TestCases)}
+		for i, testCase := range question.TestCases {
+			result, err := evaluateTestCase(testCase, caseRuns[i], comparator)
+			if err != nil {
+				return checks, err
+			}
+			if result.Verdict != enums.VerdictAccepted {
+				check.Mismatches = append(check.Mismatches, ReferenceMismatch{Index: i, Hidden: testCase.Hidden, Result: result})
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+func TestValidateTechnicalQuestion(t *testing.T) {
+	// Every reference returns 2 for both cases
+	const reply = "@@{{nonce}} BEGIN 0@@\n\n@@{{nonce}} END 0 {\"status\": \"ok\", \"result\": \"2\"}@@\n" +
+		"@@{{nonce}} BEGIN 1@@\n\n@@{{nonce}} END 1 {\"status\": \"ok\", \"result\": \"2\"}@@\n"
+	python := models.ReferenceSolution{Language: enums.CodingLanguagePython, Code: "def solve(x): return 2"}
+	javaScript := models.ReferenceSolution{Language: enums.CodingLanguageJavaScript, Code: "function solve(x) { return 2; }"}
+
+	tests := []struct {
+		name           string
+		references     []models.ReferenceSolution
+		expected       []string
+		wantMismatches map[enums.CodingLanguage][]int
+		wantErr        error
+	}{
+		{name: "no references", expected: []string{"2", "2"}, wantErr: ErrNoReferenceSolutions},
+		{
+			name:           "every case passes",
+			references:     []models.ReferenceSolution{python, javaScript},
+			expected:       []string{"2", "2"},
+			wantMismatches: map[enums.CodingLanguage][]int{},
+		},
+		{
+			name:           "wrong expected output",
+			references:     []models.ReferenceSolution{python, javaScript},
+			expected:       []string{"2", "3"},
+			wantMismatches: map[enums.CodingLanguage][]int{enums.CodingLanguagePython: {1}, enums.CodingLanguageJavaScript: {1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.TechnicalQuestion{FunctionName: "solve", ReferenceSolutions: tt.references}
+			for i, expected := range tt.expected {
+				question.TestCases = append(question.TestCases, models.TestCase{Input: "1", ExpectedOutput: expected, Hidden: i > 0})
+			}
+
+			checks, err := ValidateTechnicalQuestion(question, harnessReply(reply, RunResult{}))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateTechnicalQuestion() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			mismatches := map[enums.CodingLanguage][]int{}
+			for _, check := range checks {
+				if check.Total != len(tt.expected) {
+					t.Errorf("%s checked %d cases, want %d", check.Language, check.Total, len(tt.expected))
+				}
+				for _, mismatch := range check.Mismatches {
+					if !mismatch.Hidden || mismatch.Result.Verdict != enums.VerdictWrongAnswer {
+						t.Errorf("%s mismatch %+v, want a hidden wrong answer", check.Language, mismatch)
+					}
+					mismatches[check.Language] = append(mismatches[check.Language], mismatch.Index)
+				}
+			}
+			if !reflect.DeepEqual(mismatches, tt.wantMismatches) {
+				t.Errorf("ValidateTechnicalQuestion() mismatches = %v, want %v", mismatches, tt.wantMismatches)
+			}
+		})
+	}
+}
@@ -5,6 +5,10 @@ type CodingLanguage string
 const (
 	CodingLanguagePython     CodingLanguage = "python"
 	CodingLanguageJavaScript CodingLanguage = "js"
+	CodingLanguageTypeScript CodingLanguage = "typescript"
+	CodingLanguageGo         CodingLanguage = "go"
+	CodingLanguageJava       CodingLanguage = "java"
+	CodingLanguageCpp        CodingLanguage = "cpp"
 )
 
 // GetAllCodingLanguages returns all available coding languages
@@ -12,18 +16,17 @@ func GetAllCodingLanguages() []CodingLanguage {
 	return []CodingLanguage{
 		CodingLanguagePython,
 		CodingLanguageJavaScript,
+		CodingLanguageTypeScript,
+		CodingLanguageGo,
+		CodingLanguageJava,
+		CodingLanguageCpp,
 	}
 }
 
 // IsValidCodingLanguage checks if a language is valid
 func IsValidCodingLanguage(language string) bool {
-	validLanguages := []string{
-		string(CodingLanguagePython),
-		string(CodingLanguageJavaScript),
-	}
-	
-	for _, validLang := range validLanguages {
-		if language == validLang {
+	for _, validLang := range GetAllCodingLanguages() {
+		if language == string(validLang) {
 			return true
 		}
 	}
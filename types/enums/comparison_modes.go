@@ -0,0 +1,21 @@
+package enums
+
+// ComparisonMode represents how a program's output is judged against a test case
+type ComparisonMode string
+
+const (
+	// ComparisonModeExact requires the parsed values to be structurally equal
+	ComparisonModeExact ComparisonMode = "exact"
+
+	// ComparisonModeUnordered ignores element order in lists
+	ComparisonModeUnordered ComparisonMode = "unordered"
+
+	// ComparisonModeFloat compares numbers within a tolerance
+	ComparisonModeFloat ComparisonMode = "float"
+
+	// ComparisonModeAnyOf accepts any one of several expected outputs
+	ComparisonModeAnyOf ComparisonMode = "any_of"
+
+	// ComparisonModeChecker delegates the decision to a custom checker script
+	ComparisonModeChecker ComparisonMode = "checker"
+)
@@ -0,0 +1,20 @@
+package enums
+
+// ExecutionMode represents which test cases a code execution is judged against
+type ExecutionMode string
+
+const (
+	// ExecutionModeRun executes the sample test cases only
+	ExecutionModeRun ExecutionMode = "run"
+
+	// ExecutionModeSubmit executes every test case, including hidden ones, and records a final verdict
+	ExecutionModeSubmit ExecutionMode = "submit"
+
+	// ExecutionModeStress compares the code with a trusted solution on random inputs, looking for one that breaks it
+	ExecutionModeStress ExecutionMode = "stress"
+)
+
+// IsValidExecutionMode checks if an execution mode is valid
+func IsValidExecutionMode(mode string) bool {
+	return mode == string(ExecutionModeRun) || mode == string(ExecutionModeSubmit) || mode == string(ExecutionModeStress)
+}
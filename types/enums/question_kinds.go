@@ -0,0 +1,17 @@
+package enums
+
+// QuestionKind represents what a technical question asks candidates to write
+type QuestionKind string
+
+const (
+	// QuestionKindFunction asks for a single function, called once per test case
+	QuestionKindFunction QuestionKind = "function"
+
+	// QuestionKindDesign asks for a class, which each test case constructs and
+	// then calls a sequence of methods on (e.g. an LRU cache or a min stack)
+	QuestionKindDesign QuestionKind = "design"
+
+	// QuestionKindStdio asks for a whole program that reads each test case's
+	// input from stdin and writes its answer to stdout, as in programming contests
+	QuestionKindStdio QuestionKind = "stdio"
+)
@@ -0,0 +1,10 @@
+package enums
+
+// ReplayEventType is the kind of an event in a coding session replay
+type ReplayEventType string
+
+const (
+	ReplayEventEdit      ReplayEventType = "edit"      // the code changed
+	ReplayEventHint      ReplayEventType = "hint"      // a hint was given
+	ReplayEventExecution ReplayEventType = "execution" // the code was run or submitted
+)
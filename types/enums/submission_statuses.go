@@ -0,0 +1,23 @@
+package enums
+
+// SubmissionStatus represents where a queued code execution is in its lifecycle
+type SubmissionStatus string
+
+const (
+	// SubmissionStatusQueued is waiting for a free worker
+	SubmissionStatusQueued SubmissionStatus = "queued"
+
+	// SubmissionStatusRunning is being executed by a worker
+	SubmissionStatusRunning SubmissionStatus = "running"
+
+	// SubmissionStatusCompleted finished executing and has a result
+	SubmissionStatusCompleted SubmissionStatus = "completed"
+
+	// SubmissionStatusFailed could not be executed and has an error
+	SubmissionStatusFailed SubmissionStatus = "failed"
+)
+
+// IsFinished reports whether the submission will not change any more
+func (s SubmissionStatus) IsFinished() bool {
+	return s == SubmissionStatusCompleted || s == SubmissionStatusFailed
+}
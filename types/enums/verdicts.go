@@ -0,0 +1,14 @@
+package enums
+
+// Verdict represents the judged outcome of running code against a test case
+type Verdict string
+
+const (
+	VerdictAccepted            Verdict = "Accepted"
+	VerdictWrongAnswer         Verdict = "WrongAnswer"
+	VerdictRuntimeError        Verdict = "RuntimeError"
+	VerdictCompileError        Verdict = "CompileError"
+	VerdictTimeLimitExceeded   Verdict = "TimeLimitExceeded"
+	VerdictMemoryLimitExceeded Verdict = "MemoryLimitExceeded"
+	VerdictPolicyViolation     Verdict = "PolicyViolation" // refused by the code policy without running
+)
@@ -0,0 +1,19 @@
+package requests
+
+// EditorSnapshotInput records the candidate's code for a question, either as
+// the whole code or as a change to the snapshot numbered BaseSequence
+type EditorSnapshotInput struct {
+	SessionID    string          `json:"sessionId" validate:"required"`
+	QuestionID   string          `json:"questionId" validate:"required"`
+	Code         *string         `json:"code,omitempty"`
+	Delta        *CodeDeltaInput `json:"delta,omitempty"`
+	BaseSequence int             `json:"baseSequence,omitempty"` // required with delta
+}
+
+// CodeDeltaInput replaces Deleted characters at Start with Inserted,
+// counting Unicode characters
+type CodeDeltaInput struct {
+	Start    int    `json:"start"`
+	Deleted  int    `json:"deleted"`
+	Inserted string `json:"inserted"`
+}
@@ -0,0 +1,11 @@
+package requests
+
+import "stormhacks-be/types/enums"
+
+// ExecuteCustomInput represents the input for running code against a candidate-supplied input
+type ExecuteCustomInput struct {
+	QuestionID string               `json:"questionId" validate:"required"`
+	Code       string               `json:"code" validate:"required"`
+	Language   enums.CodingLanguage `json:"language" validate:"required"`
+	Input      string               `json:"input" validate:"required"` // arguments in the same format as a test case input
+}
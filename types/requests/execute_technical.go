@@ -7,4 +7,7 @@ type ExecuteTechnicalInput struct {
 	QuestionID string                `json:"questionId" validate:"required"`
 	Code       string                `json:"code" validate:"required"`
 	Language   enums.CodingLanguage  `json:"language" validate:"required"`
+	Mode       enums.ExecutionMode   `json:"mode,omitempty"`      // "run" (default), "submit" or "stress"
+	SessionID  string                `json:"sessionId,omitempty"` // required to submit
+	Seed       int64                 `json:"seed,omitempty"`      // stress mode: repeats the same random inputs, random if zero
 }
@@ -3,8 +3,13 @@ package requests
 type TechnicalFeedbackInput struct {
 	SessionID    string `json:"sessionId" validate:"required"`
 	QuestionID   string `json:"questionId" validate:"required"`
+
+	// Only used when the session has no recorded submissions for the question,
+	// and the feedback is then marked unverified; otherwise the final code,
+	// completion and hint count come from its history
 	UserCode     string `json:"userCode" validate:"required"`
 	HintsUsed    int    `json:"hintsUsed" validate:"required"`
 	IsCompleted  bool   `json:"isCompleted" validate:"required"`
+
 	TimeTaken    int    `json:"timeTaken" validate:"required"` // in seconds
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package responses
+
+import "stormhacks-be/types/enums"
+
+// CustomRunResult is what one program produced for a custom input
+type CustomRunResult struct {
+	ReturnValue string        `json:"returnValue"`
+	Logs        string        `json:"logs"` // what the code printed, kept apart from the return value
+	Stderr      string        `json:"stderr,omitempty"`
+	Runtime     int64         `json:"runtime"`           // in milliseconds
+	Verdict     enums.Verdict `json:"verdict,omitempty"` // set only if the run failed
+}
+
+// ExecuteCustomResponse represents the response for running code against a custom input
+type ExecuteCustomResponse struct {
+	QuestionID    string           `json:"questionId"`
+	Language      string           `json:"language"`
+	Input         string           `json:"input"`
+	Result        CustomRunResult  `json:"result"`
+	Reference     *CustomRunResult `json:"reference,omitempty"` // the reference solution's result, if the question has one
+	ExecutionTime int64            `json:"executionTime"`       // in milliseconds
+}
@@ -1,12 +1,46 @@
 package responses
 
+import "stormhacks-be/types/enums"
+
+// TestCaseResult represents the judged result of a single test case
+type TestCaseResult struct {
+	Input    string        `json:"input"`
+	Expected string        `json:"expected"`
+	Actual   string        `json:"actual"`
+	Logs     string        `json:"logs"` // what the code printed, kept apart from the return value
+	Stderr   string        `json:"stderr,omitempty"`
+	Runtime  int64         `json:"runtime"` // in milliseconds
+	Verdict  enums.Verdict `json:"verdict"`
+	Hidden   bool          `json:"hidden,omitempty"` // details of hidden test cases are withheld
+
+	// Design questions only: 1-based positions of the calls whose results are wrong
+	MismatchedCalls []int `json:"mismatchedCalls,omitempty"`
+
+	// Stdin/stdout questions only: 1-based number of the first output line that is wrong
+	MismatchedLine int `json:"mismatchedLine,omitempty"`
+}
+
 // ExecuteTechnicalResponse represents the response for code execution
 type ExecuteTechnicalResponse struct {
 	QuestionID   string `json:"questionId"`
 	Code         string `json:"code"`
 	Language     string `json:"language"`
+	Mode         enums.ExecutionMode `json:"mode"`
 	Output       string `json:"output"`
 	Error        string `json:"error,omitempty"`
 	ExecutionTime int64 `json:"executionTime"` // in milliseconds
 	Success      bool   `json:"success"`
+	Verdict      enums.Verdict    `json:"verdict"`
+	Passed       int              `json:"passed"`
+	Total        int              `json:"total"`
+	Results      []TestCaseResult `json:"results"`
+	Cached       bool             `json:"cached,omitempty"` // the result of an earlier run of the same code
+
+	// Submit mode only, for accepted submissions to questions with an input generator:
+	// the time complexity is being measured and will be attached to the submission
+	ComplexityPending bool `json:"complexityPending,omitempty"`
+
+	// Stress mode only
+	Counterexample *TestCaseResult `json:"counterexample,omitempty"` // smallest random input the code failed on
+	Seed           int64           `json:"seed,omitempty"`           // replays the same random inputs
 }
@@ -5,4 +5,15 @@ type HintResponse struct {
 	SessionID         string `json:"sessionId"`
 	ConversationalHint string `json:"conversationalHint"` // For text-to-speech
 	HintSummary       string `json:"hintSummary"`         // For display
+	PromptVersion     string `json:"promptVersion"`       // Version of the prompt the hint came from
+}
+
+// HintChunk is a piece of the conversational hint, sent as it is generated
+type HintChunk struct {
+	Text string `json:"text"`
+}
+
+// HintStreamError reports a hint stream that failed after it started
+type HintStreamError struct {
+	Error string `json:"error"`
 }
@@ -12,4 +12,5 @@ type InterviewFeedbackResponse struct {
 	InterviewQuestionFeedback []QuestionWithFeedback `json:"interviewQuestionFeedback"`
 	HireAbilityScore int `json:"hireAbilityScore"` // 0-100
 	OverallFeedback []string `json:"overallFeedback"` // 3 points of overall feedback
+	PromptVersion string `json:"promptVersion"` // version of the prompt the feedback came from
 }
\ No newline at end of file
@@ -17,8 +17,9 @@ type InterviewQuestion struct {
 
 // InterviewSessionQuestionsResponse represents the response for generated questions
 type InterviewSessionQuestionsResponse struct {
-	SessionID string               `json:"sessionId"`
-	Questions []InterviewQuestion  `json:"questions"`
+	SessionID     string              `json:"sessionId"`
+	Questions     []InterviewQuestion `json:"questions"`
+	PromptVersion string              `json:"promptVersion,omitempty"` // version of the prompt that customized the questions, empty if they were not customized
 }
 
 // InterviewSessionDetailsResponse represents detailed session information
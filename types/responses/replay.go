@@ -0,0 +1,46 @@
+package responses
+
+import (
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+// EditorSnapshotResponse acknowledges a recorded editor snapshot
+type EditorSnapshotResponse struct {
+	SessionID  string    `json:"sessionId"`
+	QuestionID string    `json:"questionId"`
+	Sequence   int       `json:"sequence"`            // send as baseSequence with the next delta
+	Unchanged  bool      `json:"unchanged,omitempty"` // the code matched the latest snapshot, so nothing was stored
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// ReplayResponse is a coding session's timeline for one question, and the code as it stood at At
+type ReplayResponse struct {
+	SessionID  string        `json:"sessionId"`
+	QuestionID string        `json:"questionId"`
+	At         *time.Time    `json:"at,omitempty"` // events stop here when set
+	Code       string        `json:"code"`
+	Events     []ReplayEvent `json:"events"`
+}
+
+// ReplayEvent is one thing that happened during a coding session. Applying the
+// edit events' deltas in order, starting from empty code, rebuilds the code.
+type ReplayEvent struct {
+	Type      enums.ReplayEventType `json:"type"`
+	Time      time.Time             `json:"time"`
+	Sequence  int                   `json:"sequence,omitempty"`  // edit events
+	Delta     *models.CodeDelta     `json:"delta,omitempty"`     // edit events
+	Execution *ReplayExecution      `json:"execution,omitempty"` // execution events
+}
+
+// ReplayExecution summarizes a run or submission of the code during a replay
+type ReplayExecution struct {
+	SubmissionID string              `json:"submissionId"`
+	Mode         enums.ExecutionMode `json:"mode"`
+	Language     string              `json:"language"`
+	Verdict      enums.Verdict       `json:"verdict"`
+	Passed       int                 `json:"passed"`
+	Total        int                 `json:"total"`
+}
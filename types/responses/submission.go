@@ -0,0 +1,27 @@
+package responses
+
+import (
+	"time"
+
+	"stormhacks-be/models"
+	"stormhacks-be/types/enums"
+)
+
+// SubmissionResponse is the state of a queued code execution
+type SubmissionResponse struct {
+	SubmissionID string                    `json:"submissionId"`
+	Status       enums.SubmissionStatus    `json:"status"`
+	Position     int                       `json:"position,omitempty"` // place in line while queued, 1 is next
+	Result       *ExecuteTechnicalResponse `json:"result,omitempty"`   // set once completed
+	Error        string                    `json:"error,omitempty"`    // set if failed
+	CreatedAt    time.Time                 `json:"createdAt"`
+	StartedAt    *time.Time                `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time                `json:"finishedAt,omitempty"`
+}
+
+// SubmissionHistoryResponse lists the code a session has run and submitted, oldest first
+type SubmissionHistoryResponse struct {
+	SessionID   string              `json:"sessionId"`
+	QuestionID  string              `json:"questionId,omitempty"` // set when filtered to one question
+	Submissions []models.Submission `json:"submissions"`
+}
@@ -5,4 +5,6 @@ type TechnicalFeedbackResponse struct {
 	HireAbilityScore int `json:"hireAbilityScore"` // 0-100
 	Suggestions []string `json:"suggestions"` // 3 suggestions for improvement
 	Strengths []string `json:"strengths"` // 3 things you did well
+	PromptVersion string `json:"promptVersion"` // version of the prompt the feedback came from
+	Verified bool `json:"verified"` // false when the code, completion and hints were reported by the client rather than recorded
 }
\ No newline at end of file